@@ -0,0 +1,129 @@
+package bus
+
+import (
+	"sync"
+	"time"
+)
+
+// Topics published by the models.
+const (
+	EventCreated = "event.created"
+	EventUpdated = "event.updated"
+	EventDeleted = "event.deleted"
+	SeatsUpdated = "seats.updated"
+)
+
+// Message is one published change. IDs increase by one per message so
+// subscribers can resume from the last ID they saw.
+type Message struct {
+	ID      int64
+	Topic   string
+	EventID int64
+	Data    any
+	Time    time.Time
+}
+
+// Bus is an in-process publish/subscribe hub. It keeps a bounded history so
+// reconnecting subscribers can catch up on what they missed.
+type Bus struct {
+	mu          sync.Mutex
+	lastID      int64
+	history     []Message
+	historySize int
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription receives messages on C until it is closed. A subscriber that
+// falls too far behind is dropped and C is closed; it should resubscribe from
+// the last ID it processed.
+type Subscription struct {
+	C      <-chan Message
+	ch     chan Message
+	filter func(Message) bool
+	bus    *Bus
+}
+
+const subscriberBuffer = 64
+
+// Default is the bus the models publish to.
+var Default = New(1000)
+
+func New(historySize int) *Bus {
+	return &Bus{historySize: historySize, subscribers: map[*Subscription]struct{}{}}
+}
+
+// Publish stamps the message with the next ID and hands it to every matching
+// subscriber without blocking.
+func (b *Bus) Publish(topic string, eventID int64, data any) Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	msg := Message{ID: b.lastID, Topic: topic, EventID: eventID, Data: data, Time: time.Now().UTC()}
+
+	b.history = append(b.history, msg)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for sub := range b.subscribers {
+		if sub.filter != nil && !sub.filter(msg) {
+			continue
+		}
+
+		select {
+		case sub.ch <- msg:
+		default:
+			b.drop(sub)
+		}
+	}
+	return msg
+}
+
+// Subscribe registers a subscriber for messages matching filter (nil matches
+// everything). It returns the retained messages published after afterID, and
+// complete is false when some of those have already been discarded. An afterID
+// beyond the last ID was issued before a restart, so it is incomplete too.
+func (b *Bus) Subscribe(afterID int64, filter func(Message) bool) (sub *Subscription, replay []Message, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Message, subscriberBuffer)
+	sub = &Subscription{C: ch, ch: ch, filter: filter, bus: b}
+	b.subscribers[sub] = struct{}{}
+
+	complete = true
+	if afterID > b.lastID || (afterID > 0 && len(b.history) > 0 && b.history[0].ID > afterID+1) {
+		complete = false
+	}
+
+	for _, msg := range b.history {
+		if msg.ID <= afterID || (filter != nil && !filter(msg)) {
+			continue
+		}
+		replay = append(replay, msg)
+	}
+	return sub, replay, complete
+}
+
+// LastID returns the ID of the most recently published message.
+func (b *Bus) LastID() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastID
+}
+
+// Close unsubscribes. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.drop(s)
+}
+
+func (b *Bus) drop(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.ch)
+}
@@ -0,0 +1,84 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishSubscribe(t *testing.T) {
+	b := New(10)
+	sub, replay, complete := b.Subscribe(0, nil)
+	defer sub.Close()
+
+	assert.Empty(t, replay)
+	assert.True(t, complete)
+
+	b.Publish(EventCreated, 1, "first")
+	msg := <-sub.C
+	assert.Equal(t, int64(1), msg.ID)
+	assert.Equal(t, EventCreated, msg.Topic)
+	assert.Equal(t, "first", msg.Data)
+}
+
+func TestSubscribe_ReplaysAfterID(t *testing.T) {
+	b := New(10)
+	for i := 0; i < 5; i++ {
+		b.Publish(SeatsUpdated, int64(i%2), i)
+	}
+
+	sub, replay, complete := b.Subscribe(3, nil)
+	sub.Close()
+	assert.True(t, complete)
+	assert.Len(t, replay, 2)
+	assert.Equal(t, int64(4), replay[0].ID)
+
+	onlyEventOne := func(msg Message) bool { return msg.EventID == 1 }
+	sub, replay, _ = b.Subscribe(0, onlyEventOne)
+	sub.Close()
+	assert.Len(t, replay, 2)
+}
+
+func TestSubscribe_ReportsTrimmedHistory(t *testing.T) {
+	b := New(3)
+	for i := 0; i < 10; i++ {
+		b.Publish(EventUpdated, 1, i)
+	}
+
+	sub, replay, complete := b.Subscribe(2, nil)
+	sub.Close()
+	assert.False(t, complete)
+	assert.Len(t, replay, 3)
+
+	sub, _, complete = b.Subscribe(7, nil)
+	sub.Close()
+	assert.True(t, complete)
+}
+
+func TestSubscribe_ReportsIDFromBeforeRestart(t *testing.T) {
+	b := New(10)
+	b.Publish(EventUpdated, 1, nil)
+
+	sub, replay, complete := b.Subscribe(500, nil)
+	sub.Close()
+	assert.False(t, complete)
+	assert.Empty(t, replay)
+}
+
+func TestPublish_DropsSlowSubscriber(t *testing.T) {
+	b := New(1)
+	sub, _, _ := b.Subscribe(0, nil)
+
+	for i := 0; i < subscriberBuffer+1; i++ {
+		b.Publish(EventUpdated, 1, i)
+	}
+
+	received := 0
+	for range sub.C {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received)
+
+	// Closing an already dropped subscription is harmless
+	sub.Close()
+}
@@ -53,6 +53,52 @@ func createTables() {
 		panic(err)
 	}
 
+	createOrganizationsTable := `
+	CREATE TABLE IF NOT EXISTS organizations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		owner_id INTEGER NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (owner_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createOrganizationsTable)
+
+	if err != nil {
+		panic("Could not create organizations table")
+	}
+
+	createCategoriesTable := `
+	CREATE TABLE IF NOT EXISTS categories (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		slug TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		parent_id INTEGER,
+		FOREIGN KEY (parent_id) REFERENCES categories(id)
+	);
+	`
+	_, err = DB.Exec(createCategoriesTable)
+
+	if err != nil {
+		panic("Could not create categories table")
+	}
+
+	addColumn("events", "organization_id", "INTEGER REFERENCES organizations(id)")
+	addColumn("events", "capacity", "INTEGER NOT NULL DEFAULT 0")
+	addColumn("events", "end_time", "DATETIME")
+	addColumn("events", "guest_limit", "INTEGER NOT NULL DEFAULT 0")
+	addColumn("events", "category_id", "INTEGER REFERENCES categories(id)")
+	addColumn("events", "recurrence", "TEXT")
+	addColumn("events", "latitude", "REAL")
+	addColumn("events", "longitude", "REAL")
+
+	_, err = DB.Exec("CREATE INDEX IF NOT EXISTS events_coordinates ON events (latitude, longitude)")
+
+	if err != nil {
+		panic("Could not create events coordinates index")
+	}
+
 	createRegistrationsTable := `
 	CREATE TABLE IF NOT EXISTS registrations (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -67,4 +113,664 @@ func createTables() {
 	if err != nil {
 		panic("Could not create registrations table")
 	}
+
+	addColumn("registrations", "checked_in_at", "DATETIME")
+	addColumn("registrations", "answers", "TEXT NOT NULL DEFAULT '{}'")
+	addColumn("registrations", "status", "TEXT NOT NULL DEFAULT 'confirmed'")
+	addColumn("registrations", "guests", "INTEGER NOT NULL DEFAULT 0")
+	addColumn("registrations", "group_id", "INTEGER REFERENCES registration_groups(id)")
+	addColumn("registrations", "ticket_token", "TEXT")
+	addColumn("registrations", "registered_at", "DATETIME")
+
+	// Registrations made before tickets existed get one now
+	_, err = DB.Exec("UPDATE registrations SET ticket_token = lower(hex(randomblob(16))) WHERE ticket_token IS NULL")
+
+	if err != nil {
+		panic("Could not issue tickets for existing registrations")
+	}
+
+	_, err = DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS registrations_ticket_token ON registrations(ticket_token)")
+
+	if err != nil {
+		panic("Could not create ticket token index")
+	}
+
+	createRegistrationGroupsTable := `
+	CREATE TABLE IF NOT EXISTS registration_groups (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		leader_id INTEGER NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (event_id) REFERENCES events(id),
+		FOREIGN KEY (leader_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createRegistrationGroupsTable)
+
+	if err != nil {
+		panic("Could not create registration groups table")
+	}
+
+	createRegistrationFormsTable := `
+	CREATE TABLE IF NOT EXISTS registration_forms (
+		event_id INTEGER PRIMARY KEY,
+		fields TEXT NOT NULL,
+		FOREIGN KEY (event_id) REFERENCES events(id)
+	);
+	`
+	_, err = DB.Exec(createRegistrationFormsTable)
+
+	if err != nil {
+		panic("Could not create registration forms table")
+	}
+
+	createRemindersTable := `
+	CREATE TABLE IF NOT EXISTS reminders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		send_at DATETIME NOT NULL,
+		sent_at DATETIME,
+		UNIQUE (event_id, user_id, kind),
+		FOREIGN KEY (event_id) REFERENCES events(id),
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createRemindersTable)
+
+	if err != nil {
+		panic("Could not create reminders table")
+	}
+
+	addColumn("reminders", "channels", "TEXT NOT NULL DEFAULT ''")
+
+	createNotificationsTable := `
+	CREATE TABLE IF NOT EXISTS notifications (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		title TEXT NOT NULL,
+		body TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		read_at DATETIME,
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createNotificationsTable)
+
+	if err != nil {
+		panic("Could not create notifications table")
+	}
+
+	addColumn("notifications", "read_at", "DATETIME")
+	addColumn("users", "timezone", "TEXT NOT NULL DEFAULT 'UTC'")
+	addColumn("users", "is_admin", "INTEGER NOT NULL DEFAULT 0")
+
+	createNotificationSettingsTable := `
+	CREATE TABLE IF NOT EXISTS notification_settings (
+		user_id INTEGER PRIMARY KEY,
+		quiet_hours_start TEXT NOT NULL DEFAULT '',
+		quiet_hours_end TEXT NOT NULL DEFAULT '',
+		phone TEXT NOT NULL DEFAULT '',
+		webhook_url TEXT NOT NULL DEFAULT '',
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createNotificationSettingsTable)
+
+	if err != nil {
+		panic("Could not create notification settings table")
+	}
+
+	createNotificationPreferencesTable := `
+	CREATE TABLE IF NOT EXISTS notification_preferences (
+		user_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		channel TEXT NOT NULL,
+		enabled INTEGER NOT NULL,
+		frequency TEXT NOT NULL,
+		PRIMARY KEY (user_id, type, channel),
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createNotificationPreferencesTable)
+
+	if err != nil {
+		panic("Could not create notification preferences table")
+	}
+
+	createNotificationQueueTable := `
+	CREATE TABLE IF NOT EXISTS notification_queue (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		channel TEXT NOT NULL,
+		type TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		body TEXT NOT NULL,
+		digest INTEGER NOT NULL,
+		deliver_after DATETIME NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createNotificationQueueTable)
+
+	if err != nil {
+		panic("Could not create notification queue table")
+	}
+
+	addColumn("notification_queue", "attachments", "TEXT NOT NULL DEFAULT '[]'")
+
+	createWebhookEndpointsTable := `
+	CREATE TABLE IF NOT EXISTS webhook_endpoints (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		organization_id INTEGER NOT NULL,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		event_types TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (organization_id) REFERENCES organizations(id)
+	);
+	`
+	_, err = DB.Exec(createWebhookEndpointsTable)
+
+	if err != nil {
+		panic("Could not create webhook endpoints table")
+	}
+
+	createWebhookDeliveriesTable := `
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		endpoint_id INTEGER NOT NULL,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints(id)
+	);
+	`
+	_, err = DB.Exec(createWebhookDeliveriesTable)
+
+	if err != nil {
+		panic("Could not create webhook deliveries table")
+	}
+
+	createWebhookAttemptsTable := `
+	CREATE TABLE IF NOT EXISTS webhook_attempts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		delivery_id INTEGER NOT NULL,
+		attempted_at DATETIME NOT NULL,
+		status_code INTEGER NOT NULL,
+		error TEXT NOT NULL,
+		duration_ms INTEGER NOT NULL,
+		FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries(id)
+	);
+	`
+	_, err = DB.Exec(createWebhookAttemptsTable)
+
+	if err != nil {
+		panic("Could not create webhook attempts table")
+	}
+
+	createPollsTable := `
+	CREATE TABLE IF NOT EXISTS polls (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		question TEXT NOT NULL,
+		options TEXT NOT NULL,
+		closed INTEGER NOT NULL DEFAULT 0,
+		created_by INTEGER NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (event_id) REFERENCES events(id),
+		FOREIGN KEY (created_by) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createPollsTable)
+
+	if err != nil {
+		panic("Could not create polls table")
+	}
+
+	createPollVotesTable := `
+	CREATE TABLE IF NOT EXISTS poll_votes (
+		poll_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		option_index INTEGER NOT NULL,
+		PRIMARY KEY (poll_id, user_id),
+		FOREIGN KEY (poll_id) REFERENCES polls(id),
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createPollVotesTable)
+
+	if err != nil {
+		panic("Could not create poll votes table")
+	}
+
+	createQuestionsTable := `
+	CREATE TABLE IF NOT EXISTS questions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		text TEXT NOT NULL,
+		answered INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (event_id) REFERENCES events(id),
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createQuestionsTable)
+
+	if err != nil {
+		panic("Could not create questions table")
+	}
+
+	createQuestionVotesTable := `
+	CREATE TABLE IF NOT EXISTS question_votes (
+		question_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		PRIMARY KEY (question_id, user_id),
+		FOREIGN KEY (question_id) REFERENCES questions(id),
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createQuestionVotesTable)
+
+	if err != nil {
+		panic("Could not create question votes table")
+	}
+
+	createCommentsTable := `
+	CREATE TABLE IF NOT EXISTS comments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		parent_id INTEGER,
+		body TEXT NOT NULL,
+		pinned INTEGER NOT NULL DEFAULT 0,
+		hidden INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		edited_at DATETIME,
+		deleted_at DATETIME,
+		FOREIGN KEY (event_id) REFERENCES events(id),
+		FOREIGN KEY (user_id) REFERENCES users(id),
+		FOREIGN KEY (parent_id) REFERENCES comments(id)
+	);
+	`
+	_, err = DB.Exec(createCommentsTable)
+
+	if err != nil {
+		panic("Could not create comments table")
+	}
+
+	createCommentReportsTable := `
+	CREATE TABLE IF NOT EXISTS comment_reports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		comment_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		reason TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		resolution TEXT,
+		resolved_by INTEGER,
+		resolved_at DATETIME,
+		UNIQUE(comment_id, user_id),
+		FOREIGN KEY (comment_id) REFERENCES comments(id),
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createCommentReportsTable)
+
+	if err != nil {
+		panic("Could not create comment reports table")
+	}
+
+	createSurveysTable := `
+	CREATE TABLE IF NOT EXISTS surveys (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL UNIQUE,
+		title TEXT NOT NULL,
+		questions TEXT NOT NULL,
+		invites_sent_at DATETIME,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (event_id) REFERENCES events(id)
+	);
+	`
+	_, err = DB.Exec(createSurveysTable)
+
+	if err != nil {
+		panic("Could not create surveys table")
+	}
+
+	createSurveyResponsesTable := `
+	CREATE TABLE IF NOT EXISTS survey_responses (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		survey_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		answers TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		UNIQUE(survey_id, user_id),
+		FOREIGN KEY (survey_id) REFERENCES surveys(id),
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createSurveyResponsesTable)
+
+	if err != nil {
+		panic("Could not create survey responses table")
+	}
+
+	createTransferRulesTable := `
+	CREATE TABLE IF NOT EXISTS transfer_rules (
+		event_id INTEGER PRIMARY KEY,
+		allowed INTEGER NOT NULL,
+		cutoff_hours INTEGER NOT NULL,
+		offer_expiry_hours INTEGER NOT NULL,
+		FOREIGN KEY (event_id) REFERENCES events(id)
+	);
+	`
+	_, err = DB.Exec(createTransferRulesTable)
+
+	if err != nil {
+		panic("Could not create transfer rules table")
+	}
+
+	createTicketTransfersTable := `
+	CREATE TABLE IF NOT EXISTS ticket_transfers (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		from_user_id INTEGER NOT NULL,
+		to_user_id INTEGER NOT NULL,
+		status TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL,
+		resolved_at DATETIME,
+		FOREIGN KEY (event_id) REFERENCES events(id),
+		FOREIGN KEY (from_user_id) REFERENCES users(id),
+		FOREIGN KEY (to_user_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createTicketTransfersTable)
+
+	if err != nil {
+		panic("Could not create ticket transfers table")
+	}
+
+	createLotteriesTable := `
+	CREATE TABLE IF NOT EXISTS lotteries (
+		event_id INTEGER PRIMARY KEY,
+		opens_at DATETIME NOT NULL,
+		closes_at DATETIME NOT NULL,
+		priority_groups TEXT NOT NULL DEFAULT '[]',
+		seed TEXT,
+		seed_hash TEXT,
+		drawn_at DATETIME,
+		FOREIGN KEY (event_id) REFERENCES events(id)
+	);
+	`
+	_, err = DB.Exec(createLotteriesTable)
+
+	if err != nil {
+		panic("Could not create lotteries table")
+	}
+
+	addColumn("lotteries", "seed_hash", "TEXT")
+
+	createLotteryEntriesTable := `
+	CREATE TABLE IF NOT EXISTS lottery_entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		guests INTEGER NOT NULL DEFAULT 0,
+		answers TEXT NOT NULL DEFAULT '{}',
+		created_at DATETIME NOT NULL,
+		priority INTEGER,
+		rank INTEGER,
+		outcome TEXT NOT NULL DEFAULT 'pending',
+		UNIQUE(event_id, user_id),
+		FOREIGN KEY (event_id) REFERENCES events(id),
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createLotteryEntriesTable)
+
+	if err != nil {
+		panic("Could not create lottery entries table")
+	}
+
+	createCertificateTemplatesTable := `
+	CREATE TABLE IF NOT EXISTS certificate_templates (
+		event_id INTEGER PRIMARY KEY,
+		title TEXT NOT NULL,
+		body TEXT NOT NULL,
+		signatures TEXT NOT NULL DEFAULT '[]',
+		logo BLOB,
+		FOREIGN KEY (event_id) REFERENCES events(id)
+	);
+	`
+	_, err = DB.Exec(createCertificateTemplatesTable)
+
+	if err != nil {
+		panic("Could not create certificate templates table")
+	}
+
+	createCertificatesTable := `
+	CREATE TABLE IF NOT EXISTS certificates (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		code TEXT NOT NULL UNIQUE,
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		recipient_name TEXT NOT NULL,
+		issued_at DATETIME NOT NULL,
+		UNIQUE(event_id, user_id),
+		FOREIGN KEY (event_id) REFERENCES events(id),
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createCertificatesTable)
+
+	if err != nil {
+		panic("Could not create certificates table")
+	}
+
+	createEventImagesTable := `
+	CREATE TABLE IF NOT EXISTS event_images (
+		event_id INTEGER PRIMARY KEY,
+		storage_key TEXT NOT NULL,
+		width INTEGER NOT NULL,
+		height INTEGER NOT NULL,
+		uploaded_at DATETIME NOT NULL,
+		FOREIGN KEY (event_id) REFERENCES events(id)
+	);
+	`
+	_, err = DB.Exec(createEventImagesTable)
+
+	if err != nil {
+		panic("Could not create event images table")
+	}
+
+	createAttachmentsTable := `
+	CREATE TABLE IF NOT EXISTS attachments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		storage_key TEXT NOT NULL,
+		visibility TEXT NOT NULL,
+		uploaded_by INTEGER NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (event_id) REFERENCES events(id),
+		FOREIGN KEY (uploaded_by) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createAttachmentsTable)
+
+	if err != nil {
+		panic("Could not create attachments table")
+	}
+
+	createEventTagsTable := `
+	CREATE TABLE IF NOT EXISTS event_tags (
+		event_id INTEGER NOT NULL,
+		tag TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		PRIMARY KEY (event_id, tag),
+		FOREIGN KEY (event_id) REFERENCES events(id)
+	);
+	CREATE INDEX IF NOT EXISTS event_tags_tag ON event_tags (tag);
+	`
+	_, err = DB.Exec(createEventTagsTable)
+
+	if err != nil {
+		panic("Could not create event tags table")
+	}
+
+	createFollowsTable := `
+	CREATE TABLE IF NOT EXISTS follows (
+		user_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		target_id INTEGER NOT NULL,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (user_id, kind, target_id),
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createFollowsTable)
+
+	if err != nil {
+		panic("Could not create follows table")
+	}
+
+	createRecommendationsTable := `
+	CREATE TABLE IF NOT EXISTS recommendations (
+		user_id INTEGER NOT NULL,
+		event_id INTEGER NOT NULL,
+		rank INTEGER NOT NULL,
+		score REAL NOT NULL,
+		reasons TEXT NOT NULL,
+		computed_at DATETIME NOT NULL,
+		PRIMARY KEY (user_id, event_id),
+		FOREIGN KEY (user_id) REFERENCES users(id),
+		FOREIGN KEY (event_id) REFERENCES events(id)
+	);
+	`
+	_, err = DB.Exec(createRecommendationsTable)
+
+	if err != nil {
+		panic("Could not create recommendations table")
+	}
+
+	createBookmarksTable := `
+	CREATE TABLE IF NOT EXISTS bookmarks (
+		user_id INTEGER NOT NULL,
+		event_id INTEGER NOT NULL,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (user_id, event_id),
+		FOREIGN KEY (user_id) REFERENCES users(id),
+		FOREIGN KEY (event_id) REFERENCES events(id)
+	);
+	`
+	_, err = DB.Exec(createBookmarksTable)
+
+	if err != nil {
+		panic("Could not create bookmarks table")
+	}
+
+	createEventViewsTable := `
+	CREATE TABLE IF NOT EXISTS event_views (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		viewer TEXT NOT NULL,
+		viewed_at DATETIME NOT NULL,
+		FOREIGN KEY (event_id) REFERENCES events(id)
+	);
+	CREATE INDEX IF NOT EXISTS event_views_viewer ON event_views (event_id, viewer);
+	`
+	_, err = DB.Exec(createEventViewsTable)
+
+	if err != nil {
+		panic("Could not create event views table")
+	}
+
+	addColumn("event_views", "source", "TEXT NOT NULL DEFAULT ''")
+
+	_, err = DB.Exec("CREATE INDEX IF NOT EXISTS event_views_source ON event_views (event_id, source)")
+
+	if err != nil {
+		panic("Could not create event views source index")
+	}
+
+	createFeedItemsTable := `
+	CREATE TABLE IF NOT EXISTS feed_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		event_id INTEGER NOT NULL,
+		event_name TEXT NOT NULL,
+		message TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	CREATE INDEX IF NOT EXISTS feed_items_user ON feed_items (user_id, id);
+	`
+	_, err = DB.Exec(createFeedItemsTable)
+
+	if err != nil {
+		panic("Could not create feed items table")
+	}
+
+	createSavedSearchesTable := `
+	CREATE TABLE IF NOT EXISTS saved_searches (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		query TEXT NOT NULL,
+		categories TEXT NOT NULL,
+		tags TEXT NOT NULL,
+		frequency TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	`
+	_, err = DB.Exec(createSavedSearchesTable)
+
+	if err != nil {
+		panic("Could not create saved searches table")
+	}
+
+	createSavedSearchQueueTable := `
+	CREATE TABLE IF NOT EXISTS saved_search_queue (
+		event_id INTEGER PRIMARY KEY,
+		FOREIGN KEY (event_id) REFERENCES events(id)
+	);
+	`
+	_, err = DB.Exec(createSavedSearchQueueTable)
+
+	if err != nil {
+		panic("Could not create saved search queue table")
+	}
+}
+
+// addColumn adds a column to a table created by an older version of the
+// schema. SQLite has no ADD COLUMN IF NOT EXISTS, so the table is checked first.
+func addColumn(table, column, definition string) {
+	var count int
+	err := DB.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+
+	if err != nil {
+		panic(err)
+	}
+
+	if count > 0 {
+		return
+	}
+
+	_, err = DB.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition)
+
+	if err != nil {
+		panic("Could not add " + column + " to " + table + " table")
+	}
 }
@@ -0,0 +1,106 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// CertificateSignature is a signatory printed at the foot of a certificate.
+type CertificateSignature struct {
+	Name string
+	Role string
+}
+
+// Certificate is the content of one attendance certificate.
+type Certificate struct {
+	Title      string
+	Recipient  string
+	Body       string
+	Signatures []CertificateSignature
+	// Logo is an optional PNG or JPEG printed above the title.
+	Logo      []byte
+	Code      string
+	VerifyURL string
+}
+
+const certificateLogoHeight = 24
+
+// WriteCertificate renders the certificate as a landscape A4 PDF.
+func WriteCertificate(w io.Writer, c Certificate) error {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(false, 0)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	pdf.SetDrawColor(60, 60, 60)
+	pdf.SetLineWidth(1)
+	pdf.Rect(10, 10, pageWidth-20, pageHeight-20, "D")
+	pdf.SetLineWidth(0.3)
+	pdf.Rect(13, 13, pageWidth-26, pageHeight-26, "D")
+
+	y := 28.0
+	if len(c.Logo) > 0 {
+		imageType := ""
+		switch http.DetectContentType(c.Logo) {
+		case "image/png":
+			imageType = "PNG"
+		case "image/jpeg":
+			imageType = "JPG"
+		default:
+			return errors.New("logo must be a PNG or JPEG image")
+		}
+
+		options := fpdf.ImageOptions{ImageType: imageType}
+		info := pdf.RegisterImageOptionsReader("logo", options, bytes.NewReader(c.Logo))
+		if info != nil && info.Height() > 0 {
+			width := certificateLogoHeight * info.Width() / info.Height()
+			pdf.ImageOptions("logo", (pageWidth-width)/2, y, width, certificateLogoHeight, false, options, 0, "")
+		}
+		y += certificateLogoHeight + 6
+	}
+
+	pdf.SetY(y)
+	pdf.SetFont("Times", "B", 30)
+	pdf.CellFormat(0, 14, translate(c.Title), "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	pdf.SetFont("Times", "I", 14)
+	pdf.CellFormat(0, 8, "This certifies that", "", 1, "C", false, 0, "")
+	pdf.Ln(2)
+	pdf.SetFont("Times", "B", 26)
+	pdf.CellFormat(0, 14, translate(c.Recipient), "", 1, "C", false, 0, "")
+	pdf.Ln(2)
+	pdf.SetFont("Times", "", 14)
+	pdf.MultiCell(0, 7, translate(c.Body), "", "C", false)
+
+	if len(c.Signatures) > 0 {
+		left, _, right, _ := pdf.GetMargins()
+		slot := (pageWidth - left - right) / float64(len(c.Signatures))
+		lineY := pageHeight - 48
+		for i, signature := range c.Signatures {
+			x := left + float64(i)*slot
+			pdf.Line(x+10, lineY, x+slot-10, lineY)
+			pdf.SetXY(x, lineY+1)
+			pdf.SetFont("Times", "B", 12)
+			pdf.CellFormat(slot, 6, translate(signature.Name), "", 2, "C", false, 0, "")
+			pdf.SetFont("Times", "", 11)
+			pdf.CellFormat(slot, 6, translate(signature.Role), "", 0, "C", false, 0, "")
+		}
+	}
+
+	pdf.SetXY(20, pageHeight-24)
+	pdf.SetFont("Helvetica", "", 9)
+	verification := "Certificate " + c.Code
+	if c.VerifyURL != "" {
+		verification += " - verify at " + c.VerifyURL
+	}
+	pdf.CellFormat(0, 5, translate(verification), "", 0, "C", false, 0, "")
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// Table writes an export one row at a time so large exports never have to be
+// held in memory.
+type Table interface {
+	WriteRow(cells []string) error
+	// Flush pushes rows written so far to the underlying writer.
+	Flush() error
+	// Close finishes the document. The underlying writer is left open.
+	Close() error
+}
+
+// EscapeCell stops spreadsheet programs from evaluating a cell as a formula by
+// prefixing a quote to values starting with =, +, -, @, tab or carriage
+// return. The CSV and XLSX tables escape every cell they write.
+func EscapeCell(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func escapeRow(cells []string) []string {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = EscapeCell(cell)
+	}
+	return escaped
+}
+
+type csvTable struct {
+	writer *csv.Writer
+}
+
+// NewCSV returns a Table writing comma-separated values, with cells escaped by
+// EscapeCell.
+func NewCSV(w io.Writer) Table {
+	return &csvTable{writer: csv.NewWriter(w)}
+}
+
+func (t *csvTable) WriteRow(cells []string) error {
+	return t.writer.Write(escapeRow(cells))
+}
+
+func (t *csvTable) Flush() error {
+	t.writer.Flush()
+	return t.writer.Error()
+}
+
+func (t *csvTable) Close() error {
+	return t.Flush()
+}
@@ -0,0 +1,140 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readSheet(t *testing.T, data []byte) [][]string {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Failed to open workbook: %v", err)
+	}
+
+	file, err := archive.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatalf("Workbook has no sheet: %v", err)
+	}
+	defer file.Close()
+
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref  string `xml:"r,attr"`
+				Text string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	content, _ := io.ReadAll(file)
+	err = xml.Unmarshal(content, &sheet)
+	if err != nil {
+		t.Fatalf("Failed to parse sheet: %v", err)
+	}
+
+	var rows [][]string
+	for _, row := range sheet.Rows {
+		var cells []string
+		for _, cell := range row.Cells {
+			cells = append(cells, cell.Ref+"="+cell.Text)
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+func TestXLSX_WritesRowsAsInlineStrings(t *testing.T) {
+	var buf bytes.Buffer
+	table, err := NewXLSX(&buf, "Career Fair: Spring [2026]")
+	if err != nil {
+		t.Fatalf("Failed to start workbook: %v", err)
+	}
+
+	assert.NoError(t, table.WriteRow([]string{"Email", "Notes"}))
+	assert.NoError(t, table.Flush())
+	assert.NoError(t, table.WriteRow([]string{"a@example.com", "<vegan> & \"gluten-free\""}))
+	assert.NoError(t, table.Close())
+
+	assert.Equal(t, [][]string{
+		{"A1=Email", "B1=Notes"},
+		{"A2=a@example.com", "B2=<vegan> & \"gluten-free\""},
+	}, readSheet(t, buf.Bytes()))
+
+	archive, _ := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	workbook, _ := archive.Open("xl/workbook.xml")
+	content, _ := io.ReadAll(workbook)
+	assert.Contains(t, string(content), `name="Career Fair  Spring  2026"`)
+}
+
+func TestColumnName(t *testing.T) {
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+	assert.Equal(t, "AZ", columnName(51))
+	assert.Equal(t, "BA", columnName(52))
+}
+
+func TestCSV_WritesRows(t *testing.T) {
+	var buf bytes.Buffer
+	table := NewCSV(&buf)
+	assert.NoError(t, table.WriteRow([]string{"Email", "Notes"}))
+	assert.NoError(t, table.WriteRow([]string{"a@example.com", "vegan, no nuts"}))
+	assert.NoError(t, table.Close())
+	assert.Equal(t, "Email,Notes\na@example.com,\"vegan, no nuts\"\n", buf.String())
+}
+
+func TestEscapeCell(t *testing.T) {
+	for _, cell := range []string{"=1+1", "+49 30 1234", "-2", "@SUM(A1)", "\tx", "\rx"} {
+		assert.Equal(t, "'"+cell, EscapeCell(cell), cell)
+	}
+	assert.Equal(t, "vegan", EscapeCell("vegan"))
+	assert.Equal(t, "", EscapeCell(""))
+
+	var buf bytes.Buffer
+	table, _ := NewXLSX(&buf, "Sheet")
+	assert.NoError(t, table.WriteRow([]string{"=cmd|' /C calc'!A0"}))
+	assert.NoError(t, table.Close())
+	assert.Equal(t, [][]string{{"A1='=cmd|' /C calc'!A0"}}, readSheet(t, buf.Bytes()))
+}
+
+func TestSignInSheet_WritesPDF(t *testing.T) {
+	var buf bytes.Buffer
+	sheet := NewSignInSheet(&buf, "Career Fair", "Gym", []string{"#", "Email"}, []float64{10, 80})
+	for range 60 {
+		assert.NoError(t, sheet.WriteRow([]string{"1", strings.Repeat("très-long-name", 10) + "@example.com"}))
+	}
+	assert.NoError(t, sheet.Close())
+
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+	// Sixty rows do not fit on one page
+	assert.Contains(t, buf.String(), "/Count 3")
+}
+
+func TestWriteCertificate_WithLogo(t *testing.T) {
+	logo := image.NewGray(image.Rect(0, 0, 30, 10))
+	var logoPNG bytes.Buffer
+	assert.NoError(t, png.Encode(&logoPNG, logo))
+
+	var buf bytes.Buffer
+	err := WriteCertificate(&buf, Certificate{
+		Title:      "Certificate of Attendance",
+		Recipient:  "Zoë Ångström",
+		Body:       "for attending the Career Fair on 3 March 2026.",
+		Signatures: []CertificateSignature{{Name: "Dean", Role: "Student Affairs"}, {Name: "Club President"}},
+		Logo:       logoPNG.Bytes(),
+		Code:       "K7QX2-M4PDA",
+		VerifyURL:  "http://localhost:8080/certificates/K7QX2-M4PDA",
+	})
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+
+	err = WriteCertificate(io.Discard, Certificate{Title: "Bad logo", Logo: []byte("GIF89a")})
+	assert.Error(t, err)
+}
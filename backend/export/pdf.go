@@ -0,0 +1,117 @@
+package export
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	signInRowHeight      = 9
+	signInHeaderHeight   = 7
+	signInSignatureWidth = 50
+)
+
+// SignInSheet is a printable attendance list: one ruled row per registration
+// with an empty signature box at the end. PDF pages can only be written out
+// once the document is complete, so rows are kept until Close.
+type SignInSheet struct {
+	pdf       *fpdf.Fpdf
+	out       io.Writer
+	header    []string
+	widths    []float64
+	translate func(string) string
+	rows      int
+}
+
+// NewSignInSheet starts a sheet titled with the event. widths are the column
+// widths in millimetres and must match header; the signature column takes the
+// rest of the page.
+func NewSignInSheet(w io.Writer, title, subtitle string, header []string, widths []float64) *SignInSheet {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(12, 12, 12)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("")
+
+	sheet := &SignInSheet{
+		pdf:       pdf,
+		out:       w,
+		header:    header,
+		widths:    widths,
+		translate: pdf.UnicodeTranslatorFromDescriptor(""),
+	}
+
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(0, 8, sheet.translate(title), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, 6, sheet.translate(subtitle), "", 1, "L", false, 0, "")
+		pdf.Ln(2)
+
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		for i, column := range sheet.header {
+			pdf.CellFormat(sheet.widths[i], signInHeaderHeight, sheet.translate(column), "1", 0, "L", true, 0, "")
+		}
+		pdf.CellFormat(sheet.signatureWidth(), signInHeaderHeight, "Signature", "1", 1, "L", true, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(0, 6, "Page "+strconv.Itoa(pdf.PageNo())+" of {nb}", "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+	return sheet
+}
+
+func (s *SignInSheet) signatureWidth() float64 {
+	pageWidth, _ := s.pdf.GetPageSize()
+	left, _, right, _ := s.pdf.GetMargins()
+	width := pageWidth - left - right
+	for _, w := range s.widths {
+		width -= w
+	}
+	return max(width, signInSignatureWidth)
+}
+
+func (s *SignInSheet) WriteRow(cells []string) error {
+	s.rows++
+	for i, width := range s.widths {
+		text := ""
+		if i < len(cells) {
+			text = s.fit(cells[i], width-2)
+		}
+		s.pdf.CellFormat(width, signInRowHeight, text, "1", 0, "L", false, 0, "")
+	}
+	s.pdf.CellFormat(s.signatureWidth(), signInRowHeight, "", "1", 1, "L", false, 0, "")
+	return s.pdf.Error()
+}
+
+// fit translates text for the PDF font, shortening it with an ellipsis until
+// it fits in width.
+func (s *SignInSheet) fit(text string, width float64) string {
+	translated := s.translate(text)
+	if s.pdf.GetStringWidth(translated) <= width {
+		return translated
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && s.pdf.GetStringWidth(s.translate(string(runes)+"...")) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return s.translate(string(runes) + "...")
+}
+
+// Flush does nothing; the document is written by Close.
+func (s *SignInSheet) Flush() error {
+	return nil
+}
+
+func (s *SignInSheet) Close() error {
+	if s.rows == 0 {
+		s.pdf.SetFont("Helvetica", "I", 9)
+		s.pdf.CellFormat(0, signInRowHeight, "No registrations yet", "", 1, "L", false, 0, "")
+	}
+	return s.pdf.Output(s.out)
+}
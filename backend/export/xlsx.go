@@ -0,0 +1,141 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The parts of a workbook with a single sheet. The sheet itself is written
+// last so its rows can be streamed.
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`},
+}
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+// maxSheetName is Excel's limit on sheet name length.
+const maxSheetName = 31
+
+type xlsxTable struct {
+	zip   *zip.Writer
+	sheet io.Writer
+	rows  int
+}
+
+// NewXLSX returns a Table writing an Excel workbook with one sheet. Cells are
+// escaped by EscapeCell and written as inline strings, so no shared string
+// table has to be built up before the sheet.
+func NewXLSX(w io.Writer, sheetName string) (Table, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		err := writeZipFile(archive, part.name, part.content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	name := strings.Map(func(r rune) rune {
+		// Excel rejects these in sheet names
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return ' '
+		}
+		return r
+	}, sheetName)
+	if name = strings.TrimSpace(name); name == "" {
+		name = "Sheet1"
+	}
+	if len([]rune(name)) > maxSheetName {
+		name = string([]rune(name)[:maxSheetName])
+	}
+
+	err := writeZipFile(archive, "xl/workbook.xml", strings.Replace(xlsxWorkbook, "%s", escapeXML(name), 1))
+	if err != nil {
+		return nil, err
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxTable{zip: archive, sheet: sheet}, nil
+}
+
+func writeZipFile(archive *zip.Writer, name, content string) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(file, content)
+	return err
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func (t *xlsxTable) WriteRow(cells []string) error {
+	t.rows++
+	row := strconv.Itoa(t.rows)
+
+	var b strings.Builder
+	b.WriteString(`<row r="` + row + `">`)
+	for i, cell := range cells {
+		b.WriteString(`<c r="` + columnName(i) + row + `" t="inlineStr"><is><t xml:space="preserve">`)
+		b.WriteString(escapeXML(EscapeCell(cell)))
+		b.WriteString(`</t></is></c>`)
+	}
+	b.WriteString(`</row>`)
+
+	_, err := io.WriteString(t.sheet, b.String())
+	return err
+}
+
+func (t *xlsxTable) Flush() error {
+	return t.zip.Flush()
+}
+
+func (t *xlsxTable) Close() error {
+	_, err := io.WriteString(t.sheet, `</sheetData></worksheet>`)
+	if err != nil {
+		return err
+	}
+	return t.zip.Close()
+}
+
+// columnName returns the spreadsheet column letters for a zero-based index:
+// A, B, ..., Z, AA, AB and so on.
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,85 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Place is a named spot on the campus map. Aliases are other names people
+// use for it, such as abbreviations.
+type Place struct {
+	Name      string
+	Aliases   []string
+	Latitude  float64
+	Longitude float64
+}
+
+// CampusMap geocodes locations against a fixed list of places, so events at
+// known buildings get coordinates without calling an external service.
+type CampusMap struct {
+	places []Place
+}
+
+// NewCampusMap returns a map of the given places.
+func NewCampusMap(places []Place) (*CampusMap, error) {
+	for _, place := range places {
+		if strings.TrimSpace(place.Name) == "" {
+			return nil, errors.New("campus map has a place without a name")
+		}
+		if !(Point{place.Latitude, place.Longitude}).Valid() {
+			return nil, fmt.Errorf("campus map place %q has invalid coordinates", place.Name)
+		}
+	}
+	return &CampusMap{places: places}, nil
+}
+
+// LoadCampusMap reads a JSON array of places from the file at path.
+func LoadCampusMap(path string) (*CampusMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var places []Place
+	err = json.Unmarshal(data, &places)
+	if err != nil {
+		return nil, fmt.Errorf("campus map %s: %w", path, err)
+	}
+	return NewCampusMap(places)
+}
+
+// normalizeName lowercases the name and collapses punctuation and spaces,
+// so "Main Hall, Room 2" contains "main hall".
+func normalizeName(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}), " ")
+}
+
+// Geocode returns the place whose name or alias the location is or
+// mentions, preferring the longest match so "Science Library" wins over
+// "Library".
+func (m *CampusMap) Geocode(ctx context.Context, location string) (Point, error) {
+	location = " " + normalizeName(location) + " "
+
+	var best *Place
+	bestLength := 0
+	for i, place := range m.places {
+		for _, name := range append([]string{place.Name}, place.Aliases...) {
+			name = normalizeName(name)
+			if name != "" && len(name) > bestLength && strings.Contains(location, " "+name+" ") {
+				best = &m.places[i]
+				bestLength = len(name)
+			}
+		}
+	}
+
+	if best == nil {
+		return Point{}, ErrNotFound
+	}
+	return Point{Latitude: best.Latitude, Longitude: best.Longitude}, nil
+}
@@ -0,0 +1,93 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"math"
+)
+
+var ErrNotFound = errors.New("location not found")
+
+// earthRadius is the mean radius of the Earth in meters.
+const earthRadius = 6371000.0
+
+// Point is a position in decimal degrees.
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Geocoder turns a free-form location such as "Main Hall, room 2" into
+// coordinates.
+type Geocoder interface {
+	// Geocode returns the location's coordinates, or ErrNotFound.
+	Geocode(ctx context.Context, location string) (Point, error)
+}
+
+// Valid reports whether the point lies within the range of coordinates.
+func (p Point) Valid() bool {
+	return p.Latitude >= -90 && p.Latitude <= 90 && p.Longitude >= -180 && p.Longitude <= 180
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
+
+// Distance returns the great-circle distance between a and b in meters,
+// using the haversine formula.
+func Distance(a, b Point) float64 {
+	dLat := radians(b.Latitude - a.Latitude)
+	dLng := radians(b.Longitude - a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(radians(a.Latitude))*math.Cos(radians(b.Latitude))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Box is a latitude and longitude range. MinLongitude is greater than
+// MaxLongitude when the box crosses the antimeridian.
+type Box struct {
+	MinLatitude, MaxLatitude   float64
+	MinLongitude, MaxLongitude float64
+}
+
+// BoundingBox returns a box containing every point within radius meters of
+// center, so candidates can be found with a cheap range query before
+// checking their exact Distance.
+func BoundingBox(center Point, radius float64) Box {
+	dLat := degrees(radius / earthRadius)
+	box := Box{
+		MinLatitude:  center.Latitude - dLat,
+		MaxLatitude:  center.Latitude + dLat,
+		MinLongitude: -180,
+		MaxLongitude: 180,
+	}
+
+	// Near the poles every longitude may be within reach
+	if box.MinLatitude <= -90 || box.MaxLatitude >= 90 {
+		box.MinLatitude = math.Max(box.MinLatitude, -90)
+		box.MaxLatitude = math.Min(box.MaxLatitude, 90)
+		return box
+	}
+
+	dLng := degrees(math.Asin(math.Sin(radius/earthRadius) / math.Cos(radians(center.Latitude))))
+	if dLng >= 180 {
+		return box
+	}
+	box.MinLongitude = wrapLongitude(center.Longitude - dLng)
+	box.MaxLongitude = wrapLongitude(center.Longitude + dLng)
+	return box
+}
+
+func wrapLongitude(longitude float64) float64 {
+	if longitude < -180 {
+		return longitude + 360
+	}
+	if longitude > 180 {
+		return longitude - 360
+	}
+	return longitude
+}
@@ -0,0 +1,68 @@
+package geo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	london := Point{Latitude: 51.5074, Longitude: -0.1278}
+	paris := Point{Latitude: 48.8566, Longitude: 2.3522}
+
+	assert.InDelta(t, 343500, Distance(london, paris), 1000)
+	assert.InDelta(t, 0, Distance(london, london), 1e-6)
+	// Across the antimeridian
+	assert.InDelta(t, 22239, Distance(Point{0, 179.9}, Point{0, -179.9}), 10)
+}
+
+func TestBoundingBox(t *testing.T) {
+	center := Point{Latitude: 52.2053, Longitude: 0.1218}
+	box := BoundingBox(center, 1000)
+
+	// Points on the edge of the radius fall inside the box
+	for _, bearing := range []Point{{0.00899, 0}, {-0.00899, 0}, {0, 0.0146}, {0, -0.0146}} {
+		p := Point{center.Latitude + bearing.Latitude, center.Longitude + bearing.Longitude}
+		assert.LessOrEqual(t, Distance(center, p), 1001.0)
+		assert.True(t, p.Latitude >= box.MinLatitude && p.Latitude <= box.MaxLatitude, p)
+		assert.True(t, p.Longitude >= box.MinLongitude && p.Longitude <= box.MaxLongitude, p)
+	}
+
+	wrapped := BoundingBox(Point{Latitude: 0, Longitude: 179.99}, 5000)
+	assert.Greater(t, wrapped.MinLongitude, wrapped.MaxLongitude)
+
+	polar := BoundingBox(Point{Latitude: 89.99, Longitude: 10}, 5000)
+	assert.Equal(t, 90.0, polar.MaxLatitude)
+	assert.Equal(t, -180.0, polar.MinLongitude)
+	assert.Equal(t, 180.0, polar.MaxLongitude)
+}
+
+func TestCampusMap_Geocode(t *testing.T) {
+	campus, err := LoadCampusMap("testdata/campus.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx := context.Background()
+	point, err := campus.Geocode(ctx, "Main Hall, Room 2.14")
+	assert.NoError(t, err)
+	assert.Equal(t, Point{Latitude: 52.2053, Longitude: 0.1218}, point)
+
+	point, err = campus.Geocode(ctx, "MH 101")
+	assert.NoError(t, err)
+	assert.Equal(t, 52.2053, point.Latitude)
+
+	// The longest matching name wins
+	point, err = campus.Geocode(ctx, "Ground floor of the science library")
+	assert.NoError(t, err)
+	assert.Equal(t, 52.2098, point.Latitude)
+
+	_, err = campus.Geocode(ctx, "Mhall")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = NewCampusMap([]Place{{Name: "Nowhere", Latitude: 91}})
+	assert.Error(t, err)
+	_, err = LoadCampusMap("testdata/missing.json")
+	assert.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // decoders for the accepted upload types
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxPixels bounds the decoded size of an upload, so a small file cannot
+// expand into an image too large to hold in memory.
+const MaxPixels = 40_000_000
+
+var (
+	ErrUnsupportedType = errors.New("image must be a JPEG, PNG, GIF or WebP file")
+	ErrTooLarge        = errors.New("image dimensions are too large")
+)
+
+// Variant is one of the sizes an upload is stored in. Cropped variants fill
+// Width x Height exactly, cutting the edges off to keep the centre; the others
+// are scaled down to fit within it.
+type Variant struct {
+	Name   string
+	Width  int
+	Height int
+	Crop   bool
+}
+
+var (
+	Thumbnail = Variant{Name: "thumbnail", Width: 200, Height: 200, Crop: true}
+	Card      = Variant{Name: "card", Width: 640, Height: 360, Crop: true}
+	Full      = Variant{Name: "full", Width: 1600, Height: 1600}
+)
+
+// Variants lists every size an event image is stored in.
+var Variants = []Variant{Thumbnail, Card, Full}
+
+// Sniff returns the content type of an upload from its first bytes, whatever
+// the client claimed it to be.
+func Sniff(header []byte) (string, error) {
+	contentType := http.DetectContentType(header)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return contentType, nil
+	}
+	return "", ErrUnsupportedType
+}
+
+// Decode reads an image after checking its dimensions are reasonable.
+func Decode(data []byte) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+	return img, nil
+}
+
+// Resize scales src to the variant. Images are never scaled up.
+func Resize(src image.Image, v Variant) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	var crop image.Rectangle
+	var outWidth, outHeight int
+	if v.Crop {
+		// Take the largest centred region with the variant's aspect ratio
+		cropWidth, cropHeight := width, width*v.Height/v.Width
+		if cropHeight > height {
+			cropWidth, cropHeight = height*v.Width/v.Height, height
+		}
+		// Very thin images would otherwise round to an empty region
+		cropWidth, cropHeight = max(cropWidth, 1), max(cropHeight, 1)
+		x := bounds.Min.X + (width-cropWidth)/2
+		y := bounds.Min.Y + (height-cropHeight)/2
+		crop = image.Rect(x, y, x+cropWidth, y+cropHeight)
+		outWidth, outHeight = max(min(v.Width, cropWidth), 1), max(min(v.Height, cropHeight), 1)
+	} else {
+		crop = bounds
+		scale := min(float64(v.Width)/float64(width), float64(v.Height)/float64(height), 1)
+		outWidth, outHeight = max(int(float64(width)*scale), 1), max(int(float64(height)*scale), 1)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, outWidth, outHeight))
+	// JPEG has no transparency, so transparent areas become white
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, xdraw.Over, nil)
+	return dst
+}
+
+// EncodeJPEG writes the image as a JPEG suitable for the web.
+func EncodeJPEG(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+}
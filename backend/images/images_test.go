@@ -0,0 +1,87 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := range width {
+		img.Set(x, height/2, color.NRGBA{B: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestSniff(t *testing.T) {
+	contentType, err := Sniff(encodePNG(t, 2, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+
+	_, err = Sniff([]byte("<svg xmlns='http://www.w3.org/2000/svg'></svg>"))
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = Sniff([]byte("%PDF-1.4"))
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestResize_Variants(t *testing.T) {
+	img, err := Decode(encodePNG(t, 2000, 1000))
+	if err != nil {
+		t.Fatalf("Failed to decode image: %v", err)
+	}
+
+	assert.Equal(t, image.Rect(0, 0, 200, 200), Resize(img, Thumbnail).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 640, 360), Resize(img, Card).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 1600, 800), Resize(img, Full).Bounds())
+
+	var buf bytes.Buffer
+	assert.NoError(t, EncodeJPEG(&buf, Resize(img, Card)))
+	contentType, _ := Sniff(buf.Bytes())
+	assert.Equal(t, "image/jpeg", contentType)
+}
+
+func TestResize_NeverScalesUp(t *testing.T) {
+	img, err := Decode(encodePNG(t, 120, 90))
+	if err != nil {
+		t.Fatalf("Failed to decode image: %v", err)
+	}
+
+	assert.Equal(t, image.Rect(0, 0, 120, 90), Resize(img, Full).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 90, 90), Resize(img, Thumbnail).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 120, 67), Resize(img, Card).Bounds())
+}
+
+func TestResize_VeryThinImages(t *testing.T) {
+	for _, size := range []image.Point{{1, 100}, {100, 1}, {1, 1}} {
+		img, err := Decode(encodePNG(t, size.X, size.Y))
+		if err != nil {
+			t.Fatalf("Failed to decode image: %v", err)
+		}
+
+		for _, variant := range Variants {
+			bounds := Resize(img, variant).Bounds()
+			assert.Positive(t, bounds.Dx(), "%v %s", size, variant.Name)
+			assert.Positive(t, bounds.Dy(), "%v %s", size, variant.Name)
+
+			var buf bytes.Buffer
+			assert.NoError(t, EncodeJPEG(&buf, Resize(img, variant)))
+		}
+	}
+}
+
+func TestDecode_RejectsGarbage(t *testing.T) {
+	data := encodePNG(t, 4, 4)
+	_, err := Decode(data[:len(data)/2])
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
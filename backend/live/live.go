@@ -0,0 +1,355 @@
+// Package live runs the per-event WebSocket channel used for audience polls
+// and Q&A during a talk. Polls, votes and questions are stored in the database
+// so a client that reconnects gets the current state back.
+package live
+
+import (
+	"encoding/json"
+	"errors"
+	"event-planner/bus"
+	"event-planner/models"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Client message types.
+const (
+	PollCreate     = "poll.create"
+	PollVote       = "poll.vote"
+	PollClose      = "poll.close"
+	QuestionAsk    = "question.ask"
+	QuestionUpvote = "question.upvote"
+	QuestionAnswer = "question.answer"
+)
+
+// Server message types.
+const (
+	State           = "state"
+	PollUpdated     = "poll.updated"
+	QuestionUpdated = "question.updated"
+	Error           = "error"
+)
+
+const (
+	maxOptions      = 10
+	maxTextLength   = 500
+	maxMessageBytes = 4096
+	sendBuffer      = 16
+	writeWait       = 10 * time.Second
+	pongWait        = 60 * time.Second
+	pingPeriod      = pongWait * 9 / 10
+	historySize     = 100
+)
+
+// Bus carries poll and question updates between connections. It is separate
+// from bus.Default so audience traffic does not show up on the public event
+// streams.
+var Bus = bus.New(historySize)
+
+// Request is a message sent by a client.
+type Request struct {
+	Type       string   `json:"type"`
+	Question   string   `json:"question,omitempty"`
+	Options    []string `json:"options,omitempty"`
+	PollID     int64    `json:"pollId,omitempty"`
+	Option     int      `json:"option"`
+	Text       string   `json:"text,omitempty"`
+	QuestionID int64    `json:"questionId,omitempty"`
+}
+
+// Response is a message sent to clients, either in reply to a request or
+// broadcast to everyone connected to the event.
+type Response struct {
+	Type      string            `json:"type"`
+	Poll      *models.Poll      `json:"poll,omitempty"`
+	Question  *models.Question  `json:"question,omitempty"`
+	Polls     []models.Poll     `json:"polls,omitempty"`
+	Questions []models.Question `json:"questions,omitempty"`
+	Message   string            `json:"message,omitempty"`
+}
+
+// Session is one connected user. Moderators may create and close polls and
+// mark questions answered; everyone connected may vote, ask and upvote.
+type Session struct {
+	Conn        *websocket.Conn
+	EventID     int64
+	UserID      int64
+	CanModerate bool
+	send        chan Response
+}
+
+// Serve sends the current polls and questions, then handles requests until
+// the client disconnects. It closes the connection before returning.
+func Serve(session *Session) {
+	session.send = make(chan Response, sendBuffer)
+	subscription, _, _ := Bus.Subscribe(Bus.LastID(), func(msg bus.Message) bool {
+		return msg.EventID == session.EventID
+	})
+
+	done := make(chan struct{})
+	go session.writePump(subscription, done)
+	defer func() {
+		close(done)
+		subscription.Close()
+	}()
+
+	state, err := Snapshot(session.EventID)
+	if err != nil {
+		log.Printf("could not load live state for event %d: %v", session.EventID, err)
+		return
+	}
+	session.reply(*state)
+
+	session.Conn.SetReadLimit(maxMessageBytes)
+	session.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	session.Conn.SetPongHandler(func(string) error {
+		return session.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := session.Conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var request Request
+		err = json.Unmarshal(data, &request)
+		if err != nil {
+			session.reply(Response{Type: Error, Message: "could not parse message"})
+			continue
+		}
+
+		err = session.Handle(request)
+		if err != nil {
+			session.reply(Response{Type: Error, Message: err.Error()})
+		}
+	}
+}
+
+// Snapshot returns the event's polls and Q&A queue.
+func Snapshot(eventID int64) (*Response, error) {
+	polls, err := models.GetPollsForEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := models.GetQuestionsForEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{Type: State, Polls: polls, Questions: questions}, nil
+}
+
+// Handle applies one request and broadcasts the result. The returned error
+// is safe to show to the client.
+func (s *Session) Handle(request Request) error {
+	switch request.Type {
+	case PollCreate:
+		return s.createPoll(request)
+	case PollVote:
+		return s.vote(request)
+	case PollClose:
+		return s.closePoll(request)
+	case QuestionAsk:
+		return s.ask(request)
+	case QuestionUpvote:
+		return s.upvote(request)
+	case QuestionAnswer:
+		return s.answer(request)
+	}
+	return errors.New("unknown message type")
+}
+
+func (s *Session) createPoll(request Request) error {
+	if !s.CanModerate {
+		return errors.New("only organizers can create polls")
+	}
+
+	question := strings.TrimSpace(request.Question)
+	if question == "" || len(question) > maxTextLength {
+		return errors.New("poll question is required")
+	}
+
+	var options []string
+	for _, option := range request.Options {
+		option = strings.TrimSpace(option)
+		if option != "" {
+			options = append(options, option)
+		}
+	}
+	if len(options) < 2 || len(options) > maxOptions {
+		return errors.New("a poll needs between 2 and 10 options")
+	}
+
+	poll := models.Poll{EventID: s.EventID, Question: question, Options: options, CreatedBy: s.UserID}
+	err := poll.Save()
+	if err != nil {
+		return s.internal("save poll", err)
+	}
+
+	Bus.Publish(PollUpdated, s.EventID, Response{Type: PollUpdated, Poll: &poll})
+	return nil
+}
+
+func (s *Session) vote(request Request) error {
+	poll, err := s.poll(request.PollID)
+	if err != nil {
+		return err
+	}
+
+	err = poll.Vote(s.UserID, request.Option)
+	switch {
+	case errors.Is(err, models.ErrAlreadyVoted), errors.Is(err, models.ErrPollClosed), errors.Is(err, models.ErrInvalidVote):
+		return err
+	case err != nil:
+		return s.internal("record vote", err)
+	}
+
+	Bus.Publish(PollUpdated, s.EventID, Response{Type: PollUpdated, Poll: poll})
+	return nil
+}
+
+func (s *Session) closePoll(request Request) error {
+	if !s.CanModerate {
+		return errors.New("only organizers can close polls")
+	}
+
+	poll, err := s.poll(request.PollID)
+	if err != nil {
+		return err
+	}
+
+	err = poll.Close()
+	if err != nil {
+		return s.internal("close poll", err)
+	}
+
+	Bus.Publish(PollUpdated, s.EventID, Response{Type: PollUpdated, Poll: poll})
+	return nil
+}
+
+func (s *Session) ask(request Request) error {
+	text := strings.TrimSpace(request.Text)
+	if text == "" || len(text) > maxTextLength {
+		return errors.New("question text is required")
+	}
+
+	question := models.Question{EventID: s.EventID, UserID: s.UserID, Text: text}
+	err := question.Save()
+	if err != nil {
+		return s.internal("save question", err)
+	}
+
+	Bus.Publish(QuestionUpdated, s.EventID, Response{Type: QuestionUpdated, Question: &question})
+	return nil
+}
+
+func (s *Session) upvote(request Request) error {
+	question, err := s.question(request.QuestionID)
+	if err != nil {
+		return err
+	}
+
+	err = question.Upvote(s.UserID)
+	if errors.Is(err, models.ErrAlreadyUpvoted) {
+		return err
+	}
+	if err != nil {
+		return s.internal("record upvote", err)
+	}
+
+	Bus.Publish(QuestionUpdated, s.EventID, Response{Type: QuestionUpdated, Question: question})
+	return nil
+}
+
+func (s *Session) answer(request Request) error {
+	if !s.CanModerate {
+		return errors.New("only organizers can mark questions answered")
+	}
+
+	question, err := s.question(request.QuestionID)
+	if err != nil {
+		return err
+	}
+
+	err = question.MarkAnswered()
+	if err != nil {
+		return s.internal("mark question answered", err)
+	}
+
+	Bus.Publish(QuestionUpdated, s.EventID, Response{Type: QuestionUpdated, Question: question})
+	return nil
+}
+
+// Helper function to load a poll that belongs to the session's event
+func (s *Session) poll(id int64) (*models.Poll, error) {
+	poll, err := models.GetPollByID(id)
+	if err != nil || poll.EventID != s.EventID {
+		return nil, errors.New("poll not found")
+	}
+	return poll, nil
+}
+
+// Helper function to load a question that belongs to the session's event
+func (s *Session) question(id int64) (*models.Question, error) {
+	question, err := models.GetQuestionByID(id)
+	if err != nil || question.EventID != s.EventID {
+		return nil, errors.New("question not found")
+	}
+	return question, nil
+}
+
+func (s *Session) internal(action string, err error) error {
+	log.Printf("live: could not %s for event %d: %v", action, s.EventID, err)
+	return errors.New("could not " + action)
+}
+
+// reply queues a message for this client only. A client that stops reading
+// is disconnected rather than allowed to block the reader.
+func (s *Session) reply(response Response) {
+	select {
+	case s.send <- response:
+	default:
+		s.Conn.Close()
+	}
+}
+
+// writePump is the only goroutine that writes to the connection, as
+// gorilla/websocket allows one concurrent writer.
+func (s *Session) writePump(subscription *bus.Subscription, done <-chan struct{}) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		s.Conn.Close()
+	}()
+
+	for {
+		var err error
+		select {
+		case <-done:
+			s.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			s.Conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		case response := <-s.send:
+			s.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			err = s.Conn.WriteJSON(response)
+		case msg, open := <-subscription.C:
+			if !open {
+				// Dropped for falling behind; the client reconnects and gets a fresh state
+				return
+			}
+			s.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			err = s.Conn.WriteJSON(msg.Data)
+		case <-ticker.C:
+			s.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			err = s.Conn.WriteMessage(websocket.PingMessage, nil)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
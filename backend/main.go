@@ -2,8 +2,21 @@ package main
 
 import (
 	"event-planner/db"
+	"event-planner/geo"
+	"event-planner/models"
+	"event-planner/notifications"
+	"event-planner/recommend"
 	"event-planner/routes"
+	"event-planner/scheduler"
+	"event-planner/storage"
+	"event-planner/utils"
+	"event-planner/webhooks"
+	"log"
+	"net/http"
+	"os"
+	"strings"
 	"time"
+	_ "time/tzdata" // user time zones must resolve in the slim container image
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -11,11 +24,96 @@ import (
 
 func main() {
 	db.InitDB()
+
+	adminEmails := os.Getenv("ADMIN_EMAILS")
+	if adminEmails != "" {
+		err := models.PromoteAdmins(strings.Split(adminEmails, ","))
+		if err != nil {
+			log.Printf("could not promote admins: %v", err)
+		}
+	}
+
+	var emailNotifier notifications.Notifier = notifications.LogNotifier{}
+	smtpNotifier, ok := notifications.EmailNotifierFromEnv()
+	if ok {
+		emailNotifier = smtpNotifier
+	}
+
+	publicURL := getEnv("PUBLIC_URL", "http://localhost:8080")
+	routes.PublicURL = publicURL
+	if window, err := time.ParseDuration(os.Getenv("TRENDING_WINDOW")); err == nil {
+		routes.TrendingWindow = window
+	}
+	routes.Storage = storage.Local{Dir: getEnv("UPLOAD_DIR", "uploads"), BaseURL: publicURL + "/uploads"}
+	if os.Getenv("STORAGE_DRIVER") == "s3" {
+		routes.Storage = storage.S3{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    getEnv("S3_REGION", "us-east-1"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+			Client:    &http.Client{Timeout: 30 * time.Second},
+		}
+	}
+
+	if path := os.Getenv("CAMPUS_MAP_FILE"); path != "" {
+		campus, err := geo.LoadCampusMap(path)
+		if err != nil {
+			log.Printf("could not load campus map, events will not be geocoded: %v", err)
+		} else {
+			routes.Geocoder = campus
+		}
+	}
+
+	dispatcher := &notifications.Dispatcher{
+		Channels: map[string]notifications.Notifier{
+			notifications.ChannelEmail: emailNotifier,
+			notifications.ChannelInApp: notifications.InAppNotifier{},
+			// No SMS provider is integrated yet, so texts are only logged
+			notifications.ChannelSMS:     notifications.LogNotifier{},
+			notifications.ChannelWebhook: notifications.WebhookNotifier{Client: utils.NewOutboundClient(10 * time.Second)},
+		},
+		BaseURL: publicURL,
+	}
+	notifications.Default = dispatcher
+
+	jobs := scheduler.New()
+	jobs.Every("reminders", time.Minute, func(now time.Time) error {
+		return notifications.SendDueReminders(notifications.Default, now)
+	})
+	jobs.Every("queued-notifications", time.Minute, dispatcher.SendQueued)
+	jobs.Every("survey-invites", time.Minute, func(now time.Time) error {
+		return notifications.SendSurveyInvites(notifications.Default, now)
+	})
+	jobs.Every("saved-searches", time.Minute, func(now time.Time) error {
+		return notifications.SendSavedSearchMatches(notifications.Default, now)
+	})
+	jobs.Every("lottery-draws", time.Minute, func(now time.Time) error {
+		return notifications.DrawLotteries(notifications.Default, now)
+	})
+	jobs.Every("recommendations", time.Hour, recommend.Refresh)
+	jobs.Every("feed-reminders", 5*time.Minute, notifications.AddFeedReminders)
+	jobs.Every("expired-invitations", 5*time.Minute, models.ExpireInvitations)
+
+	deliverer := webhooks.Deliverer{
+		Client:      utils.NewOutboundClient(10 * time.Second),
+		MaxAttempts: 8,
+		BaseBackoff: 30 * time.Second,
+	}
+	jobs.Every("webhooks", 10*time.Second, deliverer.DeliverDue)
+	jobs.Start()
+	defer jobs.Stop()
+
 	server := gin.Default()
+	err := routes.ConfigureProxies(server, os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// ✅ Enable CORS so React frontend can call API
 	server.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:5173", "http://localhost:3000"},
+		AllowOrigins:     routes.AllowedOrigins,
 		AllowMethods:     []string{"POST", "GET", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
@@ -27,3 +125,11 @@ func main() {
 
 	server.Run(":8080") // localhost:8080
 }
+
+func getEnv(key, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"event-planner/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin must run after Authenticate.
+func RequireAdmin(context *gin.Context) {
+	admin, err := models.IsAdmin(context.GetInt64("userId"))
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "Could not check admin rights"})
+		return
+	}
+
+	if !admin {
+		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Only administrators can do this"})
+		return
+	}
+
+	context.Next()
+}
@@ -0,0 +1,219 @@
+package models
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"event-planner/db"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Attachment visibilities.
+const (
+	AttachmentPublic      = "public"
+	AttachmentRegistrants = "registrants"
+)
+
+// Attachment quotas, in bytes.
+const (
+	MaxAttachmentSize       = 20 << 20
+	MaxEventAttachmentsSize = 100 << 20
+)
+
+var (
+	ErrAttachmentType  = errors.New("attachments must be PDFs, Office or OpenDocument files, text files or images")
+	ErrAttachmentQuota = errors.New("the attachments of an event may not exceed 100MB in total")
+)
+
+var (
+	zipMagic = []byte("PK\x03\x04")
+	oleMagic = []byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1")
+)
+
+// attachmentTypes maps each accepted extension to the content type files are
+// served with and a check of the file's first bytes, so a renamed executable
+// is not accepted as a document.
+var attachmentTypes = map[string]struct {
+	contentType string
+	matches     func(header []byte) bool
+}{
+	".pdf":  {"application/pdf", sniffed("application/pdf")},
+	".doc":  {"application/msword", hasMagic(oleMagic)},
+	".xls":  {"application/vnd.ms-excel", hasMagic(oleMagic)},
+	".ppt":  {"application/vnd.ms-powerpoint", hasMagic(oleMagic)},
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", hasMagic(zipMagic)},
+	".xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", hasMagic(zipMagic)},
+	".pptx": {"application/vnd.openxmlformats-officedocument.presentationml.presentation", hasMagic(zipMagic)},
+	".odt":  {"application/vnd.oasis.opendocument.text", hasMagic(zipMagic)},
+	".ods":  {"application/vnd.oasis.opendocument.spreadsheet", hasMagic(zipMagic)},
+	".odp":  {"application/vnd.oasis.opendocument.presentation", hasMagic(zipMagic)},
+	".txt":  {"text/plain; charset=utf-8", sniffed("text/plain; charset=utf-8")},
+	".csv":  {"text/csv; charset=utf-8", sniffed("text/plain; charset=utf-8")},
+	".png":  {"image/png", sniffed("image/png")},
+	".jpg":  {"image/jpeg", sniffed("image/jpeg")},
+	".jpeg": {"image/jpeg", sniffed("image/jpeg")},
+	".gif":  {"image/gif", sniffed("image/gif")},
+	".webp": {"image/webp", sniffed("image/webp")},
+}
+
+func sniffed(contentType string) func([]byte) bool {
+	return func(header []byte) bool {
+		return http.DetectContentType(header) == contentType
+	}
+}
+
+func hasMagic(magic []byte) func([]byte) bool {
+	return func(header []byte) bool {
+		return bytes.HasPrefix(header, magic)
+	}
+}
+
+// DetectAttachmentType returns the content type of an upload named name
+// whose contents start with header. Both the extension and the contents must
+// agree on an accepted type.
+func DetectAttachmentType(name string, header []byte) (string, error) {
+	accepted, ok := attachmentTypes[strings.ToLower(filepath.Ext(name))]
+	if !ok || !accepted.matches(header) {
+		return "", ErrAttachmentType
+	}
+	return accepted.contentType, nil
+}
+
+// CleanAttachmentName keeps the base name of an uploaded file, without
+// control characters, so it is safe to echo back in headers.
+func CleanAttachmentName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+
+	runes := []rune(strings.TrimSpace(name))
+	if len(runes) > 200 {
+		runes = runes[len(runes)-200:]
+	}
+	return string(runes)
+}
+
+// Attachment is a file organizers share with an event, such as an agenda or
+// a waiver. Registrants-only attachments are hidden from everyone but
+// confirmed registrants and the organizers.
+type Attachment struct {
+	ID          int64
+	EventID     int64
+	Name        string
+	ContentType string
+	Size        int64
+	Key         string `json:"-"`
+	Visibility  string
+	UploadedBy  int64
+	CreatedAt   time.Time
+}
+
+func ValidateAttachmentVisibility(visibility string) error {
+	if visibility != AttachmentPublic && visibility != AttachmentRegistrants {
+		return errors.New("visibility must be public or registrants")
+	}
+	return nil
+}
+
+// NewAttachmentKey picks a fresh storage key for a file attached to the event.
+func NewAttachmentKey(eventID int64) (string, error) {
+	buf := make([]byte, 16)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return "attachments/" + strconv.FormatInt(eventID, 10) + "/" + hex.EncodeToString(buf), nil
+}
+
+// AttachmentsSize returns the bytes already attached to the event.
+func AttachmentsSize(eventID int64) (int64, error) {
+	var size int64
+	err := db.DB.QueryRow("SELECT COALESCE(SUM(size), 0) FROM attachments WHERE event_id = ?", eventID).Scan(&size)
+	return size, err
+}
+
+func scanAttachment(row scanner) (*Attachment, error) {
+	var attachment Attachment
+	err := row.Scan(&attachment.ID, &attachment.EventID, &attachment.Name, &attachment.ContentType, &attachment.Size,
+		&attachment.Key, &attachment.Visibility, &attachment.UploadedBy, &attachment.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func GetAttachment(id int64) (*Attachment, error) {
+	query := `
+	SELECT id, event_id, name, content_type, size, storage_key, visibility, uploaded_by, created_at
+	FROM attachments WHERE id = ?`
+	return scanAttachment(db.DB.QueryRow(query, id))
+}
+
+// Attachments returns the files attached to the event, oldest first.
+func (e Event) Attachments() ([]Attachment, error) {
+	query := `
+	SELECT id, event_id, name, content_type, size, storage_key, visibility, uploaded_by, created_at
+	FROM attachments WHERE event_id = ? ORDER BY created_at, id`
+	rows, err := db.DB.Query(query, e.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *attachment)
+	}
+	return attachments, rows.Err()
+}
+
+// Save records the attachment unless it would take the event over
+// MaxEventAttachmentsSize, in which case it returns ErrAttachmentQuota.
+func (a *Attachment) Save() error {
+	query := `
+	INSERT INTO attachments (event_id, name, content_type, size, storage_key, visibility, uploaded_by, created_at)
+	SELECT ?, ?, ?, ?, ?, ?, ?, ?
+	WHERE (SELECT COALESCE(SUM(size), 0) FROM attachments WHERE event_id = ?) + ? <= ?`
+
+	a.CreatedAt = time.Now().UTC()
+	result, err := db.DB.Exec(query, a.EventID, a.Name, a.ContentType, a.Size, a.Key, a.Visibility, a.UploadedBy, a.CreatedAt,
+		a.EventID, a.Size, MaxEventAttachmentsSize)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAttachmentQuota
+	}
+
+	a.ID, err = result.LastInsertId()
+	return err
+}
+
+func (a Attachment) UpdateVisibility(visibility string) error {
+	_, err := db.DB.Exec("UPDATE attachments SET visibility = ? WHERE id = ?", visibility, a.ID)
+	return err
+}
+
+func (a Attachment) Delete() error {
+	_, err := db.DB.Exec("DELETE FROM attachments WHERE id = ?", a.ID)
+	return err
+}
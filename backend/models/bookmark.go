@@ -0,0 +1,52 @@
+package models
+
+import (
+	"event-planner/db"
+	"time"
+)
+
+// AddBookmark saves the event to the user's bookmarks; bookmarking twice is
+// not an error.
+func AddBookmark(userID, eventID int64) error {
+	query := `
+	INSERT INTO bookmarks (user_id, event_id, created_at)
+	VALUES (?, ?, ?)
+	ON CONFLICT(user_id, event_id) DO NOTHING`
+
+	_, err := db.DB.Exec(query, userID, eventID, time.Now().UTC())
+	return err
+}
+
+func RemoveBookmark(userID, eventID int64) error {
+	_, err := db.DB.Exec("DELETE FROM bookmarks WHERE user_id = ? AND event_id = ?", userID, eventID)
+	return err
+}
+
+// GetBookmarkedEvents returns the events the user bookmarked, in order of
+// start time.
+func GetBookmarkedEvents(userID int64) ([]Event, error) {
+	query := "SELECT " + eventColumns + " FROM events WHERE id IN (SELECT event_id FROM bookmarks WHERE user_id = ?) ORDER BY julianday(dateTime), id"
+	rows, err := db.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, loadTags(events)
+}
+
+// Bookmarkers returns the users who bookmarked the event.
+func (e Event) Bookmarkers() ([]int64, error) {
+	return queryIDs("SELECT user_id FROM bookmarks WHERE event_id = ? ORDER BY user_id", e.ID)
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// CalendarEntry is one occurrence of an event. Start and End are in the
+// calendar's time zone.
+type CalendarEntry struct {
+	Event Event
+	Start time.Time
+	End   time.Time
+}
+
+// CalendarDay holds the entries taking place on one local day, in order of
+// start time. Entries spanning several days appear on each of them.
+type CalendarDay struct {
+	Date    string
+	Entries []CalendarEntry
+}
+
+// Calendar is a month, week or day of events, cut into local days. Days
+// covers [Start, End) without gaps so clients can draw the grid as is.
+type Calendar struct {
+	View     string
+	TimeZone string
+	Start    time.Time
+	End      time.Time
+	Days     []CalendarDay
+}
+
+// calendarRange returns the bounds of the view containing date in its
+// location. Weeks start on Monday.
+func calendarRange(view string, date time.Time) (time.Time, time.Time, error) {
+	start := bucketStart(date, view)
+	switch view {
+	case IntervalDay:
+		return start, start.AddDate(0, 0, 1), nil
+	case IntervalWeek:
+		return start, start.AddDate(0, 0, 7), nil
+	case IntervalMonth:
+		return start, start.AddDate(0, 1, 0), nil
+	}
+	return time.Time{}, time.Time{}, errors.New("view must be day, week or month")
+}
+
+// localDay returns midnight of t's day in its location.
+func localDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// GetCalendar returns the events matching the filter that take place in the
+// view containing date, with recurring events expanded into their
+// occurrences. Days are cut in date's location. The filter's From and To are
+// ignored.
+func GetCalendar(view string, date time.Time, filter EventFilter) (*Calendar, error) {
+	start, end, err := calendarRange(view, date)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.From = nil
+	filter.To = nil
+	where, args := filter.where()
+	query := "SELECT " + eventColumns + " FROM events WHERE " + where + ` AND unixepoch(dateTime) < unixepoch(?)
+		AND (recurrence IS NOT NULL OR ` + endsSQL + ` > unixepoch(?))
+	ORDER BY julianday(dateTime), id`
+
+	events, err := queryEvents(query, append(args, end.UTC(), defaultEventSeconds, start.UTC())...)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := date.Location()
+	calendar := Calendar{View: view, TimeZone: loc.String(), Start: start, End: end, Days: []CalendarDay{}}
+	index := map[string]int{}
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		index[day.Format("2006-01-02")] = len(calendar.Days)
+		calendar.Days = append(calendar.Days, CalendarDay{Date: day.Format("2006-01-02"), Entries: []CalendarEntry{}})
+	}
+
+	for _, event := range events {
+		if !filter.nearby(event) {
+			continue
+		}
+
+		duration := event.Ends().Sub(event.DateTime)
+		for _, occurrence := range event.Occurrences(start, end, loc) {
+			entry := CalendarEntry{Event: event, Start: occurrence.In(loc), End: occurrence.Add(duration).In(loc)}
+			for day := localDay(entry.Start); day.Before(entry.End) && day.Before(end); day = day.AddDate(0, 0, 1) {
+				i, ok := index[day.Format("2006-01-02")]
+				if ok {
+					calendar.Days[i].Entries = append(calendar.Days[i].Entries, entry)
+				}
+			}
+		}
+	}
+
+	for _, day := range calendar.Days {
+		sort.SliceStable(day.Entries, func(i, j int) bool { return day.Entries[i].Start.Before(day.Entries[j].Start) })
+	}
+	return &calendar, nil
+}
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"errors"
+	"event-planner/db"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrCategoryExists = errors.New("a category with this slug already exists")
+	ErrCategoryInUse  = errors.New("category still has events or subcategories")
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Category is one node of the site-wide taxonomy admins manage. Events belong
+// to at most one category; filtering by a category includes its descendants.
+type Category struct {
+	ID       int64
+	Slug     string
+	Name     string `binding:"required"`
+	ParentID *int64
+}
+
+// Slugify turns a name into lowercase words joined by hyphens.
+func Slugify(name string) string {
+	var slug strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if hyphen && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+	return slug.String()
+}
+
+// Validate trims the name, derives the slug from it when none was given and
+// checks the parent exists without making the category its own ancestor.
+func (c *Category) Validate() error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" || len(c.Name) > 60 {
+		return errors.New("name must be between 1 and 60 characters")
+	}
+
+	if c.Slug == "" {
+		c.Slug = Slugify(c.Name)
+	}
+	if len(c.Slug) > 60 || !slugPattern.MatchString(c.Slug) {
+		return errors.New("slug must be lowercase letters, digits and single hyphens")
+	}
+
+	if c.ParentID == nil {
+		return nil
+	}
+
+	var count int
+	query := `
+	WITH RECURSIVE subtree(id) AS (
+		SELECT ?
+		UNION
+		SELECT categories.id FROM categories JOIN subtree ON categories.parent_id = subtree.id
+	)
+	SELECT
+		(SELECT COUNT(*) FROM categories WHERE id = ?),
+		(SELECT COUNT(*) FROM subtree WHERE id = ?)`
+	var cycle int
+	err := db.DB.QueryRow(query, c.ID, *c.ParentID, *c.ParentID).Scan(&count, &cycle)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("parent category does not exist")
+	}
+	if cycle > 0 {
+		return errors.New("a category cannot be nested under itself")
+	}
+	return nil
+}
+
+func scanCategory(row scanner) (*Category, error) {
+	var category Category
+	err := row.Scan(&category.ID, &category.Slug, &category.Name, &category.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetCategories returns the whole taxonomy ordered by name.
+func GetCategories() ([]Category, error) {
+	rows, err := db.DB.Query("SELECT id, slug, name, parent_id FROM categories ORDER BY name, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []Category{}
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, *category)
+	}
+	return categories, rows.Err()
+}
+
+func GetCategory(id int64) (*Category, error) {
+	return scanCategory(db.DB.QueryRow("SELECT id, slug, name, parent_id FROM categories WHERE id = ?", id))
+}
+
+// Save adds the category, returning ErrCategoryExists if its slug is taken.
+func (c *Category) Save() error {
+	query := `
+	INSERT INTO categories (slug, name, parent_id)
+	SELECT ?, ?, ?
+	WHERE NOT EXISTS (SELECT 1 FROM categories WHERE slug = ?)`
+
+	result, err := db.DB.Exec(query, c.Slug, c.Name, c.ParentID, c.Slug)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrCategoryExists
+	}
+
+	c.ID, err = result.LastInsertId()
+	return err
+}
+
+// Update renames or moves the category, returning ErrCategoryExists if the
+// new slug belongs to another one.
+func (c Category) Update() error {
+	query := `
+	UPDATE categories SET slug = ?, name = ?, parent_id = ?
+	WHERE id = ? AND NOT EXISTS (SELECT 1 FROM categories WHERE slug = ? AND id != ?)`
+
+	result, err := db.DB.Exec(query, c.Slug, c.Name, c.ParentID, c.ID, c.Slug, c.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrCategoryExists
+	}
+	return nil
+}
+
+// Delete removes a category nothing refers to any more, returning
+// ErrCategoryInUse otherwise.
+func (c Category) Delete() error {
+	query := `
+	DELETE FROM categories
+	WHERE id = ?
+		AND NOT EXISTS (SELECT 1 FROM categories WHERE parent_id = ?)
+		AND NOT EXISTS (SELECT 1 FROM events WHERE category_id = ?)`
+
+	result, err := db.DB.Exec(query, c.ID, c.ID, c.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrCategoryInUse
+	}
+	return nil
+}
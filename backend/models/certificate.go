@@ -0,0 +1,312 @@
+package models
+
+import (
+	"bytes"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"event-planner/db"
+	"image"
+	_ "image/jpeg" // logos may be JPEG
+	_ "image/png"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	maxCertificateTitle    = 120
+	maxCertificateBody     = 1000
+	maxCertificateName     = 100
+	maxSignatures          = 3
+	maxCertificateLogo     = 512 << 10
+	defaultCertificateBody = "for attending {event} on {date}."
+)
+
+var ErrCertificateName = errors.New("name must be between 1 and 100 characters")
+
+// CertificateSignature is a signatory printed at the foot of a certificate.
+type CertificateSignature struct {
+	Name string
+	Role string
+}
+
+// CertificateTemplate is the organizer's design for an event's attendance
+// certificates. Body may use {event} and {date}, and Logo is a PNG or JPEG
+// sent as base64 in JSON.
+type CertificateTemplate struct {
+	EventID    int64
+	Title      string `binding:"required"`
+	Body       string
+	Signatures []CertificateSignature
+	Logo       []byte
+}
+
+// Certificate is proof that a user attended an event. Until an organizer
+// approves a name with NameCertificate it is made out to the user's masked
+// email, since anyone holding the code can see the recipient.
+type Certificate struct {
+	ID            int64
+	Code          string
+	EventID       int64
+	UserID        int64
+	Email         string
+	RecipientName string
+	IssuedAt      time.Time
+}
+
+func (t CertificateTemplate) Validate() error {
+	if len(t.Title) > maxCertificateTitle || len(t.Body) > maxCertificateBody {
+		return errors.New("certificate title or body is too long")
+	}
+	if len(t.Signatures) > maxSignatures {
+		return errors.New("a certificate can have at most 3 signatures")
+	}
+	for _, signature := range t.Signatures {
+		if strings.TrimSpace(signature.Name) == "" {
+			return errors.New("signatures need a name")
+		}
+	}
+
+	if len(t.Logo) == 0 {
+		return nil
+	}
+	if len(t.Logo) > maxCertificateLogo {
+		return errors.New("logo must be at most 512KB")
+	}
+	contentType := http.DetectContentType(t.Logo)
+	if contentType != "image/png" && contentType != "image/jpeg" {
+		return errors.New("logo must be a PNG or JPEG image")
+	}
+	_, _, err := image.DecodeConfig(bytes.NewReader(t.Logo))
+	if err != nil {
+		return errors.New("logo is not a valid image")
+	}
+	return nil
+}
+
+// BodyFor fills in the template body for the event.
+func (t CertificateTemplate) BodyFor(event Event) string {
+	body := t.Body
+	if strings.TrimSpace(body) == "" {
+		body = defaultCertificateBody
+	}
+	return strings.NewReplacer(
+		"{event}", event.Name,
+		"{date}", event.DateTime.UTC().Format("2 January 2006"),
+	).Replace(body)
+}
+
+// GetCertificateTemplate returns the event's template, or sql.ErrNoRows if
+// the organizer has not set one up.
+func GetCertificateTemplate(eventID int64) (*CertificateTemplate, error) {
+	t := CertificateTemplate{EventID: eventID}
+	var signatures string
+	err := db.DB.QueryRow("SELECT title, body, signatures, logo FROM certificate_templates WHERE event_id = ?", eventID).
+		Scan(&t.Title, &t.Body, &signatures, &t.Logo)
+	if err != nil {
+		return nil, err
+	}
+	return &t, json.Unmarshal([]byte(signatures), &t.Signatures)
+}
+
+func (t CertificateTemplate) Save() error {
+	signatures, err := json.Marshal(t.Signatures)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO certificate_templates (event_id, title, body, signatures, logo)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(event_id) DO UPDATE SET
+		title = excluded.title,
+		body = excluded.body,
+		signatures = excluded.signatures,
+		logo = excluded.logo`
+
+	_, err = db.DB.Exec(query, t.EventID, t.Title, t.Body, string(signatures), t.Logo)
+	return err
+}
+
+// newCertificateCode returns a code like "K7QX2-M4PDA" that is easy to read
+// out and type.
+func newCertificateCode() (string, error) {
+	buf := make([]byte, 8)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	code := base32.StdEncoding.EncodeToString(buf)
+	return code[:5] + "-" + code[5:10], nil
+}
+
+// NormalizeCertificateCode makes codes typed by hand match the issued form.
+func NormalizeCertificateCode(code string) string {
+	code = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+	if len(code) == 10 && !strings.Contains(code, "-") {
+		code = code[:5] + "-" + code[5:]
+	}
+	return code
+}
+
+// maxCodeAttempts bounds how often issuing retries after drawing a code that
+// is already taken.
+const maxCodeAttempts = 5
+
+// IssueCertificate returns the user's certificate for the event, issuing one
+// in their masked email if they have none. Only checked-in attendees get
+// certificates; organizers can change the name with NameCertificate.
+func IssueCertificate(event Event, userID int64, now time.Time) (*Certificate, error) {
+	certificate, err := GetCertificate(event.ID, userID)
+	if err == nil {
+		return certificate, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return issueCertificate(event, userID, maskEmail(user.Email), now)
+}
+
+// maskEmail keeps the first character of the local part and the domain, like
+// "a***@example.com".
+func maskEmail(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found || local == "" {
+		return "***"
+	}
+	return string([]rune(local)[:1]) + "***@" + domain
+}
+
+// NameCertificate sets the name an organizer approved on the user's
+// certificate, issuing it if the user has none yet.
+func NameCertificate(event Event, userID int64, name string, now time.Time) (*Certificate, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxCertificateName {
+		return nil, ErrCertificateName
+	}
+
+	result, err := db.DB.Exec("UPDATE certificates SET recipient_name = ? WHERE event_id = ? AND user_id = ?", name, event.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if affected == 0 {
+		return issueCertificate(event, userID, name, now)
+	}
+	return GetCertificate(event.ID, userID)
+}
+
+func issueCertificate(event Event, userID int64, name string, now time.Time) (*Certificate, error) {
+	checkedIn, err := event.IsCheckedIn(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !checkedIn {
+		return nil, ErrNotRegistered
+	}
+
+	query := `
+	INSERT INTO certificates (code, event_id, user_id, recipient_name, issued_at)
+	SELECT ?, ?, ?, ?, ?
+	WHERE NOT EXISTS (SELECT 1 FROM certificates WHERE code = ?)
+	ON CONFLICT (event_id, user_id) DO NOTHING`
+
+	for range maxCodeAttempts {
+		code, err := newCertificateCode()
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := db.DB.Exec(query, code, event.ID, userID, name, now.UTC(), code)
+		if err != nil {
+			return nil, err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+
+		// Nothing was inserted either because a concurrent request issued the
+		// certificate first, or because the code is taken and needs redrawing
+		certificate, err := GetCertificate(event.ID, userID)
+		if affected == 1 || !errors.Is(err, sql.ErrNoRows) {
+			return certificate, err
+		}
+	}
+	return nil, errors.New("could not draw an unused certificate code")
+}
+
+const certificateColumns = `
+	c.id, c.code, c.event_id, c.user_id, u.email, c.recipient_name, c.issued_at
+	FROM certificates c
+	JOIN users u ON u.id = c.user_id`
+
+func scanCertificate(row scanner) (*Certificate, error) {
+	var c Certificate
+	err := row.Scan(&c.ID, &c.Code, &c.EventID, &c.UserID, &c.Email, &c.RecipientName, &c.IssuedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func GetCertificate(eventID, userID int64) (*Certificate, error) {
+	return scanCertificate(db.DB.QueryRow("SELECT "+certificateColumns+" WHERE c.event_id = ? AND c.user_id = ?", eventID, userID))
+}
+
+func GetCertificateByCode(code string) (*Certificate, error) {
+	return scanCertificate(db.DB.QueryRow("SELECT "+certificateColumns+" WHERE c.code = ?", NormalizeCertificateCode(code)))
+}
+
+// CertificateNames maps users to the name an organizer approved for their
+// certificate. Certificates still made out to the masked email are left out.
+func (e Event) CertificateNames() (map[int64]string, error) {
+	certificates, err := e.Certificates()
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[int64]string{}
+	for _, certificate := range certificates {
+		if certificate.RecipientName != maskEmail(certificate.Email) {
+			names[certificate.UserID] = certificate.RecipientName
+		}
+	}
+	return names, nil
+}
+
+// Certificates returns every certificate issued for the event.
+func (e Event) Certificates() ([]Certificate, error) {
+	rows, err := db.DB.Query("SELECT "+certificateColumns+" WHERE c.event_id = ? ORDER BY c.id", e.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	certificates := []Certificate{}
+
+	for rows.Next() {
+		certificate, err := scanCertificate(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		certificates = append(certificates, *certificate)
+	}
+	return certificates, rows.Err()
+}
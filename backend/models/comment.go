@@ -0,0 +1,307 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"event-planner/db"
+	"time"
+)
+
+var ErrAlreadyReported = errors.New("already reported this comment")
+
+// Moderation outcomes recorded on resolved reports.
+const (
+	ResolutionHidden    = "hidden"
+	ResolutionDismissed = "dismissed"
+)
+
+// Comment is a message in an event's discussion. Replies point at their
+// parent through ParentID. Deleted and hidden comments keep their place in
+// the thread so replies still make sense, but their body is not shown.
+type Comment struct {
+	ID        int64
+	EventID   int64
+	UserID    int64
+	ParentID  *int64
+	Body      string
+	Pinned    bool
+	Hidden    bool
+	Deleted   bool
+	CreatedAt time.Time
+	EditedAt  *time.Time
+	Replies   []Comment
+}
+
+// CommentReport is a user's report of an abusive comment.
+type CommentReport struct {
+	ID         int64
+	CommentID  int64
+	UserID     int64
+	Reason     string
+	CreatedAt  time.Time
+	Resolution *string
+	ResolvedBy *int64
+	ResolvedAt *time.Time
+}
+
+// ModerationItem is a comment with the reports still waiting for a moderator.
+type ModerationItem struct {
+	Comment Comment
+	Reports []CommentReport
+}
+
+const commentColumns = "id, event_id, user_id, parent_id, body, pinned, hidden, created_at, edited_at, deleted_at"
+
+func scanComment(row scanner) (*Comment, error) {
+	var c Comment
+	var parentID sql.NullInt64
+	var editedAt, deletedAt sql.NullTime
+	err := row.Scan(&c.ID, &c.EventID, &c.UserID, &parentID, &c.Body, &c.Pinned, &c.Hidden, &c.CreatedAt, &editedAt, &deletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentID.Valid {
+		c.ParentID = &parentID.Int64
+	}
+	if editedAt.Valid {
+		c.EditedAt = &editedAt.Time
+	}
+	c.Deleted = deletedAt.Valid
+	return &c, nil
+}
+
+func queryComments(query string, args ...any) ([]Comment, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []Comment{}
+
+	for rows.Next() {
+		comment, err := scanComment(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, *comment)
+	}
+	return comments, rows.Err()
+}
+
+func (c *Comment) Save() error {
+	query := `
+	INSERT INTO comments (event_id, user_id, parent_id, body, created_at)
+	VALUES (?, ?, ?, ?, ?)`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	c.CreatedAt = time.Now().UTC()
+	result, err := stmt.Exec(c.EventID, c.UserID, c.ParentID, c.Body, c.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	c.ID = id
+	return err
+}
+
+func GetCommentByID(id int64) (*Comment, error) {
+	query := "SELECT " + commentColumns + " FROM comments WHERE id = ?"
+	return scanComment(db.DB.QueryRow(query, id))
+}
+
+// GetCommentThread returns the event's top-level comments, pinned first and
+// then oldest first, each with its replies nested in posting order. Bodies of
+// deleted and hidden comments are blanked.
+func GetCommentThread(eventID int64) ([]Comment, error) {
+	query := "SELECT " + commentColumns + " FROM comments WHERE event_id = ? ORDER BY pinned DESC, id"
+	comments, err := queryComments(query, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	children := map[int64][]Comment{}
+	var roots []Comment
+	for _, comment := range comments {
+		if comment.Deleted || comment.Hidden {
+			comment.Body = ""
+		}
+
+		if comment.ParentID == nil {
+			roots = append(roots, comment)
+		} else {
+			children[*comment.ParentID] = append(children[*comment.ParentID], comment)
+		}
+	}
+
+	var attach func(comment *Comment)
+	attach = func(comment *Comment) {
+		comment.Replies = children[comment.ID]
+		for i := range comment.Replies {
+			attach(&comment.Replies[i])
+		}
+	}
+
+	thread := []Comment{}
+	for _, root := range roots {
+		attach(&root)
+		thread = append(thread, root)
+	}
+	return thread, nil
+}
+
+// CountCommentsSince returns how many comments the user posted after since.
+func CountCommentsSince(userID int64, since time.Time) (int, error) {
+	var count int
+	err := db.DB.QueryRow("SELECT COUNT(*) FROM comments WHERE user_id = ? AND created_at > ?", userID, since.UTC()).Scan(&count)
+	return count, err
+}
+
+func (c *Comment) Edit(body string) error {
+	editedAt := time.Now().UTC()
+	_, err := db.DB.Exec("UPDATE comments SET body = ?, edited_at = ? WHERE id = ?", body, editedAt, c.ID)
+	if err != nil {
+		return err
+	}
+
+	c.Body = body
+	c.EditedAt = &editedAt
+	return nil
+}
+
+// Delete removes the comment's text but keeps it in the thread for its replies.
+func (c *Comment) Delete() error {
+	_, err := db.DB.Exec("UPDATE comments SET body = '', deleted_at = ? WHERE id = ?", time.Now().UTC(), c.ID)
+	if err != nil {
+		return err
+	}
+
+	c.Body = ""
+	c.Deleted = true
+	return nil
+}
+
+func (c *Comment) SetPinned(pinned bool) error {
+	_, err := db.DB.Exec("UPDATE comments SET pinned = ? WHERE id = ?", pinned, c.ID)
+	if err != nil {
+		return err
+	}
+
+	c.Pinned = pinned
+	return nil
+}
+
+// Report records the user's report of the comment. Each user can report a
+// comment once.
+func (c Comment) Report(userID int64, reason string) error {
+	query := `
+	INSERT OR IGNORE INTO comment_reports (comment_id, user_id, reason, created_at)
+	VALUES (?, ?, ?, ?)`
+
+	result, err := db.DB.Exec(query, c.ID, userID, reason, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrAlreadyReported
+	}
+	return nil
+}
+
+// Moderate resolves the comment's open reports, hiding the comment when the
+// resolution is ResolutionHidden.
+func (c *Comment) Moderate(moderatorID int64, resolution string) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if resolution == ResolutionHidden {
+		_, err = tx.Exec("UPDATE comments SET hidden = 1 WHERE id = ?", c.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+	UPDATE comment_reports
+	SET resolution = ?, resolved_by = ?, resolved_at = ?
+	WHERE comment_id = ? AND resolved_at IS NULL`, resolution, moderatorID, time.Now().UTC(), c.ID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	c.Hidden = c.Hidden || resolution == ResolutionHidden
+	return nil
+}
+
+// GetModerationQueue returns reported comments with open reports, the
+// comment reported first at the front.
+func GetModerationQueue() ([]ModerationItem, error) {
+	query := `
+	SELECT id, comment_id, user_id, reason, created_at, resolution, resolved_by, resolved_at
+	FROM comment_reports
+	WHERE resolved_at IS NULL
+	ORDER BY id`
+
+	rows, err := db.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var order []int64
+	reports := map[int64][]CommentReport{}
+	for rows.Next() {
+		var r CommentReport
+		err := rows.Scan(&r.ID, &r.CommentID, &r.UserID, &r.Reason, &r.CreatedAt, &r.Resolution, &r.ResolvedBy, &r.ResolvedAt)
+
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		if _, seen := reports[r.CommentID]; !seen {
+			order = append(order, r.CommentID)
+		}
+		reports[r.CommentID] = append(reports[r.CommentID], r)
+	}
+	rows.Close()
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	queue := []ModerationItem{}
+	for _, commentID := range order {
+		comment, err := GetCommentByID(commentID)
+		if err != nil {
+			return nil, err
+		}
+
+		queue = append(queue, ModerationItem{Comment: *comment, Reports: reports[commentID]})
+	}
+	return queue, nil
+}
@@ -1,25 +1,96 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
+	"event-planner/bus"
 	"event-planner/db"
+	"event-planner/geo"
 	"time"
 )
 
+var (
+	ErrEventFull         = errors.New("event is full")
+	ErrAlreadyRegistered = errors.New("already registered for this event")
+	ErrNotRegistered     = errors.New("not registered for this event")
+)
+
 type Event struct {
-	ID          int64
-	Name        string    `binding:"required"`
-	Description string    `binding:"required"`
-	Location    string    `binding:"required"`
-	DateTime    time.Time `binding:"required"`
-	UserID      int64
+	ID             int64
+	Name           string    `binding:"required"`
+	Description    string    `binding:"required"`
+	Location       string    `binding:"required"`
+	DateTime       time.Time `binding:"required"`
+	UserID         int64
+	OrganizationID *int64
+	// Capacity is the number of seats; 0 means unlimited.
+	Capacity int
+	// EndTime is optional; see Ends.
+	EndTime *time.Time
+	// GuestLimit is how many guests one registration may bring; 0 allows none.
+	GuestLimit int
+	// CategoryID is optional; see Category.
+	CategoryID *int64
+	// Tags are free-form and normalized by NormalizeTags.
+	Tags []string
+	// Recurrence is optional and only expanded by calendars; registrations
+	// are for the whole series.
+	Recurrence *Recurrence
+	// Latitude and Longitude are optional, in decimal degrees, and set
+	// together.
+	Latitude  *float64
+	Longitude *float64
+}
+
+// DefaultEventDuration is assumed for events saved without an end time.
+const DefaultEventDuration = 2 * time.Hour
+
+// Seats describes how full an event is. Registered counts guests and pending
+// group invitations, since both hold a seat. Available is nil for events
+// without a capacity limit.
+type Seats struct {
+	EventID    int64
+	Capacity   int
+	Registered int
+	Available  *int
 }
 
 var events = []Event{}
 
+// eventColumns is the column list every event query selects, in the order
+// scanEvent reads them.
+const eventColumns = "id, name, description, location, dateTime, userID, organization_id, capacity, end_time, guest_limit, category_id, recurrence, latitude, longitude"
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEvent(row scanner) (*Event, error) {
+	var event Event
+	var recurrence *string
+	err := row.Scan(&event.ID, &event.Name, &event.Description, &event.Location, &event.DateTime, &event.UserID, &event.OrganizationID, &event.Capacity, &event.EndTime, &event.GuestLimit, &event.CategoryID, &recurrence, &event.Latitude, &event.Longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	if recurrence != nil {
+		err = json.Unmarshal([]byte(*recurrence), &event.Recurrence)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &event, nil
+}
+
 func (e *Event) Save() error {
 	query := `
-	INSERT INTO events (name, description, location, dateTime, userID)
-	VALUES (?, ?, ?, ?, ?)`
+	INSERT INTO events (name, description, location, dateTime, userID, organization_id, capacity, end_time, guest_limit, category_id, recurrence, latitude, longitude)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	recurrence, err := e.recurrenceJSON()
+	if err != nil {
+		return err
+	}
 
 	stmt, err := db.DB.Prepare(query)
 	if err != nil {
@@ -27,57 +98,101 @@ func (e *Event) Save() error {
 	}
 
 	defer stmt.Close()
-	result, err := stmt.Exec(e.Name, e.Description, e.Location, e.DateTime, e.UserID)
+	result, err := stmt.Exec(e.Name, e.Description, e.Location, e.DateTime, e.UserID, e.OrganizationID, e.Capacity, e.EndTime, e.GuestLimit, e.CategoryID, recurrence, e.Latitude, e.Longitude)
 	if err != nil {
 		return err
 	}
 
 	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
 	e.ID = id
-	return err
+	err = e.saveTags()
+	if err != nil {
+		return err
+	}
+	bus.Default.Publish(bus.EventCreated, e.ID, *e)
+	return nil
 }
 
-func GetAllEvents() ([]Event, error) {
-	query := "SELECT * FROM events"
-	rows, err := db.DB.Query(query)
-	if err != nil {
-		return nil, err
+// Validate checks the fields binding cannot.
+func (e Event) Validate() error {
+	if e.EndTime != nil && !e.EndTime.After(e.DateTime) {
+		return errors.New("end time must be after the start time")
 	}
-	defer rows.Close()
+	if e.Capacity < 0 || e.GuestLimit < 0 {
+		return errors.New("capacity and guest limit cannot be negative")
+	}
+	if (e.Latitude == nil) != (e.Longitude == nil) {
+		return errors.New("latitude and longitude must be given together")
+	}
+	if e.Latitude != nil && !(geo.Point{Latitude: *e.Latitude, Longitude: *e.Longitude}).Valid() {
+		return errors.New("latitude must be between -90 and 90 and longitude between -180 and 180")
+	}
+	if e.Recurrence != nil {
+		return e.Recurrence.Validate(e.DateTime)
+	}
+	return nil
+}
 
-	var events []Event
+// Point returns the event's coordinates, or false when it has none.
+func (e Event) Point() (geo.Point, bool) {
+	if e.Latitude == nil || e.Longitude == nil {
+		return geo.Point{}, false
+	}
+	return geo.Point{Latitude: *e.Latitude, Longitude: *e.Longitude}, true
+}
 
-	for rows.Next() {
-		var event Event
-		err := rows.Scan(&event.ID, &event.Name, &event.Description, &event.Location, &event.DateTime, &event.UserID)
+// recurrenceJSON returns the recurrence as stored, nil for one-off events.
+func (e Event) recurrenceJSON() (*string, error) {
+	if e.Recurrence == nil {
+		return nil, nil
+	}
 
-		if err != nil {
-			return nil, err
-		}
+	data, err := json.Marshal(e.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	recurrence := string(data)
+	return &recurrence, nil
+}
 
-		events = append(events, event)
+// Ends returns when the event finishes, assuming DefaultEventDuration when no
+// end time was given.
+func (e Event) Ends() time.Time {
+	if e.EndTime != nil {
+		return *e.EndTime
 	}
-	return events, nil
+	return e.DateTime.Add(DefaultEventDuration)
 }
 
 func GetEventByID(id int64) (*Event, error) {
-	query := "SELECT * FROM events WHERE id = ?"
+	query := "SELECT " + eventColumns + " FROM events WHERE id = ?"
 	row := db.DB.QueryRow(query, id)
 
-	var event Event
-	err := row.Scan(&event.ID, &event.Name, &event.Description, &event.Location, &event.DateTime, &event.UserID)
+	event, err := scanEvent(row)
 	if err != nil {
 		return nil, err
 	}
-	return &event, nil
+
+	events := []Event{*event}
+	err = loadTags(events)
+	return &events[0], err
 }
 
 func (event Event) Update() error {
 	query := `
 	UPDATE events
-	SET name = ?, description = ?, location = ?, dateTime = ?
+	SET name = ?, description = ?, location = ?, dateTime = ?, capacity = ?, end_time = ?, guest_limit = ?, category_id = ?, recurrence = ?, latitude = ?, longitude = ?
 	WHERE id = ?`
 
+	recurrence, err := event.recurrenceJSON()
+	if err != nil {
+		return err
+	}
+
 	stmt, err := db.DB.Prepare(query)
 
 	if err != nil {
@@ -86,8 +201,18 @@ func (event Event) Update() error {
 
 	defer stmt.Close()
 
-	_, err = stmt.Exec(event.Name, event.Description, event.Location, event.DateTime, event.ID)
-	return err
+	_, err = stmt.Exec(event.Name, event.Description, event.Location, event.DateTime, event.Capacity, event.EndTime, event.GuestLimit, event.CategoryID, recurrence, event.Latitude, event.Longitude, event.ID)
+	if err != nil {
+		return err
+	}
+
+	err = event.saveTags()
+	if err != nil {
+		return err
+	}
+
+	bus.Default.Publish(bus.EventUpdated, event.ID, event)
+	return event.publishSeats()
 }
 
 func (event Event) Delete() error {
@@ -99,25 +224,66 @@ func (event Event) Delete() error {
 
 	defer stmt.Close()
 	_, err = stmt.Exec(event.ID)
-	return err
+	if err != nil {
+		return err
+	}
+
+	_, err = db.DB.Exec("DELETE FROM event_tags WHERE event_id = ?", event.ID)
+	if err != nil {
+		return err
+	}
+
+	bus.Default.Publish(bus.EventDeleted, event.ID, event)
+	return event.deleteReminders()
+}
+
+// Register adds the user to the event without guests. See RegisterWithGuests.
+func (e Event) Register(userID int64, answers RegistrationAnswers) error {
+	return e.RegisterWithGuests(userID, 0, answers)
+}
+
+// IsRegistered reports whether the user holds a confirmed registration.
+// Pending group invitations do not count.
+func (e Event) IsRegistered(userID int64) (bool, error) {
+	var count int
+	err := db.DB.QueryRow("SELECT COUNT(*) FROM registrations WHERE event_id = ? AND user_id = ? AND status = ?", e.ID, userID, RegistrationConfirmed).Scan(&count)
+	return count > 0, err
 }
 
-func (e Event) Register(userID int64) error {
+// Seats returns the event's current seat availability.
+func (e Event) Seats() (Seats, error) {
 	query := `
-	INSERT INTO registrations (event_id, user_id)
-	VALUES (?, ?)`
-	stmt, err := db.DB.Prepare(query)
+	SELECT e.capacity, COALESCE(SUM(1 + r.guests), 0)
+	FROM events e
+	LEFT JOIN registrations r ON r.event_id = e.id
+	WHERE e.id = ?
+	GROUP BY e.id`
 
+	seats := Seats{EventID: e.ID}
+	err := db.DB.QueryRow(query, e.ID).Scan(&seats.Capacity, &seats.Registered)
 	if err != nil {
-		return err
+		return seats, err
 	}
 
-	defer stmt.Close()
+	if seats.Capacity > 0 {
+		available := max(seats.Capacity-seats.Registered, 0)
+		seats.Available = &available
+	}
+	return seats, nil
+}
 
-	_, err = stmt.Exec(e.ID, userID)
-	return err
+func (e Event) publishSeats() error {
+	seats, err := e.Seats()
+	if err != nil {
+		return err
+	}
+
+	bus.Default.Publish(bus.SeatsUpdated, e.ID, seats)
+	return nil
 }
 
+// CancelRegistration removes the user's registration. Invitations still
+// pending in groups they lead are withdrawn too, so their seats are freed.
 func (e Event) CancelRegistration(userID int64) error {
 	query := `
 	DELETE FROM registrations
@@ -132,5 +298,130 @@ func (e Event) CancelRegistration(userID int64) error {
 	defer stmt.Close()
 
 	_, err = stmt.Exec(e.ID, userID)
-	return err
+	if err != nil {
+		return err
+	}
+
+	query = `
+	DELETE FROM registrations
+	WHERE event_id = ? AND status = ?
+		AND group_id IN (SELECT id FROM registration_groups WHERE event_id = ? AND leader_id = ?)`
+
+	_, err = db.DB.Exec(query, e.ID, RegistrationInvited, e.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	err = e.publishSeats()
+	if err != nil {
+		return err
+	}
+
+	return cancelReminders(e.ID, userID)
+}
+
+// RegistrantIDs returns the IDs of every user with a confirmed registration.
+func (e Event) RegistrantIDs() ([]int64, error) {
+	rows, err := db.DB.Query("SELECT DISTINCT user_id FROM registrations WHERE event_id = ? AND status = ?", e.ID, RegistrationConfirmed)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+
+	for rows.Next() {
+		var userID int64
+		err := rows.Scan(&userID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+// Registrants returns the ID and email of every user with a confirmed registration.
+func (e Event) Registrants() ([]User, error) {
+	query := `
+	SELECT DISTINCT u.id, u.email
+	FROM registrations r
+	JOIN users u ON u.id = r.user_id
+	WHERE r.event_id = ? AND r.status = ?`
+
+	rows, err := db.DB.Query(query, e.ID, RegistrationConfirmed)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []User
+
+	for rows.Next() {
+		var user User
+		err := rows.Scan(&user.ID, &user.Email)
+
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// CheckIn marks the registered user as present at the event. Checking in
+// twice keeps the first time.
+func (e Event) CheckIn(userID int64, at time.Time) error {
+	result, err := db.DB.Exec("UPDATE registrations SET checked_in_at = COALESCE(checked_in_at, ?) WHERE event_id = ? AND user_id = ? AND status = ?", at.UTC(), e.ID, userID, RegistrationConfirmed)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNotRegistered
+	}
+	return nil
+}
+
+func (e Event) IsCheckedIn(userID int64) (bool, error) {
+	var count int
+	err := db.DB.QueryRow("SELECT COUNT(*) FROM registrations WHERE event_id = ? AND user_id = ? AND checked_in_at IS NOT NULL", e.ID, userID).Scan(&count)
+	return count > 0, err
+}
+
+// CheckedInAttendees returns the ID and email of every user checked in to the event.
+func (e Event) CheckedInAttendees() ([]User, error) {
+	query := `
+	SELECT DISTINCT u.id, u.email
+	FROM registrations r
+	JOIN users u ON u.id = r.user_id
+	WHERE r.event_id = ? AND r.checked_in_at IS NOT NULL`
+
+	rows, err := db.DB.Query(query, e.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []User
+
+	for rows.Next() {
+		var user User
+		err := rows.Scan(&user.ID, &user.Email)
+
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+	return users, rows.Err()
 }
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"event-planner/db"
+	"time"
+)
+
+// Kinds of feed items.
+const (
+	FeedNewEvent       = "event.new"
+	FeedEventChanged   = "event.changed"
+	FeedEventCancelled = "event.cancelled"
+	FeedReminder       = "event.reminder"
+)
+
+// FeedItem is one entry of a user's activity feed. The event's name is kept
+// so items about cancelled events still read well.
+type FeedItem struct {
+	ID        int64
+	Kind      string
+	EventID   int64
+	EventName string
+	Message   string
+	CreatedAt time.Time
+}
+
+// AddFeedItem adds a copy of item to the feed of every given user.
+func AddFeedItem(userIDs []int64, item FeedItem) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+	INSERT INTO feed_items (user_id, kind, event_id, event_name, message, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	createdAt := time.Now().UTC()
+	for _, userID := range userIDs {
+		_, err = stmt.Exec(userID, item.Kind, item.EventID, item.EventName, item.Message, createdAt)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetFeed returns up to limit of the user's feed items, newest first,
+// starting after the item with ID before when it is not 0.
+func GetFeed(userID, before int64, limit int) ([]FeedItem, error) {
+	query := `
+	SELECT id, kind, event_id, event_name, message, created_at
+	FROM feed_items
+	WHERE user_id = ? AND (? = 0 OR id < ?)
+	ORDER BY id DESC
+	LIMIT ?`
+
+	rows, err := db.DB.Query(query, userID, before, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []FeedItem{}
+	for rows.Next() {
+		var item FeedItem
+		err = rows.Scan(&item.ID, &item.Kind, &item.EventID, &item.EventName, &item.Message, &item.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// FeedReminderDue is an event starting soon that a user bookmarked or
+// registered for and has not had a feed reminder about.
+type FeedReminderDue struct {
+	UserID int64
+	Event  Event
+}
+
+// GetFeedRemindersDue returns the feed reminders to add for events starting
+// between now and now + lead.
+func GetFeedRemindersDue(now time.Time, lead time.Duration) ([]FeedReminderDue, error) {
+	query := `
+	SELECT interested.user_id, e.id
+	FROM (
+		SELECT user_id, event_id FROM bookmarks
+		UNION
+		SELECT user_id, event_id FROM registrations WHERE status = ?
+	) interested
+	JOIN events e ON e.id = interested.event_id
+	WHERE julianday(e.dateTime) > julianday(?) AND julianday(e.dateTime) <= julianday(?)
+		AND NOT EXISTS (
+			SELECT 1 FROM feed_items f
+			WHERE f.user_id = interested.user_id AND f.event_id = e.id AND f.kind = ?
+		)
+	ORDER BY e.id, interested.user_id`
+
+	rows, err := db.DB.Query(query, RegistrationConfirmed, now.UTC(), now.Add(lead).UTC(), FeedReminder)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := []FeedReminderDue{}
+	for rows.Next() {
+		var reminder FeedReminderDue
+		err = rows.Scan(&reminder.UserID, &reminder.Event.ID)
+		if err != nil {
+			return nil, err
+		}
+		due = append(due, reminder)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	events := map[int64]*Event{}
+	for i := range due {
+		event := events[due[i].Event.ID]
+		if event == nil {
+			event, err = GetEventByID(due[i].Event.ID)
+			if err != nil {
+				return nil, err
+			}
+			events[event.ID] = event
+		}
+		due[i].Event = *event
+	}
+	return due, nil
+}
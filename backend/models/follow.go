@@ -0,0 +1,102 @@
+package models
+
+import (
+	"event-planner/db"
+	"time"
+)
+
+// Kinds of things users follow.
+const (
+	FollowOrganization = "organization"
+	FollowOrganizer    = "organizer"
+)
+
+// Follow records that a user wants to hear about new events from an
+// organization or an organizer. Name is the organization's name or the
+// organizer's email.
+type Follow struct {
+	Kind      string
+	TargetID  int64
+	Name      string
+	CreatedAt time.Time
+}
+
+// AddFollow follows the target; following twice is not an error.
+func AddFollow(userID int64, kind string, targetID int64) error {
+	query := `
+	INSERT INTO follows (user_id, kind, target_id, created_at)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT(user_id, kind, target_id) DO NOTHING`
+
+	_, err := db.DB.Exec(query, userID, kind, targetID, time.Now().UTC())
+	return err
+}
+
+func RemoveFollow(userID int64, kind string, targetID int64) error {
+	_, err := db.DB.Exec("DELETE FROM follows WHERE user_id = ? AND kind = ? AND target_id = ?", userID, kind, targetID)
+	return err
+}
+
+// GetFollows returns what the user follows, most recent first, with the
+// name of each target.
+func GetFollows(userID int64) ([]Follow, error) {
+	query := `
+	SELECT f.kind, f.target_id, COALESCE(o.name, u.email, ''), f.created_at
+	FROM follows f
+	LEFT JOIN organizations o ON f.kind = 'organization' AND o.id = f.target_id
+	LEFT JOIN users u ON f.kind = 'organizer' AND u.id = f.target_id
+	WHERE f.user_id = ?
+	ORDER BY f.created_at DESC, f.target_id`
+
+	rows, err := db.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	follows := []Follow{}
+	for rows.Next() {
+		var follow Follow
+		err = rows.Scan(&follow.Kind, &follow.TargetID, &follow.Name, &follow.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		follows = append(follows, follow)
+	}
+	return follows, rows.Err()
+}
+
+// Followers returns the users following the event's organizer or its
+// organization, other than the organizer.
+func (e Event) Followers() ([]int64, error) {
+	query := `
+	SELECT DISTINCT user_id FROM follows
+	WHERE ((kind = ? AND target_id = ?) OR (kind = ? AND target_id = ?)) AND user_id != ?
+	ORDER BY user_id`
+
+	var organizationID int64
+	if e.OrganizationID != nil {
+		organizationID = *e.OrganizationID
+	}
+	return queryIDs(query, FollowOrganizer, e.UserID, FollowOrganization, organizationID, e.UserID)
+}
+
+// queryIDs returns the single integer column of query's rows.
+func queryIDs(query string, args ...any) ([]int64, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"event-planner/db"
+	"strconv"
+	"time"
+)
+
+// EventImage is the picture shown with an event. Its variants are stored
+// under Key, for example Key + "/card.jpg"; each upload gets a new key so
+// cached copies of an old image are never served in its place.
+type EventImage struct {
+	EventID    int64
+	Key        string
+	Width      int
+	Height     int
+	UploadedAt time.Time
+}
+
+// NewEventImage picks a fresh storage key for an upload of the given size.
+func NewEventImage(eventID int64, width, height int) (*EventImage, error) {
+	buf := make([]byte, 8)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EventImage{
+		EventID: eventID,
+		Key:     "events/" + strconv.FormatInt(eventID, 10) + "/" + hex.EncodeToString(buf),
+		Width:   width,
+		Height:  height,
+	}, nil
+}
+
+// VariantKey returns the storage key of one size of the image.
+func (i EventImage) VariantKey(variant string) string {
+	return i.Key + "/" + variant + ".jpg"
+}
+
+func GetEventImage(eventID int64) (*EventImage, error) {
+	image := EventImage{EventID: eventID}
+	err := db.DB.QueryRow("SELECT storage_key, width, height, uploaded_at FROM event_images WHERE event_id = ?", eventID).
+		Scan(&image.Key, &image.Width, &image.Height, &image.UploadedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// Save records the image as the event's picture, replacing any earlier one.
+func (i *EventImage) Save() error {
+	query := `
+	INSERT INTO event_images (event_id, storage_key, width, height, uploaded_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(event_id) DO UPDATE SET
+		storage_key = excluded.storage_key,
+		width = excluded.width,
+		height = excluded.height,
+		uploaded_at = excluded.uploaded_at`
+
+	i.UploadedAt = time.Now().UTC()
+	_, err := db.DB.Exec(query, i.EventID, i.Key, i.Width, i.Height, i.UploadedAt)
+	return err
+}
+
+func (i EventImage) Delete() error {
+	_, err := db.DB.Exec("DELETE FROM event_images WHERE event_id = ? AND storage_key = ?", i.EventID, i.Key)
+	return err
+}
@@ -0,0 +1,531 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"event-planner/db"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Lottery entry outcomes.
+const (
+	LotteryPending = "pending"
+	LotteryWon     = "won"
+	LotteryLost    = "lost"
+)
+
+// maxPriorityGroups caps how many priority tiers a lottery may have.
+const maxPriorityGroups = 10
+
+var (
+	ErrLotteryClosed  = errors.New("the lottery is not open for entries")
+	ErrLotteryDrawn   = errors.New("the lottery has already been drawn")
+	ErrAlreadyEntered = errors.New("already entered in this lottery")
+	ErrNoLotteryEntry = errors.New("no lottery entry for this event")
+)
+
+// PriorityGroup is a tier of entrants drawn before everyone in later tiers,
+// such as students of the hosting department.
+type PriorityGroup struct {
+	Name   string
+	Emails []string
+}
+
+// Lottery replaces first-come registration for an event. Users enter between
+// OpensAt and ClosesAt; afterwards the draw fills the event's capacity in an
+// order derived from Seed, which is kept so anyone can re-check the results.
+// The seed is picked when the lottery is created and only SeedHash, its hex
+// SHA-256, is published until the draw, so it cannot be chosen afterwards.
+type Lottery struct {
+	EventID        int64
+	OpensAt        time.Time `binding:"required"`
+	ClosesAt       time.Time `binding:"required"`
+	PriorityGroups []PriorityGroup
+	Seed           string
+	SeedHash       string
+	DrawnAt        *time.Time
+}
+
+// LotteryEntry is a user's ticket in the draw. Priority is the index of the
+// priority group they were drawn in, and Rank their 1-based place in the draw;
+// both are set by the draw.
+type LotteryEntry struct {
+	ID        int64
+	EventID   int64
+	UserID    int64
+	Email     string
+	Guests    int
+	Answers   RegistrationAnswers
+	CreatedAt time.Time
+	Priority  *int
+	Rank      *int
+	Outcome   string
+}
+
+// LotteryResult is one line of the published draw. It leaves out who the
+// entrant was; entrants recognise themselves by their entry ID.
+type LotteryResult struct {
+	EntryID       int64
+	PriorityGroup string
+	Rank          int
+	Outcome       string
+}
+
+// Validate checks the lottery closes before the event starts and that every
+// priority group is named.
+func (l Lottery) Validate(event Event) error {
+	if !l.ClosesAt.After(l.OpensAt) {
+		return errors.New("the lottery must close after it opens")
+	}
+	if l.ClosesAt.After(event.DateTime) {
+		return errors.New("the lottery must close before the event starts")
+	}
+	if len(l.PriorityGroups) > maxPriorityGroups {
+		return errors.New("a lottery can have at most " + strconv.Itoa(maxPriorityGroups) + " priority groups")
+	}
+	for _, group := range l.PriorityGroups {
+		if strings.TrimSpace(group.Name) == "" {
+			return errors.New("priority groups need a name")
+		}
+	}
+	return nil
+}
+
+// Open reports whether the lottery takes entries at now.
+func (l Lottery) Open(now time.Time) bool {
+	return l.DrawnAt == nil && !now.Before(l.OpensAt) && now.Before(l.ClosesAt)
+}
+
+const lotteryColumns = "event_id, opens_at, closes_at, priority_groups, seed, seed_hash, drawn_at"
+
+func scanLottery(row scanner) (*Lottery, error) {
+	var l Lottery
+	var groups string
+	var seed, seedHash *string
+	err := row.Scan(&l.EventID, &l.OpensAt, &l.ClosesAt, &groups, &seed, &seedHash, &l.DrawnAt)
+	if err != nil {
+		return nil, err
+	}
+	if seed != nil {
+		l.Seed = *seed
+	}
+	if seedHash != nil {
+		l.SeedHash = *seedHash
+	}
+	return &l, json.Unmarshal([]byte(groups), &l.PriorityGroups)
+}
+
+// GetLottery returns the event's lottery, or sql.ErrNoRows if registration
+// is first come, first served.
+func GetLottery(eventID int64) (*Lottery, error) {
+	return scanLottery(db.DB.QueryRow("SELECT "+lotteryColumns+" FROM lotteries WHERE event_id = ?", eventID))
+}
+
+// GetLotteriesDue returns the lotteries that have closed but not been drawn.
+func GetLotteriesDue(now time.Time) ([]Lottery, error) {
+	rows, err := db.DB.Query("SELECT "+lotteryColumns+" FROM lotteries WHERE drawn_at IS NULL AND closes_at <= ?", now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lotteries []Lottery
+
+	for rows.Next() {
+		lottery, err := scanLottery(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		lotteries = append(lotteries, *lottery)
+	}
+	return lotteries, rows.Err()
+}
+
+// Save creates or replaces the lottery settings until it has been drawn. A new
+// lottery commits to its seed here; updates keep the seed already committed
+// to. Seed and SeedHash are set to the stored values.
+func (l *Lottery) Save() error {
+	groups, err := json.Marshal(l.PriorityGroups)
+	if err != nil {
+		return err
+	}
+
+	seed, err := newLotterySeed()
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO lotteries (event_id, opens_at, closes_at, priority_groups, seed, seed_hash)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT(event_id) DO UPDATE SET
+		opens_at = excluded.opens_at,
+		closes_at = excluded.closes_at,
+		priority_groups = excluded.priority_groups,
+		seed = COALESCE(lotteries.seed, excluded.seed),
+		seed_hash = COALESCE(lotteries.seed_hash, excluded.seed_hash)
+	WHERE lotteries.drawn_at IS NULL`
+
+	l.OpensAt = l.OpensAt.UTC()
+	l.ClosesAt = l.ClosesAt.UTC()
+	result, err := db.DB.Exec(query, l.EventID, l.OpensAt, l.ClosesAt, string(groups), seed, lotterySeedHash(seed))
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrLotteryDrawn
+	}
+
+	return db.DB.QueryRow("SELECT seed, seed_hash FROM lotteries WHERE event_id = ?", l.EventID).Scan(&l.Seed, &l.SeedHash)
+}
+
+// Delete switches the event back to first-come registration, discarding any
+// entries. Drawn lotteries are kept as the record of who got a seat.
+func (l Lottery) Delete() error {
+	result, err := db.DB.Exec("DELETE FROM lotteries WHERE event_id = ? AND drawn_at IS NULL", l.EventID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrLotteryDrawn
+	}
+
+	_, err = db.DB.Exec("DELETE FROM lottery_entries WHERE event_id = ?", l.EventID)
+	return err
+}
+
+// Enter adds the user to the draw with the guests and form answers they would
+// register with. Users who already hold a registration cannot enter.
+func (l Lottery) Enter(event Event, userID int64, guests int, answers RegistrationAnswers, now time.Time) (*LotteryEntry, error) {
+	if !l.Open(now) {
+		return nil, ErrLotteryClosed
+	}
+	if guests < 0 || guests > event.GuestLimit {
+		return nil, ErrGuestLimit
+	}
+
+	answers, err := event.validateAnswers(answers)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = GetRegistration(event.ID, userID)
+	if err == nil {
+		return nil, ErrAlreadyRegistered
+	}
+
+	encodedAnswers, err := json.Marshal(answers)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+	INSERT INTO lottery_entries (event_id, user_id, guests, answers, created_at)
+	SELECT ?, ?, ?, ?, ?
+	WHERE NOT EXISTS (SELECT 1 FROM lottery_entries WHERE event_id = ? AND user_id = ?)
+	AND EXISTS (SELECT 1 FROM lotteries WHERE event_id = ? AND drawn_at IS NULL AND closes_at > ?)`
+
+	entry := LotteryEntry{EventID: event.ID, UserID: userID, Guests: guests, Answers: answers, CreatedAt: now.UTC(), Outcome: LotteryPending}
+	result, err := db.DB.Exec(query,
+		entry.EventID, entry.UserID, entry.Guests, string(encodedAnswers), entry.CreatedAt,
+		entry.EventID, entry.UserID,
+		entry.EventID, entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if affected == 0 {
+		_, err = GetLotteryEntry(event.ID, userID)
+		if err == nil {
+			return nil, ErrAlreadyEntered
+		}
+		return nil, ErrLotteryClosed
+	}
+
+	entry.ID, err = result.LastInsertId()
+	return &entry, err
+}
+
+// WithdrawLotteryEntry removes the user's entry while the draw is pending.
+func WithdrawLotteryEntry(eventID, userID int64) error {
+	result, err := db.DB.Exec("DELETE FROM lottery_entries WHERE event_id = ? AND user_id = ? AND outcome = ?", eventID, userID, LotteryPending)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNoLotteryEntry
+	}
+	return nil
+}
+
+const lotteryEntryColumns = `
+	l.id, l.event_id, l.user_id, u.email, l.guests, l.answers, l.created_at, l.priority, l.rank, l.outcome
+	FROM lottery_entries l
+	JOIN users u ON u.id = l.user_id`
+
+func scanLotteryEntry(row scanner) (*LotteryEntry, error) {
+	var entry LotteryEntry
+	var answers string
+	err := row.Scan(&entry.ID, &entry.EventID, &entry.UserID, &entry.Email, &entry.Guests, &answers, &entry.CreatedAt, &entry.Priority, &entry.Rank, &entry.Outcome)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, json.Unmarshal([]byte(answers), &entry.Answers)
+}
+
+func GetLotteryEntry(eventID, userID int64) (*LotteryEntry, error) {
+	return scanLotteryEntry(db.DB.QueryRow("SELECT "+lotteryEntryColumns+" WHERE l.event_id = ? AND l.user_id = ?", eventID, userID))
+}
+
+// Entries returns every entry, in draw order once the lottery is drawn.
+func (l Lottery) Entries() ([]LotteryEntry, error) {
+	rows, err := db.DB.Query("SELECT "+lotteryEntryColumns+" WHERE l.event_id = ? ORDER BY l.rank, l.id", l.EventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []LotteryEntry{}
+
+	for rows.Next() {
+		entry, err := scanLotteryEntry(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+// Results returns the published outcome of the draw.
+func (l Lottery) Results() ([]LotteryResult, error) {
+	entries, err := l.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]LotteryResult, 0, len(entries))
+	for _, entry := range entries {
+		result := LotteryResult{EntryID: entry.ID, Outcome: entry.Outcome}
+		if entry.Rank != nil {
+			result.Rank = *entry.Rank
+		}
+		if entry.Priority != nil && *entry.Priority < len(l.PriorityGroups) {
+			result.PriorityGroup = l.PriorityGroups[*entry.Priority].Name
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func newLotterySeed() (string, error) {
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// lotterySeedHash is the published commitment to a seed: its hex SHA-256.
+func lotterySeedHash(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
+
+// lotteryDrawKey is an entry's place within its priority group: the hex
+// SHA-256 of "<seed>:<entry id>", sorted ascending.
+func lotteryDrawKey(seed string, entryID int64) string {
+	sum := sha256.Sum256([]byte(seed + ":" + strconv.FormatInt(entryID, 10)))
+	return hex.EncodeToString(sum[:])
+}
+
+// priorityOf returns the index of the first group listing the email, or
+// len(groups) for entrants in none.
+func priorityOf(email string, groups []PriorityGroup) int {
+	for i, group := range groups {
+		for _, member := range group.Emails {
+			if strings.EqualFold(strings.TrimSpace(member), email) {
+				return i
+			}
+		}
+	}
+	return len(groups)
+}
+
+// drawOrder sorts the entries by priority group and then by draw key. The
+// order depends only on the seed, the entry IDs and the groups.
+func drawOrder(seed string, entries []LotteryEntry, groups []PriorityGroup) []LotteryEntry {
+	priorities := make(map[int64]int, len(entries))
+	keys := make(map[int64]string, len(entries))
+	for _, entry := range entries {
+		priorities[entry.ID] = priorityOf(entry.Email, groups)
+		keys[entry.ID] = lotteryDrawKey(seed, entry.ID)
+	}
+
+	ordered := append([]LotteryEntry(nil), entries...)
+	sort.Slice(ordered, func(i, j int) bool {
+		a, b := ordered[i].ID, ordered[j].ID
+		if priorities[a] != priorities[b] {
+			return priorities[a] < priorities[b]
+		}
+		return keys[a] < keys[b]
+	})
+
+	for i := range ordered {
+		priority := priorities[ordered[i].ID]
+		if priority < len(groups) {
+			ordered[i].Priority = &priority
+		}
+		rank := i + 1
+		ordered[i].Rank = &rank
+	}
+	return ordered
+}
+
+// Draw registers entrants in the order given by the committed seed until the
+// event is full; entrants whose party no longer fits lose. It reports false
+// if the lottery was still open or already drawn, so concurrent runs draw
+// once.
+func (l *Lottery) Draw(event Event, now time.Time) (bool, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	drawnAt := now.UTC()
+	result, err := tx.Exec("UPDATE lotteries SET drawn_at = ? WHERE event_id = ? AND drawn_at IS NULL AND closes_at <= ?",
+		drawnAt, l.EventID, drawnAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if affected == 0 {
+		return false, nil
+	}
+
+	var seed, seedHash sql.NullString
+	err = tx.QueryRow("SELECT seed, seed_hash FROM lotteries WHERE event_id = ?", l.EventID).Scan(&seed, &seedHash)
+	if err != nil {
+		return false, err
+	}
+
+	// Lotteries saved before seeds were committed to get one now
+	if !seed.Valid {
+		seed.String, err = newLotterySeed()
+		if err != nil {
+			return false, err
+		}
+		seedHash.String = lotterySeedHash(seed.String)
+
+		_, err = tx.Exec("UPDATE lotteries SET seed = ?, seed_hash = ? WHERE event_id = ?", seed.String, seedHash.String, l.EventID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	rows, err := tx.Query("SELECT "+lotteryEntryColumns+" WHERE l.event_id = ?", l.EventID)
+	if err != nil {
+		return false, err
+	}
+
+	var entries []LotteryEntry
+	for rows.Next() {
+		entry, err := scanLotteryEntry(rows)
+		if err != nil {
+			rows.Close()
+			return false, err
+		}
+		entries = append(entries, *entry)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return false, rows.Err()
+	}
+
+	var winners []int64
+	for _, entry := range drawOrder(seed.String, entries, l.PriorityGroups) {
+		registration := Registration{UserID: entry.UserID, Status: RegistrationConfirmed, Guests: entry.Guests, Answers: entry.Answers}
+		err = event.insertRegistration(tx, &registration)
+
+		outcome := LotteryWon
+		switch {
+		case errors.Is(err, ErrEventFull):
+			outcome = LotteryLost
+		case errors.Is(err, ErrAlreadyRegistered):
+			// They took a seat another way, such as a group invitation
+		case err != nil:
+			return false, err
+		default:
+			winners = append(winners, entry.UserID)
+		}
+
+		_, err = tx.Exec("UPDATE lottery_entries SET priority = ?, rank = ?, outcome = ? WHERE id = ?",
+			entry.Priority, entry.Rank, outcome, entry.ID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	l.Seed = seed.String
+	l.SeedHash = seedHash.String
+	l.DrawnAt = &drawnAt
+
+	err = event.publishSeats()
+	if err != nil {
+		return true, err
+	}
+
+	for _, userID := range winners {
+		err = ScheduleReminders(event, userID)
+		if err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
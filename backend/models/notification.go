@@ -0,0 +1,113 @@
+package models
+
+import (
+	"event-planner/db"
+	"time"
+)
+
+// Notification is an in-app message shown to a single user.
+type Notification struct {
+	ID        int64
+	UserID    int64
+	Type      string
+	Title     string
+	Body      string
+	CreatedAt time.Time
+	ReadAt    *time.Time
+}
+
+func (n *Notification) Save() error {
+	query := `
+	INSERT INTO notifications (user_id, type, title, body, created_at)
+	VALUES (?, ?, ?, ?, ?)`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now().UTC()
+	}
+
+	result, err := stmt.Exec(n.UserID, n.Type, n.Title, n.Body, n.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	n.ID = id
+	return err
+}
+
+// GetNotificationsForUser returns a page of the user's notifications, newest first.
+func GetNotificationsForUser(userID int64, limit, offset int) ([]Notification, error) {
+	query := `
+	SELECT id, user_id, type, title, body, created_at, read_at
+	FROM notifications
+	WHERE user_id = ?
+	ORDER BY created_at DESC, id DESC
+	LIMIT ? OFFSET ?`
+
+	rows, err := db.DB.Query(query, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+
+	for rows.Next() {
+		var n Notification
+		err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &n.CreatedAt, &n.ReadAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+// CountNotifications returns how many notifications the user has in total and
+// how many of them are unread.
+func CountNotifications(userID int64) (total int, unread int, err error) {
+	query := `
+	SELECT COUNT(*), COUNT(*) - COUNT(read_at)
+	FROM notifications
+	WHERE user_id = ?`
+
+	err = db.DB.QueryRow(query, userID).Scan(&total, &unread)
+	return total, unread, err
+}
+
+// MarkNotificationRead marks one of the user's notifications as read. It
+// reports false when the notification does not exist or belongs to someone else.
+func MarkNotificationRead(id, userID int64) (bool, error) {
+	query := `
+	UPDATE notifications
+	SET read_at = COALESCE(read_at, ?)
+	WHERE id = ? AND user_id = ?`
+
+	result, err := db.DB.Exec(query, time.Now().UTC(), id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// MarkAllNotificationsRead marks every unread notification of the user as read.
+func MarkAllNotificationsRead(userID int64) error {
+	query := `
+	UPDATE notifications
+	SET read_at = ?
+	WHERE user_id = ? AND read_at IS NULL`
+
+	_, err := db.DB.Exec(query, time.Now().UTC(), userID)
+	return err
+}
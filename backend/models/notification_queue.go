@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/json"
+	"event-planner/db"
+	"time"
+)
+
+// QueuedAttachment is a file sent along with a queued message, such as a
+// calendar entry.
+type QueuedAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// QueuedNotification is a message held back for a digest or until the
+// recipient's quiet hours end.
+type QueuedNotification struct {
+	ID           int64
+	UserID       int64
+	Channel      string
+	Type         string
+	Subject      string
+	Body         string
+	Attachments  []QueuedAttachment
+	Digest       bool
+	DeliverAfter time.Time
+}
+
+func (q *QueuedNotification) Save() error {
+	attachments, err := json.Marshal(q.Attachments)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO notification_queue (user_id, channel, type, subject, body, attachments, digest, deliver_after)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	result, err := stmt.Exec(q.UserID, q.Channel, q.Type, q.Subject, q.Body, string(attachments), q.Digest, q.DeliverAfter.UTC())
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	q.ID = id
+	return err
+}
+
+// GetDueQueuedNotifications returns queued messages ready for delivery, grouped
+// by user and channel so digests can be assembled in one pass.
+func GetDueQueuedNotifications(now time.Time) ([]QueuedNotification, error) {
+	query := `
+	SELECT id, user_id, channel, type, subject, body, attachments, digest, deliver_after
+	FROM notification_queue
+	WHERE deliver_after <= ?
+	ORDER BY user_id, channel, digest, id`
+
+	rows, err := db.DB.Query(query, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var queued []QueuedNotification
+
+	for rows.Next() {
+		var q QueuedNotification
+		var attachments string
+		err := rows.Scan(&q.ID, &q.UserID, &q.Channel, &q.Type, &q.Subject, &q.Body, &attachments, &q.Digest, &q.DeliverAfter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(attachments), &q.Attachments)
+		if err != nil {
+			return nil, err
+		}
+
+		queued = append(queued, q)
+	}
+	return queued, rows.Err()
+}
+
+// Claim removes the message from the queue. It reports false when another run
+// already took it, so queued messages are delivered at most once.
+func (q QueuedNotification) Claim() (bool, error) {
+	result, err := db.DB.Exec("DELETE FROM notification_queue WHERE id = ?", q.ID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"event-planner/db"
+	"time"
+)
+
+// Organization is a club or department that runs events. Its owner manages
+// its settings, such as webhooks.
+type Organization struct {
+	ID          int64
+	Name        string `binding:"required"`
+	Description string
+	OwnerID     int64
+	CreatedAt   time.Time
+}
+
+func (o *Organization) Save() error {
+	query := `
+	INSERT INTO organizations (name, description, owner_id, created_at)
+	VALUES (?, ?, ?, ?)`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	o.CreatedAt = time.Now().UTC()
+	result, err := stmt.Exec(o.Name, o.Description, o.OwnerID, o.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	o.ID = id
+	return err
+}
+
+func GetAllOrganizations() ([]Organization, error) {
+	query := "SELECT id, name, description, owner_id, created_at FROM organizations ORDER BY name"
+	rows, err := db.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	organizations := []Organization{}
+
+	for rows.Next() {
+		var o Organization
+		err := rows.Scan(&o.ID, &o.Name, &o.Description, &o.OwnerID, &o.CreatedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		organizations = append(organizations, o)
+	}
+	return organizations, rows.Err()
+}
+
+func GetOrganizationByID(id int64) (*Organization, error) {
+	query := "SELECT id, name, description, owner_id, created_at FROM organizations WHERE id = ?"
+	row := db.DB.QueryRow(query, id)
+
+	var o Organization
+	err := row.Scan(&o.ID, &o.Name, &o.Description, &o.OwnerID, &o.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
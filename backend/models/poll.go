@@ -0,0 +1,171 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"event-planner/db"
+	"time"
+)
+
+var (
+	ErrAlreadyVoted = errors.New("already voted in this poll")
+	ErrPollClosed   = errors.New("poll is closed")
+	ErrInvalidVote  = errors.New("no such poll option")
+)
+
+// Poll is an audience poll run during an event. Tallies holds the number of
+// votes for each option, in the same order as Options.
+type Poll struct {
+	ID        int64
+	EventID   int64
+	Question  string
+	Options   []string
+	Closed    bool
+	CreatedBy int64
+	CreatedAt time.Time
+	Tallies   []int
+}
+
+func (p *Poll) Save() error {
+	query := `
+	INSERT INTO polls (event_id, question, options, created_by, created_at)
+	VALUES (?, ?, ?, ?, ?)`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	options, err := json.Marshal(p.Options)
+	if err != nil {
+		return err
+	}
+
+	p.CreatedAt = time.Now().UTC()
+	result, err := stmt.Exec(p.EventID, p.Question, string(options), p.CreatedBy, p.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	p.ID = id
+	p.Tallies = make([]int, len(p.Options))
+	return err
+}
+
+func GetPollByID(id int64) (*Poll, error) {
+	polls, err := queryPolls("SELECT id, event_id, question, options, closed, created_by, created_at FROM polls WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(polls) == 0 {
+		return nil, errors.New("poll not found")
+	}
+	return &polls[0], nil
+}
+
+// GetPollsForEvent returns the event's polls with their current tallies.
+func GetPollsForEvent(eventID int64) ([]Poll, error) {
+	return queryPolls("SELECT id, event_id, question, options, closed, created_by, created_at FROM polls WHERE event_id = ? ORDER BY id", eventID)
+}
+
+func queryPolls(query string, args ...any) ([]Poll, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	polls := []Poll{}
+
+	for rows.Next() {
+		var p Poll
+		var options string
+		err := rows.Scan(&p.ID, &p.EventID, &p.Question, &options, &p.Closed, &p.CreatedBy, &p.CreatedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(options), &p.Options)
+		if err != nil {
+			return nil, err
+		}
+
+		polls = append(polls, p)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range polls {
+		err = polls[i].loadTallies()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return polls, nil
+}
+
+func (p *Poll) loadTallies() error {
+	rows, err := db.DB.Query("SELECT option_index, COUNT(*) FROM poll_votes WHERE poll_id = ? GROUP BY option_index", p.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	p.Tallies = make([]int, len(p.Options))
+	for rows.Next() {
+		var option, count int
+		err := rows.Scan(&option, &count)
+
+		if err != nil {
+			return err
+		}
+
+		if option >= 0 && option < len(p.Tallies) {
+			p.Tallies[option] = count
+		}
+	}
+	return rows.Err()
+}
+
+// Vote records the user's single vote and refreshes the tallies.
+func (p *Poll) Vote(userID int64, option int) error {
+	if p.Closed {
+		return ErrPollClosed
+	}
+	if option < 0 || option >= len(p.Options) {
+		return ErrInvalidVote
+	}
+
+	result, err := db.DB.Exec("INSERT OR IGNORE INTO poll_votes (poll_id, user_id, option_index) VALUES (?, ?, ?)", p.ID, userID, option)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrAlreadyVoted
+	}
+	return p.loadTallies()
+}
+
+func (p *Poll) Close() error {
+	_, err := db.DB.Exec("UPDATE polls SET closed = 1 WHERE id = ?", p.ID)
+	if err != nil {
+		return err
+	}
+
+	p.Closed = true
+	return nil
+}
@@ -0,0 +1,218 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"event-planner/db"
+	"event-planner/utils"
+	"fmt"
+	"time"
+)
+
+// Delivery frequencies for a notification preference.
+const (
+	FrequencyImmediate = "immediate"
+	FrequencyDigest    = "digest"
+)
+
+// NotificationPreference controls whether and how often one type of
+// notification is delivered on one channel.
+type NotificationPreference struct {
+	Type      string `binding:"required"`
+	Channel   string `binding:"required"`
+	Enabled   bool
+	Frequency string
+}
+
+// NotificationSettings holds the per-user delivery details shared by every
+// notification type. Quiet hours are "HH:MM" in the user's time zone; empty
+// values disable them.
+type NotificationSettings struct {
+	UserID          int64
+	Email           string
+	TimeZone        string
+	QuietHoursStart string
+	QuietHoursEnd   string
+	Phone           string
+	WebhookURL      string
+}
+
+func GetNotificationSettings(userID int64) (*NotificationSettings, error) {
+	query := `
+	SELECT u.id, u.email, u.timezone,
+		COALESCE(s.quiet_hours_start, ''), COALESCE(s.quiet_hours_end, ''),
+		COALESCE(s.phone, ''), COALESCE(s.webhook_url, '')
+	FROM users u
+	LEFT JOIN notification_settings s ON s.user_id = u.id
+	WHERE u.id = ?`
+
+	var s NotificationSettings
+	err := db.DB.QueryRow(query, userID).Scan(&s.UserID, &s.Email, &s.TimeZone, &s.QuietHoursStart, &s.QuietHoursEnd, &s.Phone, &s.WebhookURL)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Validate checks the time zone and quiet hours can be interpreted, and that
+// the webhook URL does not point into the internal network.
+func (s NotificationSettings) Validate() error {
+	_, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return fmt.Errorf("unknown time zone %q", s.TimeZone)
+	}
+
+	if (s.QuietHoursStart == "") != (s.QuietHoursEnd == "") {
+		return errors.New("quiet hours need both a start and an end")
+	}
+
+	for _, clock := range []string{s.QuietHoursStart, s.QuietHoursEnd} {
+		if clock == "" {
+			continue
+		}
+		_, err := time.Parse("15:04", clock)
+		if err != nil {
+			return fmt.Errorf("quiet hours must be HH:MM, got %q", clock)
+		}
+	}
+
+	if s.WebhookURL != "" {
+		err = utils.ValidateOutboundURL(s.WebhookURL)
+		if err != nil {
+			return fmt.Errorf("webhook %w", err)
+		}
+	}
+	return nil
+}
+
+func (s NotificationSettings) Save() error {
+	_, err := db.DB.Exec("UPDATE users SET timezone = ? WHERE id = ?", s.TimeZone, s.UserID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO notification_settings (user_id, quiet_hours_start, quiet_hours_end, phone, webhook_url)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (user_id) DO UPDATE SET
+		quiet_hours_start = excluded.quiet_hours_start,
+		quiet_hours_end = excluded.quiet_hours_end,
+		phone = excluded.phone,
+		webhook_url = excluded.webhook_url`
+
+	_, err = db.DB.Exec(query, s.UserID, s.QuietHoursStart, s.QuietHoursEnd, s.Phone, s.WebhookURL)
+	return err
+}
+
+// Location returns the user's time zone, falling back to UTC.
+func (s NotificationSettings) Location() *time.Location {
+	location, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// QuietUntil reports whether now falls in the user's quiet hours and, if so,
+// when they end. Windows that cross midnight, such as 22:00-07:00, are supported.
+func (s NotificationSettings) QuietUntil(now time.Time) (time.Time, bool) {
+	if s.QuietHoursStart == "" || s.QuietHoursEnd == "" {
+		return time.Time{}, false
+	}
+
+	local := now.In(s.Location())
+	start := clockOn(local, s.QuietHoursStart)
+	end := clockOn(local, s.QuietHoursEnd)
+
+	if !start.Before(end) {
+		// Overnight window: either we are after today's start, or before today's end
+		if !local.Before(start) {
+			return end.AddDate(0, 0, 1), true
+		}
+		if local.Before(end) {
+			return end, true
+		}
+		return time.Time{}, false
+	}
+
+	if !local.Before(start) && local.Before(end) {
+		return end, true
+	}
+	return time.Time{}, false
+}
+
+// NextLocalClock returns the first time at or after now that the user's wall
+// clock shows clock ("HH:MM").
+func (s NotificationSettings) NextLocalClock(now time.Time, clock string) time.Time {
+	local := now.In(s.Location())
+	next := clockOn(local, clock)
+	if next.Before(local) {
+		next = clockOn(local.AddDate(0, 0, 1), clock)
+	}
+	return next
+}
+
+func clockOn(day time.Time, clock string) time.Time {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return day
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), parsed.Hour(), parsed.Minute(), 0, 0, day.Location())
+}
+
+// GetNotificationPreferences returns only the preferences the user has saved.
+func GetNotificationPreferences(userID int64) ([]NotificationPreference, error) {
+	query := `
+	SELECT type, channel, enabled, frequency
+	FROM notification_preferences
+	WHERE user_id = ?
+	ORDER BY type, channel`
+
+	rows, err := db.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var preferences []NotificationPreference
+
+	for rows.Next() {
+		var p NotificationPreference
+		err := rows.Scan(&p.Type, &p.Channel, &p.Enabled, &p.Frequency)
+
+		if err != nil {
+			return nil, err
+		}
+
+		preferences = append(preferences, p)
+	}
+	return preferences, rows.Err()
+}
+
+// GetNotificationPreference returns the user's preference for one type and
+// channel, or fallback when none was saved.
+func GetNotificationPreference(userID int64, notificationType, channel string, fallback NotificationPreference) (NotificationPreference, error) {
+	query := `
+	SELECT type, channel, enabled, frequency
+	FROM notification_preferences
+	WHERE user_id = ? AND type = ? AND channel = ?`
+
+	var p NotificationPreference
+	err := db.DB.QueryRow(query, userID, notificationType, channel).Scan(&p.Type, &p.Channel, &p.Enabled, &p.Frequency)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fallback, nil
+	}
+	return p, err
+}
+
+func (p NotificationPreference) Save(userID int64) error {
+	query := `
+	INSERT INTO notification_preferences (user_id, type, channel, enabled, frequency)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (user_id, type, channel) DO UPDATE SET
+		enabled = excluded.enabled,
+		frequency = excluded.frequency`
+
+	_, err := db.DB.Exec(query, userID, p.Type, p.Channel, p.Enabled, p.Frequency)
+	return err
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"errors"
+	"event-planner/db"
+	"time"
+)
+
+var ErrAlreadyUpvoted = errors.New("already upvoted this question")
+
+// Question is an audience question in an event's Q&A queue.
+type Question struct {
+	ID        int64
+	EventID   int64
+	UserID    int64
+	Text      string
+	Answered  bool
+	Upvotes   int
+	CreatedAt time.Time
+}
+
+func (q *Question) Save() error {
+	query := `
+	INSERT INTO questions (event_id, user_id, text, created_at)
+	VALUES (?, ?, ?, ?)`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	q.CreatedAt = time.Now().UTC()
+	result, err := stmt.Exec(q.EventID, q.UserID, q.Text, q.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	q.ID = id
+	return err
+}
+
+const questionQuery = `
+	SELECT q.id, q.event_id, q.user_id, q.text, q.answered, q.created_at, COUNT(v.user_id)
+	FROM questions q
+	LEFT JOIN question_votes v ON v.question_id = q.id`
+
+func GetQuestionByID(id int64) (*Question, error) {
+	questions, err := queryQuestions(questionQuery+" WHERE q.id = ? GROUP BY q.id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(questions) == 0 {
+		return nil, errors.New("question not found")
+	}
+	return &questions[0], nil
+}
+
+// GetQuestionsForEvent returns the Q&A queue: open questions first, most
+// upvoted first, then oldest first.
+func GetQuestionsForEvent(eventID int64) ([]Question, error) {
+	return queryQuestions(questionQuery+`
+	WHERE q.event_id = ?
+	GROUP BY q.id
+	ORDER BY q.answered, COUNT(v.user_id) DESC, q.id`, eventID)
+}
+
+func queryQuestions(query string, args ...any) ([]Question, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	questions := []Question{}
+
+	for rows.Next() {
+		var q Question
+		err := rows.Scan(&q.ID, &q.EventID, &q.UserID, &q.Text, &q.Answered, &q.CreatedAt, &q.Upvotes)
+
+		if err != nil {
+			return nil, err
+		}
+
+		questions = append(questions, q)
+	}
+	return questions, rows.Err()
+}
+
+// Upvote adds the user's single upvote to the question.
+func (q *Question) Upvote(userID int64) error {
+	result, err := db.DB.Exec("INSERT OR IGNORE INTO question_votes (question_id, user_id) VALUES (?, ?)", q.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrAlreadyUpvoted
+	}
+
+	q.Upvotes++
+	return nil
+}
+
+func (q *Question) MarkAnswered() error {
+	_, err := db.DB.Exec("UPDATE questions SET answered = 1 WHERE id = ?", q.ID)
+	if err != nil {
+		return err
+	}
+
+	q.Answered = true
+	return nil
+}
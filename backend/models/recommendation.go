@@ -0,0 +1,200 @@
+package models
+
+import (
+	"encoding/json"
+	"event-planner/db"
+	"time"
+)
+
+// Kinds of recommendation reasons.
+const (
+	ReasonFollow       = "follow"
+	ReasonHistory      = "history"
+	ReasonTags         = "tags"
+	ReasonSimilarUsers = "similar-users"
+)
+
+// RecommendationReason explains one signal behind a recommendation.
+type RecommendationReason struct {
+	Kind    string
+	Message string
+}
+
+// Recommendation is an upcoming event suggested to a user, as computed by
+// the last recommendations run.
+type Recommendation struct {
+	EventID int64
+	Score   float64
+	Reasons []RecommendationReason
+}
+
+// RecommendationInputs is everything a recommendations run looks at.
+type RecommendationInputs struct {
+	// Events holds every event by ID, with its tags.
+	Events map[int64]Event
+	// Registrations lists the events each user holds a confirmed registration for.
+	Registrations map[int64][]int64
+	// Follows lists the organizations each user follows.
+	Follows map[int64][]int64
+	// Categories and Organizations name the ones events refer to.
+	Categories    map[int64]string
+	Organizations map[int64]string
+}
+
+// LoadRecommendationInputs reads the events, registrations, follows and names
+// a recommendations run needs.
+func LoadRecommendationInputs() (*RecommendationInputs, error) {
+	inputs := RecommendationInputs{
+		Events:        map[int64]Event{},
+		Registrations: map[int64][]int64{},
+		Follows:       map[int64][]int64{},
+		Categories:    map[int64]string{},
+		Organizations: map[int64]string{},
+	}
+
+	events, err := FindEvents(EventFilter{})
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		inputs.Events[event.ID] = event
+	}
+
+	err = loadPairs("SELECT user_id, event_id FROM registrations WHERE status = ? ORDER BY user_id, event_id", inputs.Registrations, RegistrationConfirmed)
+	if err != nil {
+		return nil, err
+	}
+	err = loadPairs("SELECT user_id, target_id FROM follows WHERE kind = ? ORDER BY user_id, target_id", inputs.Follows, FollowOrganization)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := GetCategories()
+	if err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		inputs.Categories[category.ID] = category.Name
+	}
+
+	organizations, err := GetAllOrganizations()
+	if err != nil {
+		return nil, err
+	}
+	for _, organization := range organizations {
+		inputs.Organizations[organization.ID] = organization.Name
+	}
+	return &inputs, nil
+}
+
+// loadPairs groups the second column of query's rows by the first.
+func loadPairs(query string, into map[int64][]int64, args ...any) error {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key, value int64
+		err = rows.Scan(&key, &value)
+		if err != nil {
+			return err
+		}
+		into[key] = append(into[key], value)
+	}
+	return rows.Err()
+}
+
+// SaveRecommendations replaces every cached recommendation with the result of
+// a run, so users the run had nothing for lose their stale suggestions.
+func SaveRecommendations(recommendations map[int64][]Recommendation, computedAt time.Time) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM recommendations")
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`
+	INSERT INTO recommendations (user_id, event_id, rank, score, reasons, computed_at)
+	VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for userID, list := range recommendations {
+		for rank, recommendation := range list {
+			reasons, err := json.Marshal(recommendation.Reasons)
+			if err != nil {
+				return err
+			}
+			_, err = stmt.Exec(userID, recommendation.EventID, rank, recommendation.Score, string(reasons), computedAt.UTC())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// RecommendedEvent is a cached recommendation with its event.
+type RecommendedEvent struct {
+	Event      Event
+	Score      float64
+	Reasons    []RecommendationReason
+	ComputedAt time.Time
+}
+
+// GetRecommendations returns up to limit cached recommendations for the user,
+// best first, leaving out events that have started or that the user has
+// registered for since the last run.
+func GetRecommendations(userID int64, limit int, now time.Time) ([]RecommendedEvent, error) {
+	query := `
+	SELECT r.event_id, r.score, r.reasons, r.computed_at
+	FROM recommendations r
+	JOIN events e ON e.id = r.event_id
+	WHERE r.user_id = ? AND julianday(e.dateTime) > julianday(?)
+		AND NOT EXISTS (SELECT 1 FROM registrations WHERE event_id = e.id AND user_id = r.user_id)
+	ORDER BY r.rank
+	LIMIT ?`
+
+	rows, err := db.DB.Query(query, userID, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recommended := []RecommendedEvent{}
+	for rows.Next() {
+		var r RecommendedEvent
+		var reasons string
+		err = rows.Scan(&r.Event.ID, &r.Score, &reasons, &r.ComputedAt)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal([]byte(reasons), &r.Reasons)
+		if err != nil {
+			return nil, err
+		}
+		recommended = append(recommended, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range recommended {
+		event, err := GetEventByID(recommended[i].Event.ID)
+		if err != nil {
+			return nil, err
+		}
+		recommended[i].Event = *event
+	}
+	return recommended, nil
+}
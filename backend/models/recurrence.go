@@ -0,0 +1,174 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Recurrence frequencies.
+const (
+	RecurDaily   = "daily"
+	RecurWeekly  = "weekly"
+	RecurMonthly = "monthly"
+)
+
+// MaxRecurrenceCount caps how many times a series may repeat.
+const MaxRecurrenceCount = 500
+
+// Recurrence repeats an event every Interval days, weeks or months, at the
+// same local time. The series ends after Count occurrences or with the last
+// one starting at or before Until; with neither it goes on indefinitely.
+// Monthly series skip months without the start's day, as calendars do.
+type Recurrence struct {
+	Frequency string `binding:"required"`
+	Interval  int
+	Count     int
+	Until     *time.Time
+}
+
+// Validate defaults Interval to 1 and checks the rule can be expanded.
+func (r *Recurrence) Validate(start time.Time) error {
+	if r.Frequency != RecurDaily && r.Frequency != RecurWeekly && r.Frequency != RecurMonthly {
+		return fmt.Errorf("recurrence frequency must be %q, %q or %q", RecurDaily, RecurWeekly, RecurMonthly)
+	}
+	if r.Interval == 0 {
+		r.Interval = 1
+	}
+	if r.Interval < 1 || r.Interval > 99 {
+		return errors.New("recurrence interval must be between 1 and 99")
+	}
+	if r.Count < 0 || r.Count > MaxRecurrenceCount {
+		return fmt.Errorf("recurrence count must be between 0 and %d", MaxRecurrenceCount)
+	}
+	if r.Until != nil && r.Until.Before(start) {
+		return errors.New("recurrence must end after the event starts")
+	}
+	return nil
+}
+
+// every returns the interval, treating rules saved without one as 1.
+func (r Recurrence) every() int {
+	if r.Interval < 1 {
+		return 1
+	}
+	return r.Interval
+}
+
+// step returns the nth occurrence's start, or false when the month of a
+// monthly series has no such day.
+func (r Recurrence) step(start time.Time, n int) (time.Time, bool) {
+	switch r.Frequency {
+	case RecurDaily:
+		return start.AddDate(0, 0, n*r.every()), true
+	case RecurWeekly:
+		return start.AddDate(0, 0, 7*n*r.every()), true
+	}
+	t := start.AddDate(0, n*r.every(), 0)
+	return t, t.Day() == start.Day()
+}
+
+// firstStep returns an occurrence index at or before the first one that can
+// end after from, so open-ended series need not be walked from the start.
+func (r Recurrence) firstStep(start, from time.Time, duration time.Duration) int {
+	gap := from.Add(-duration).Sub(start)
+	if gap <= 0 {
+		return 0
+	}
+
+	var n int
+	switch r.Frequency {
+	case RecurDaily:
+		n = int(gap.Hours()/24) / r.every()
+	case RecurWeekly:
+		n = int(gap.Hours()/24/7) / r.every()
+	default:
+		n = int(gap.Hours()/24/31) / r.every()
+	}
+	// Leave a step of slack for daylight saving shifts
+	if n > 0 {
+		n--
+	}
+	return n
+}
+
+// Occurrences returns the start of every occurrence of the event overlapping
+// [from, to). Occurrences are computed in loc, so they keep their wall-clock
+// time there across daylight saving changes. Events without a recurrence
+// occur once.
+func (e Event) Occurrences(from, to time.Time, loc *time.Location) []time.Time {
+	duration := e.Ends().Sub(e.DateTime)
+	overlaps := func(t time.Time) bool {
+		return t.Before(to) && t.Add(duration).After(from)
+	}
+
+	if e.Recurrence == nil {
+		if overlaps(e.DateTime) {
+			return []time.Time{e.DateTime}
+		}
+		return nil
+	}
+
+	r := *e.Recurrence
+	start := e.DateTime.In(loc)
+
+	// Series with a count are walked from the start to count occurrences
+	n := 0
+	if r.Count == 0 {
+		n = r.firstStep(start, from, duration)
+	}
+
+	occurrences := []time.Time{}
+	for seen := 0; ; n++ {
+		t, ok := r.step(start, n)
+		if !t.Before(to) || r.Until != nil && t.After(*r.Until) {
+			break
+		}
+		if !ok {
+			continue
+		}
+
+		seen++
+		if r.Count > 0 && seen > r.Count {
+			break
+		}
+		if overlaps(t) {
+			occurrences = append(occurrences, t)
+		}
+	}
+	return occurrences
+}
+
+// NextOccurrence returns the start of the first occurrence of the event
+// starting after after, computed in loc like Occurrences. It reports false
+// when the event has no later occurrence.
+func (e Event) NextOccurrence(after time.Time, loc *time.Location) (time.Time, bool) {
+	if e.Recurrence == nil {
+		return e.DateTime, e.DateTime.After(after)
+	}
+
+	r := *e.Recurrence
+	start := e.DateTime.In(loc)
+
+	n := 0
+	if r.Count == 0 {
+		n = r.firstStep(start, after, 0)
+	}
+	for seen := 0; ; n++ {
+		t, ok := r.step(start, n)
+		if r.Until != nil && t.After(*r.Until) {
+			return time.Time{}, false
+		}
+		if !ok {
+			continue
+		}
+
+		seen++
+		if r.Count > 0 && seen > r.Count {
+			return time.Time{}, false
+		}
+		if t.After(after) {
+			return t, true
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package models
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"event-planner/db"
+	"fmt"
+	"time"
+)
+
+// Registration statuses. Members named in a group registration start out
+// invited, holding a seat until they accept, decline or the invitation
+// expires.
+const (
+	RegistrationConfirmed = "confirmed"
+	RegistrationInvited   = "invited"
+)
+
+// InvitationTTL is how long an unanswered group invitation holds its seat.
+const InvitationTTL = 48 * time.Hour
+
+var (
+	ErrGuestLimit   = errors.New("too many guests for this event")
+	ErrNoInvitation = errors.New("no pending invitation for this event")
+)
+
+// Registration is one user's place at an event. TicketToken is the code on
+// their ticket and must only be shown to the holder.
+type Registration struct {
+	ID          int64
+	EventID     int64
+	UserID      int64
+	Email       string
+	Status      string
+	Guests      int
+	GroupID     *int64
+	TicketToken string
+	CheckedInAt *time.Time
+	Answers     RegistrationAnswers
+}
+
+// RegistrationGroup ties together registrations made by one leader for a team.
+type RegistrationGroup struct {
+	ID        int64
+	EventID   int64
+	LeaderID  int64
+	Name      string
+	CreatedAt time.Time
+	Members   []Registration
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func newTicketToken() (string, error) {
+	buf := make([]byte, 16)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// insertRegistration adds the registration if the user has none yet and the
+// event has room for them and their guests. The checks run in the same
+// statement as the insert so concurrent requests cannot overbook.
+func (e Event) insertRegistration(q queryer, r *Registration) error {
+	token, err := newTicketToken()
+	if err != nil {
+		return err
+	}
+
+	answers, err := json.Marshal(r.Answers)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO registrations (event_id, user_id, status, guests, group_id, answers, ticket_token, registered_at)
+	SELECT ?, ?, ?, ?, ?, ?, ?, ?
+	WHERE NOT EXISTS (SELECT 1 FROM registrations WHERE event_id = ? AND user_id = ?)
+	AND (
+		(SELECT capacity FROM events WHERE id = ?) = 0
+		OR (SELECT COALESCE(SUM(1 + guests), 0) FROM registrations WHERE event_id = ?) + 1 + ? <= (SELECT capacity FROM events WHERE id = ?)
+	)`
+
+	result, err := q.Exec(query,
+		e.ID, r.UserID, r.Status, r.Guests, r.GroupID, string(answers), token, time.Now().UTC(),
+		e.ID, r.UserID,
+		e.ID, e.ID, r.Guests, e.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		var count int
+		err = q.QueryRow("SELECT COUNT(*) FROM registrations WHERE event_id = ? AND user_id = ?", e.ID, r.UserID).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAlreadyRegistered
+		}
+		return ErrEventFull
+	}
+
+	id, err := result.LastInsertId()
+	r.ID = id
+	r.EventID = e.ID
+	r.TicketToken = token
+	return err
+}
+
+// RegisterWithGuests adds the user and their guests to the event with their
+// answers to its registration form, returning an *AnswerError when they do
+// not satisfy the form.
+func (e Event) RegisterWithGuests(userID int64, guests int, answers RegistrationAnswers) error {
+	if guests < 0 || guests > e.GuestLimit {
+		return ErrGuestLimit
+	}
+
+	answers, err := e.validateAnswers(answers)
+	if err != nil {
+		return err
+	}
+
+	registration := Registration{UserID: userID, Status: RegistrationConfirmed, Guests: guests, Answers: answers}
+	err = e.insertRegistration(db.DB, &registration)
+	if err != nil {
+		return err
+	}
+
+	err = e.publishSeats()
+	if err != nil {
+		return err
+	}
+
+	return ScheduleReminders(e, userID)
+}
+
+// RegisterGroup registers the leader and invites each member. Members hold a
+// seat right away but only get a confirmed ticket once they accept. Either
+// everyone fits or nobody is registered.
+func (e Event) RegisterGroup(leaderID int64, name string, guests int, answers RegistrationAnswers, memberIDs []int64) (*RegistrationGroup, error) {
+	if guests < 0 || guests > e.GuestLimit {
+		return nil, ErrGuestLimit
+	}
+
+	answers, err := e.validateAnswers(answers)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	group := RegistrationGroup{EventID: e.ID, LeaderID: leaderID, Name: name, CreatedAt: time.Now().UTC()}
+	result, err := tx.Exec("INSERT INTO registration_groups (event_id, leader_id, name, created_at) VALUES (?, ?, ?, ?)",
+		group.EventID, group.LeaderID, group.Name, group.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	group.ID, err = result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	leader := Registration{UserID: leaderID, Status: RegistrationConfirmed, Guests: guests, GroupID: &group.ID, Answers: answers}
+	err = e.insertRegistration(tx, &leader)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, memberID := range memberIDs {
+		member := Registration{UserID: memberID, Status: RegistrationInvited, GroupID: &group.ID, Answers: RegistrationAnswers{}}
+		err = e.insertRegistration(tx, &member)
+		if err != nil {
+			return nil, fmt.Errorf("member %d: %w", memberID, err)
+		}
+		group.Members = append(group.Members, member)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	err = e.publishSeats()
+	if err != nil {
+		return nil, err
+	}
+
+	return &group, ScheduleReminders(e, leaderID)
+}
+
+// AcceptInvitation confirms the user's pending group invitation with their
+// answers to the registration form. Expired invitations cannot be accepted.
+func (e Event) AcceptInvitation(userID int64, answers RegistrationAnswers) error {
+	answers, err := e.validateAnswers(answers)
+	if err != nil {
+		return err
+	}
+
+	encodedAnswers, err := json.Marshal(answers)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	UPDATE registrations SET status = ?, answers = ?
+	WHERE event_id = ? AND user_id = ? AND status = ? AND julianday(registered_at) > julianday(?)`
+
+	result, err := db.DB.Exec(query, RegistrationConfirmed, string(encodedAnswers), e.ID, userID, RegistrationInvited,
+		time.Now().Add(-InvitationTTL).UTC())
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNoInvitation
+	}
+	return ScheduleReminders(e, userID)
+}
+
+// DeclineInvitation gives up the seat held for the user's group invitation.
+func (e Event) DeclineInvitation(userID int64) error {
+	result, err := db.DB.Exec("DELETE FROM registrations WHERE event_id = ? AND user_id = ? AND status = ?", e.ID, userID, RegistrationInvited)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNoInvitation
+	}
+	return e.publishSeats()
+}
+
+// ExpireInvitations frees the seats of group invitations left unanswered for
+// InvitationTTL.
+func ExpireInvitations(now time.Time) error {
+	cutoff := now.Add(-InvitationTTL).UTC()
+	eventIDs, err := queryIDs("SELECT DISTINCT event_id FROM registrations WHERE status = ? AND julianday(registered_at) <= julianday(?)",
+		RegistrationInvited, cutoff)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.DB.Exec("DELETE FROM registrations WHERE status = ? AND julianday(registered_at) <= julianday(?)", RegistrationInvited, cutoff)
+	if err != nil {
+		return err
+	}
+
+	for _, eventID := range eventIDs {
+		err = Event{ID: eventID}.publishSeats()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Helper function to check answers against the event's registration form
+func (e Event) validateAnswers(answers RegistrationAnswers) (RegistrationAnswers, error) {
+	fields, err := GetRegistrationForm(e.ID)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateAnswers(fields, answers)
+}
+
+const registrationColumns = "r.id, r.event_id, r.user_id, u.email, r.status, r.guests, r.group_id, r.ticket_token, r.checked_in_at, r.answers"
+
+func scanRegistration(row scanner) (*Registration, error) {
+	var r Registration
+	var answers string
+	err := row.Scan(&r.ID, &r.EventID, &r.UserID, &r.Email, &r.Status, &r.Guests, &r.GroupID, &r.TicketToken, &r.CheckedInAt, &answers)
+	if err != nil {
+		return nil, err
+	}
+	return &r, json.Unmarshal([]byte(answers), &r.Answers)
+}
+
+func queryRegistrations(query string, args ...any) ([]Registration, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	registrations := []Registration{}
+
+	for rows.Next() {
+		registration, err := scanRegistration(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		registrations = append(registrations, *registration)
+	}
+	return registrations, rows.Err()
+}
+
+// GetRegistration returns the user's registration for the event, confirmed
+// or invited.
+func GetRegistration(eventID, userID int64) (*Registration, error) {
+	query := "SELECT " + registrationColumns + " FROM registrations r JOIN users u ON u.id = r.user_id WHERE r.event_id = ? AND r.user_id = ?"
+	return scanRegistration(db.DB.QueryRow(query, eventID, userID))
+}
+
+// Registrations returns every registration for the event in sign-up order.
+func (e Event) Registrations() ([]Registration, error) {
+	query := "SELECT " + registrationColumns + " FROM registrations r JOIN users u ON u.id = r.user_id WHERE r.event_id = ? ORDER BY r.id"
+	return queryRegistrations(query, e.ID)
+}
+
+// EachRegistration calls fn for every registration in sign-up order, reading
+// them one at a time so exports of large events stay small in memory. It stops
+// at the first error fn returns.
+func (e Event) EachRegistration(fn func(Registration) error) error {
+	query := "SELECT " + registrationColumns + " FROM registrations r JOIN users u ON u.id = r.user_id WHERE r.event_id = ? ORDER BY r.id"
+	rows, err := db.DB.Query(query, e.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		registration, err := scanRegistration(rows)
+		if err != nil {
+			return err
+		}
+
+		err = fn(*registration)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func GetRegistrationGroup(id int64) (*RegistrationGroup, error) {
+	var group RegistrationGroup
+	err := db.DB.QueryRow("SELECT id, event_id, leader_id, name, created_at FROM registration_groups WHERE id = ?", id).
+		Scan(&group.ID, &group.EventID, &group.LeaderID, &group.Name, &group.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + registrationColumns + " FROM registrations r JOIN users u ON u.id = r.user_id WHERE r.group_id = ? AND r.user_id != ? ORDER BY r.id"
+	group.Members, err = queryRegistrations(query, group.ID, group.LeaderID)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
@@ -0,0 +1,197 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"event-planner/db"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Registration form field kinds. Text and choice answers are strings; a
+// checkbox answer is a boolean, and a required checkbox must be ticked.
+const (
+	FieldText     = "text"
+	FieldChoice   = "choice"
+	FieldCheckbox = "checkbox"
+)
+
+const maxFieldLength = 1000
+
+var fieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// FormField is one question on an event's registration form. Key identifies
+// the answer and must stay the same when the label changes. Text fields may
+// limit their length and require a regular expression match.
+type FormField struct {
+	Key       string
+	Label     string
+	Kind      string
+	Options   []string
+	Required  bool
+	MaxLength int
+	Pattern   string
+}
+
+// RegistrationAnswers maps form field keys to the registrant's answers.
+type RegistrationAnswers map[string]any
+
+// AnswerError explains why registration answers were rejected.
+type AnswerError struct {
+	Field   string
+	Message string
+}
+
+func (e *AnswerError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidateForm checks the form definition itself.
+func ValidateForm(fields []FormField) error {
+	seen := map[string]bool{}
+	for i, field := range fields {
+		if !fieldKeyPattern.MatchString(field.Key) {
+			return fmt.Errorf("field %d: key must be lowercase letters, digits and underscores", i+1)
+		}
+		if seen[field.Key] {
+			return fmt.Errorf("field %d: duplicate key %q", i+1, field.Key)
+		}
+		seen[field.Key] = true
+
+		if strings.TrimSpace(field.Label) == "" {
+			return fmt.Errorf("field %q needs a label", field.Key)
+		}
+
+		switch field.Kind {
+		case FieldText:
+			if field.Pattern != "" {
+				_, err := regexp.Compile(field.Pattern)
+				if err != nil {
+					return fmt.Errorf("field %q: invalid pattern", field.Key)
+				}
+			}
+		case FieldChoice:
+			if len(field.Options) < 2 {
+				return fmt.Errorf("field %q needs at least two options", field.Key)
+			}
+		case FieldCheckbox:
+		default:
+			return fmt.Errorf("field %q: kind must be text, choice or checkbox", field.Key)
+		}
+
+		if field.Kind != FieldChoice && len(field.Options) > 0 {
+			return fmt.Errorf("field %q: only choice fields have options", field.Key)
+		}
+		if field.Kind != FieldText && (field.Pattern != "" || field.MaxLength != 0) {
+			return fmt.Errorf("field %q: only text fields have a pattern or maximum length", field.Key)
+		}
+		if field.MaxLength < 0 || field.MaxLength > maxFieldLength {
+			return fmt.Errorf("field %q: maximum length must be between 0 and %d", field.Key, maxFieldLength)
+		}
+	}
+	return nil
+}
+
+// ValidateAnswers checks the answers against the form and returns them
+// normalized: strings trimmed, unanswered optional fields dropped.
+func ValidateAnswers(fields []FormField, answers RegistrationAnswers) (RegistrationAnswers, error) {
+	for key := range answers {
+		if !slices.ContainsFunc(fields, func(field FormField) bool { return field.Key == key }) {
+			return nil, &AnswerError{Field: key, Message: "is not on the registration form"}
+		}
+	}
+
+	normalized := RegistrationAnswers{}
+	for _, field := range fields {
+		value, ok := answers[field.Key]
+		if !ok || value == nil {
+			if field.Required {
+				return nil, &AnswerError{Field: field.Key, Message: "is required"}
+			}
+			continue
+		}
+
+		if field.Kind == FieldCheckbox {
+			checked, ok := value.(bool)
+			if !ok {
+				return nil, &AnswerError{Field: field.Key, Message: "must be true or false"}
+			}
+			if field.Required && !checked {
+				return nil, &AnswerError{Field: field.Key, Message: "must be checked"}
+			}
+			normalized[field.Key] = checked
+			continue
+		}
+
+		text, ok := value.(string)
+		if !ok {
+			return nil, &AnswerError{Field: field.Key, Message: "must be text"}
+		}
+
+		text = strings.TrimSpace(text)
+		if text == "" {
+			if field.Required {
+				return nil, &AnswerError{Field: field.Key, Message: "is required"}
+			}
+			continue
+		}
+
+		if field.Kind == FieldChoice && !slices.Contains(field.Options, text) {
+			return nil, &AnswerError{Field: field.Key, Message: "must be one of " + strings.Join(field.Options, ", ")}
+		}
+
+		maxLength := field.MaxLength
+		if maxLength == 0 {
+			maxLength = maxFieldLength
+		}
+		if len([]rune(text)) > maxLength {
+			return nil, &AnswerError{Field: field.Key, Message: fmt.Sprintf("must be at most %d characters", maxLength)}
+		}
+
+		if field.Pattern != "" {
+			pattern, err := regexp.Compile(field.Pattern)
+			if err != nil || !pattern.MatchString(text) {
+				return nil, &AnswerError{Field: field.Key, Message: "is not in the expected format"}
+			}
+		}
+
+		normalized[field.Key] = text
+	}
+	return normalized, nil
+}
+
+// GetRegistrationForm returns the event's form fields, or none when the event
+// has no form.
+func GetRegistrationForm(eventID int64) ([]FormField, error) {
+	var data string
+	err := db.DB.QueryRow("SELECT fields FROM registration_forms WHERE event_id = ?", eventID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return []FormField{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []FormField
+	return fields, json.Unmarshal([]byte(data), &fields)
+}
+
+// SaveRegistrationForm replaces the event's form. Answers already given keep
+// their keys, so renaming a label does not lose them.
+func SaveRegistrationForm(eventID int64, fields []FormField) error {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO registration_forms (event_id, fields)
+	VALUES (?, ?)
+	ON CONFLICT(event_id) DO UPDATE SET fields = excluded.fields`
+
+	_, err = db.DB.Exec(query, eventID, string(data))
+	return err
+}
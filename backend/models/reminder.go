@@ -0,0 +1,225 @@
+package models
+
+import (
+	"event-planner/db"
+	"strings"
+	"time"
+)
+
+// ReminderOffsets lists how long before an event starts each reminder is sent.
+var ReminderOffsets = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"1h":  time.Hour,
+}
+
+// Reminder is a reminder of one occurrence of an event. EventDateTime is the
+// start of that occurrence, which for recurring events is not the series'
+// first start.
+type Reminder struct {
+	ID            int64
+	EventID       int64
+	UserID        int64
+	Kind          string
+	SendAt        time.Time
+	Email         string
+	EventName     string
+	EventLocation string
+	EventDateTime time.Time
+	// Channels are the only channels to send on, when an earlier attempt
+	// reached the others. Empty means every channel.
+	Channels []string
+}
+
+// ScheduleReminders queues the reminders for one registrant. Each kind is
+// queued for the first occurrence whose send time is still ahead, so for a
+// one-off event reminders whose send time has passed are skipped. Existing
+// reminders are left alone.
+func ScheduleReminders(event Event, userID int64) error {
+	query := `
+	INSERT OR IGNORE INTO reminders (event_id, user_id, kind, send_at)
+	VALUES (?, ?, ?, ?)`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	now := time.Now()
+	for kind, offset := range ReminderOffsets {
+		start, ok := event.NextOccurrence(now.Add(offset), event.DateTime.Location())
+		if !ok {
+			continue
+		}
+
+		_, err = stmt.Exec(event.ID, userID, kind, start.Add(-offset).UTC())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RescheduleReminders drops the unsent reminders for the event and queues
+// fresh ones for all current registrants based on the event's DateTime.
+// Reminders already sent are kept while they still match the start time, so
+// their kind is not sent again, and dropped once the event moved. Sent
+// reminders of a series are always dropped, since ScheduleReminders only
+// looks at occurrences that have not been reminded of yet.
+func (e Event) RescheduleReminders() error {
+	err := e.dropStaleReminders()
+	if err != nil {
+		return err
+	}
+
+	userIDs, err := e.RegistrantIDs()
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		err = ScheduleReminders(e, userID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e Event) dropStaleReminders() error {
+	if e.Recurrence != nil {
+		return e.deleteReminders()
+	}
+
+	_, err := db.DB.Exec("DELETE FROM reminders WHERE event_id = ? AND sent_at IS NULL", e.ID)
+	if err != nil {
+		return err
+	}
+
+	for kind, offset := range ReminderOffsets {
+		_, err = db.DB.Exec(`DELETE FROM reminders WHERE event_id = ? AND kind = ? AND julianday(send_at) != julianday(?)`,
+			e.ID, kind, e.DateTime.Add(-offset).UTC())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e Event) deleteReminders() error {
+	_, err := db.DB.Exec("DELETE FROM reminders WHERE event_id = ?", e.ID)
+	return err
+}
+
+func cancelReminders(eventID, userID int64) error {
+	_, err := db.DB.Exec("DELETE FROM reminders WHERE event_id = ? AND user_id = ?", eventID, userID)
+	return err
+}
+
+// GetDueReminders returns unsent reminders whose send time is at or before now
+// and whose occurrence has not started yet. Reminders of a series missed that
+// way are moved on to the next occurrence.
+func GetDueReminders(now time.Time) ([]Reminder, error) {
+	query := `
+	SELECT r.id, r.event_id, r.user_id, r.kind, r.send_at, r.channels, u.email, e.name, e.location, e.recurrence IS NOT NULL
+	FROM reminders r
+	JOIN events e ON e.id = r.event_id
+	JOIN users u ON u.id = r.user_id
+	WHERE r.sent_at IS NULL AND r.send_at <= ?
+	ORDER BY r.send_at`
+
+	rows, err := db.DB.Query(query, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reminders, missed []Reminder
+
+	for rows.Next() {
+		var r Reminder
+		var channels string
+		var recurring bool
+		err := rows.Scan(&r.ID, &r.EventID, &r.UserID, &r.Kind, &r.SendAt, &channels, &r.Email, &r.EventName, &r.EventLocation, &recurring)
+
+		if err != nil {
+			return nil, err
+		}
+
+		r.EventDateTime = r.SendAt.Add(ReminderOffsets[r.Kind])
+
+		if channels != "" {
+			r.Channels = strings.Split(channels, ",")
+		}
+
+		if !r.EventDateTime.After(now) {
+			if recurring {
+				missed = append(missed, r)
+			}
+			continue
+		}
+
+		reminders = append(reminders, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for _, r := range missed {
+		err = r.Rearm(now)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return reminders, nil
+}
+
+// Claim marks the reminder as sent. It reports false when another run already
+// claimed it, so a reminder is never delivered twice.
+func (r Reminder) Claim(now time.Time) (bool, error) {
+	result, err := db.DB.Exec("UPDATE reminders SET sent_at = ? WHERE id = ? AND sent_at IS NULL", now.UTC(), r.ID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// Rearm moves a reminder of a recurring event on to the next occurrence whose
+// send time is still ahead of now, so every occurrence gets its reminder.
+// Reminders of one-off events and of series without such an occurrence are
+// left as they are.
+func (r Reminder) Rearm(now time.Time) error {
+	event, err := GetEventByID(r.EventID)
+	if err != nil {
+		return err
+	}
+	if event.Recurrence == nil {
+		return nil
+	}
+
+	offset := ReminderOffsets[r.Kind]
+	after := r.EventDateTime
+	if now.Add(offset).After(after) {
+		after = now.Add(offset)
+	}
+
+	start, ok := event.NextOccurrence(after, event.DateTime.Location())
+	if !ok {
+		return nil
+	}
+
+	sendAt := start.Add(-offset)
+	_, err = db.DB.Exec("UPDATE reminders SET send_at = ?, sent_at = NULL, channels = '' WHERE id = ?", sendAt.UTC(), r.ID)
+	return err
+}
+
+// Release undoes a Claim so the reminder is retried on the next run, on the
+// given channels only, or on every channel when there are none.
+func (r Reminder) Release(channels []string) error {
+	_, err := db.DB.Exec("UPDATE reminders SET sent_at = NULL, channels = ? WHERE id = ?", strings.Join(channels, ","), r.ID)
+	return err
+}
@@ -0,0 +1,248 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"event-planner/db"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxSavedSearches is how many searches one user may save.
+const MaxSavedSearches = 20
+
+var ErrTooManySavedSearches = errors.New("you can save at most 20 searches")
+
+// SavedSearch is a search a user wants to hear about new matches for. An
+// event matches when it contains every word of Query, carries every one of
+// Tags and, if Categories is not empty, belongs to one of them or their
+// subcategories. Frequency is FrequencyImmediate or FrequencyDigest.
+type SavedSearch struct {
+	ID         int64
+	UserID     int64
+	Name       string `binding:"required"`
+	Query      string
+	Categories []string
+	Tags       []string
+	Frequency  string
+	CreatedAt  time.Time
+}
+
+// Validate trims and normalizes the search and checks its categories exist.
+func (s *SavedSearch) Validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" || len(s.Name) > 60 {
+		return errors.New("name must be between 1 and 60 characters")
+	}
+
+	s.Query = strings.Join(strings.Fields(s.Query), " ")
+	if len(s.Query) > 200 {
+		return errors.New("query must be at most 200 characters")
+	}
+
+	if s.Frequency == "" {
+		s.Frequency = FrequencyImmediate
+	}
+	if s.Frequency != FrequencyImmediate && s.Frequency != FrequencyDigest {
+		return fmt.Errorf("frequency must be %q or %q", FrequencyImmediate, FrequencyDigest)
+	}
+
+	tags, err := NormalizeTags(s.Tags)
+	if err != nil {
+		return err
+	}
+	s.Tags = tags
+
+	if s.Categories == nil {
+		s.Categories = []string{}
+	}
+	if len(s.Categories) > 10 {
+		return errors.New("a search can have at most 10 categories")
+	}
+	if s.Query == "" && len(s.Tags) == 0 && len(s.Categories) == 0 {
+		return errors.New("a search needs a query, a category or a tag")
+	}
+
+	categories, err := GetCategories()
+	if err != nil {
+		return err
+	}
+	known := map[string]bool{}
+	for _, category := range categories {
+		known[category.Slug] = true
+	}
+	for _, slug := range s.Categories {
+		if !known[slug] {
+			return fmt.Errorf("unknown category %q", slug)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the event matches the search.
+func (s SavedSearch) Matches(eventID int64) (bool, error) {
+	categories := s.Categories
+	if len(categories) == 0 {
+		categories = []string{""}
+	}
+
+	for _, category := range categories {
+		filter := EventFilter{Query: s.Query, Category: category, Tags: s.Tags}
+		matches, err := filter.Matches(eventID)
+		if err != nil || matches {
+			return matches, err
+		}
+	}
+	return false, nil
+}
+
+const savedSearchColumns = "id, user_id, name, query, categories, tags, frequency, created_at"
+
+func scanSavedSearch(row scanner) (*SavedSearch, error) {
+	var s SavedSearch
+	var categories, tags string
+	err := row.Scan(&s.ID, &s.UserID, &s.Name, &s.Query, &categories, &tags, &s.Frequency, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(categories), &s.Categories)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal([]byte(tags), &s.Tags)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func querySavedSearches(query string, args ...any) ([]SavedSearch, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	searches := []SavedSearch{}
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, *search)
+	}
+	return searches, rows.Err()
+}
+
+// GetSavedSearches returns the user's saved searches, oldest first.
+func GetSavedSearches(userID int64) ([]SavedSearch, error) {
+	return querySavedSearches("SELECT "+savedSearchColumns+" FROM saved_searches WHERE user_id = ? ORDER BY id", userID)
+}
+
+// GetSavedSearchCandidates returns the saved searches of everyone but the
+// event's organizer whose tags and categories the event can match, grouped by
+// user. Matches still has to check the query.
+func GetSavedSearchCandidates(event Event) ([]SavedSearch, error) {
+	query := `
+	WITH RECURSIVE ancestors(id, slug, parent_id) AS (
+		SELECT id, slug, parent_id FROM categories WHERE id = ?
+		UNION ALL
+		SELECT categories.id, categories.slug, categories.parent_id FROM categories JOIN ancestors ON categories.id = ancestors.parent_id
+	)
+	SELECT ` + savedSearchColumns + ` FROM saved_searches
+	WHERE user_id != ?
+		AND NOT EXISTS (SELECT 1 FROM json_each(tags) WHERE value NOT IN (SELECT tag FROM event_tags WHERE event_id = ?))
+		AND (categories = '[]' OR EXISTS (SELECT 1 FROM json_each(categories) WHERE value IN (SELECT slug FROM ancestors)))
+	ORDER BY user_id, id`
+
+	return querySavedSearches(query, event.CategoryID, event.UserID, event.ID)
+}
+
+// QueueSavedSearchMatch marks a new event to be checked against the saved
+// searches by a background job, off the request path.
+func QueueSavedSearchMatch(eventID int64) error {
+	_, err := db.DB.Exec("INSERT OR IGNORE INTO saved_search_queue (event_id) VALUES (?)", eventID)
+	return err
+}
+
+// GetQueuedSavedSearchMatches returns the IDs of the queued events, oldest
+// first.
+func GetQueuedSavedSearchMatches() ([]int64, error) {
+	return queryIDs("SELECT event_id FROM saved_search_queue ORDER BY rowid")
+}
+
+// ClaimSavedSearchMatch removes the event from the queue. It reports false
+// when another run already took it, so matches are announced at most once.
+func ClaimSavedSearchMatch(eventID int64) (bool, error) {
+	result, err := db.DB.Exec("DELETE FROM saved_search_queue WHERE event_id = ?", eventID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+func GetSavedSearch(id int64) (*SavedSearch, error) {
+	row := db.DB.QueryRow("SELECT "+savedSearchColumns+" FROM saved_searches WHERE id = ?", id)
+	return scanSavedSearch(row)
+}
+
+// Save inserts the search unless the user already has MaxSavedSearches.
+func (s *SavedSearch) Save() error {
+	categories, err := json.Marshal(s.Categories)
+	if err != nil {
+		return err
+	}
+	tags, err := json.Marshal(s.Tags)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO saved_searches (user_id, name, query, categories, tags, frequency, created_at)
+	SELECT ?, ?, ?, ?, ?, ?, ?
+	WHERE (SELECT COUNT(*) FROM saved_searches WHERE user_id = ?) < ?`
+
+	s.CreatedAt = time.Now().UTC()
+	result, err := db.DB.Exec(query, s.UserID, s.Name, s.Query, string(categories), string(tags), s.Frequency, s.CreatedAt, s.UserID, MaxSavedSearches)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrTooManySavedSearches
+	}
+
+	s.ID, err = result.LastInsertId()
+	return err
+}
+
+func (s SavedSearch) Update() error {
+	categories, err := json.Marshal(s.Categories)
+	if err != nil {
+		return err
+	}
+	tags, err := json.Marshal(s.Tags)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	UPDATE saved_searches SET name = ?, query = ?, categories = ?, tags = ?, frequency = ?
+	WHERE id = ?`
+
+	_, err = db.DB.Exec(query, s.Name, s.Query, string(categories), string(tags), s.Frequency, s.ID)
+	return err
+}
+
+func (s SavedSearch) Delete() error {
+	_, err := db.DB.Exec("DELETE FROM saved_searches WHERE id = ?", s.ID)
+	return err
+}
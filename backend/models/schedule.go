@@ -0,0 +1,174 @@
+package models
+
+import (
+	"event-planner/db"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Roles of a user in the events of their schedule.
+const (
+	ScheduleAttending  = "attending"
+	ScheduleOrganizing = "organizing"
+)
+
+// ScheduleEntry is one occurrence of an event in a user's schedule. Conflicts
+// lists the event IDs of the other entries it overlaps, in order of start
+// time.
+type ScheduleEntry struct {
+	Event     Event
+	Role      string
+	Start     time.Time
+	Ends      time.Time
+	Conflicts []int64
+}
+
+// SeriesHorizon is how far ahead recurring events without an end are expanded
+// when looking for overlaps or listing a schedule without an end.
+const SeriesHorizon = 365 * 24 * time.Hour
+
+// endsSQL computes the end of an event in SQL the way Ends does, in whole Unix
+// seconds. Julian day fractions would round the default duration and make
+// back-to-back events overlap.
+const endsSQL = "COALESCE(unixepoch(end_time), unixepoch(dateTime) + ?)"
+
+var defaultEventSeconds = int64(DefaultEventDuration / time.Second)
+
+func queryEvents(query string, args ...any) ([]Event, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, loadTags(events)
+}
+
+// seriesOverlap reports whether an occurrence starting in a overlaps one
+// starting in b. Both must be in order.
+func seriesOverlap(a []time.Time, aDuration time.Duration, b []time.Time, bDuration time.Duration) bool {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		aEnds, bEnds := a[i].Add(aDuration), b[j].Add(bDuration)
+		if a[i].Before(bEnds) && b[j].Before(aEnds) {
+			return true
+		}
+		// The occurrence ending first cannot overlap anything later
+		if aEnds.Before(bEnds) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return false
+}
+
+// Conflicts returns the other events the user holds a confirmed registration
+// for that overlap this one, in order of start time. Recurring events are
+// compared occurrence by occurrence, up to SeriesHorizon ahead.
+func (e Event) Conflicts(userID int64) ([]Event, error) {
+	from, to := e.DateTime, e.Ends()
+	if e.Recurrence != nil {
+		// Past occurrences cannot clash any more
+		if now := time.Now(); now.After(from) {
+			from = now
+		}
+		to = from.Add(SeriesHorizon)
+	}
+
+	query := "SELECT " + eventColumns + ` FROM events
+	WHERE id != ?
+		AND id IN (SELECT event_id FROM registrations WHERE user_id = ? AND status = ?)
+		AND unixepoch(dateTime) < unixepoch(?) AND (recurrence IS NOT NULL OR ` + endsSQL + ` > unixepoch(?))
+	ORDER BY julianday(dateTime), id`
+
+	candidates, err := queryEvents(query, e.ID, userID, RegistrationConfirmed, to.UTC(), defaultEventSeconds, from.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	loc := e.DateTime.Location()
+	occurrences := e.Occurrences(from, to, loc)
+	duration := e.Ends().Sub(e.DateTime)
+
+	conflicts := []Event{}
+	for _, other := range candidates {
+		if seriesOverlap(occurrences, duration, other.Occurrences(from, to, loc), other.Ends().Sub(other.DateTime)) {
+			conflicts = append(conflicts, other)
+		}
+	}
+	return conflicts, nil
+}
+
+// GetSchedule merges the events the user is registered for and those they
+// organize into one timeline of the occurrences still running after from and,
+// when to is not nil, starting before it. Recurring events are expanded in
+// from's location, up to SeriesHorizon ahead when to is nil. Overlapping
+// entries are flagged in each other's Conflicts.
+func GetSchedule(userID int64, from time.Time, to *time.Time) ([]ScheduleEntry, error) {
+	query := "SELECT " + eventColumns + ` FROM events
+	WHERE (userID = ? OR id IN (SELECT event_id FROM registrations WHERE user_id = ? AND status = ?))
+		AND (recurrence IS NOT NULL OR ` + endsSQL + ` > unixepoch(?))
+		AND (? IS NULL OR unixepoch(dateTime) < unixepoch(?))
+	ORDER BY julianday(dateTime), id`
+
+	var until any
+	seriesEnd := from.Add(SeriesHorizon)
+	if to != nil {
+		until = to.UTC()
+		seriesEnd = *to
+	}
+
+	events, err := queryEvents(query, userID, userID, RegistrationConfirmed, defaultEventSeconds, from.UTC(), until, until)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := []ScheduleEntry{}
+	for _, event := range events {
+		role := ScheduleAttending
+		if event.UserID == userID {
+			role = ScheduleOrganizing
+		}
+
+		starts := []time.Time{event.DateTime}
+		if event.Recurrence != nil {
+			starts = event.Occurrences(from, seriesEnd, from.Location())
+		}
+
+		duration := event.Ends().Sub(event.DateTime)
+		for _, start := range starts {
+			schedule = append(schedule, ScheduleEntry{Event: event, Role: role, Start: start, Ends: start.Add(duration), Conflicts: []int64{}})
+		}
+	}
+	sort.SliceStable(schedule, func(i, j int) bool { return schedule[i].Start.Before(schedule[j].Start) })
+
+	// Entries are sorted by start, so only later entries starting before this
+	// one ends can overlap it
+	for i := range schedule {
+		for j := i + 1; j < len(schedule) && schedule[j].Start.Before(schedule[i].Ends); j++ {
+			if schedule[i].Event.ID == schedule[j].Event.ID {
+				continue
+			}
+			if !slices.Contains(schedule[i].Conflicts, schedule[j].Event.ID) {
+				schedule[i].Conflicts = append(schedule[i].Conflicts, schedule[j].Event.ID)
+			}
+			if !slices.Contains(schedule[j].Conflicts, schedule[i].Event.ID) {
+				schedule[j].Conflicts = append(schedule[j].Conflicts, schedule[i].Event.ID)
+			}
+		}
+	}
+	return schedule, nil
+}
@@ -0,0 +1,249 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"event-planner/db"
+	"event-planner/geo"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Date facet intervals.
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// EventFilter narrows down the events listed. Zero fields match everything;
+// an event must carry every one of Tags and contain every word of Query in
+// its name, description or location. With Near set, only events with
+// coordinates within Radius meters of it match.
+type EventFilter struct {
+	Query    string
+	Category string
+	Tags     []string
+	From     *time.Time
+	To       *time.Time
+	Near     *geo.Point
+	Radius   float64
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// where returns the SQL conditions and arguments selecting the filtered
+// events.
+func (f EventFilter) where() (string, []any) {
+	conditions := []string{"1 = 1"}
+	args := []any{}
+
+	for _, word := range strings.Fields(f.Query) {
+		conditions = append(conditions, `(name LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\' OR location LIKE ? ESCAPE '\')`)
+		pattern := "%" + likeEscaper.Replace(word) + "%"
+		args = append(args, pattern, pattern, pattern)
+	}
+
+	if f.Category != "" {
+		conditions = append(conditions, `category_id IN (
+			WITH RECURSIVE subtree(id) AS (
+				SELECT id FROM categories WHERE slug = ?
+				UNION
+				SELECT categories.id FROM categories JOIN subtree ON categories.parent_id = subtree.id
+			)
+			SELECT id FROM subtree
+		)`)
+		args = append(args, f.Category)
+	}
+	for _, tag := range f.Tags {
+		conditions = append(conditions, "id IN (SELECT event_id FROM event_tags WHERE tag = ?)")
+		args = append(args, NormalizeTag(tag))
+	}
+	if f.From != nil {
+		conditions = append(conditions, "julianday(dateTime) >= julianday(?)")
+		args = append(args, *f.From)
+	}
+	if f.To != nil {
+		conditions = append(conditions, "julianday(dateTime) < julianday(?)")
+		args = append(args, *f.To)
+	}
+	if f.Near != nil {
+		// The bounding box narrows candidates down using the index;
+		// nearby checks the exact distance
+		box := geo.BoundingBox(*f.Near, f.Radius)
+		conditions = append(conditions, "latitude BETWEEN ? AND ?")
+		args = append(args, box.MinLatitude, box.MaxLatitude)
+		if box.MinLongitude <= box.MaxLongitude {
+			conditions = append(conditions, "longitude BETWEEN ? AND ?")
+		} else {
+			conditions = append(conditions, "(longitude >= ? OR longitude <= ?)")
+		}
+		args = append(args, box.MinLongitude, box.MaxLongitude)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// nearby reports whether the event is within the filter's radius, or true
+// when the filter has no Near.
+func (f EventFilter) nearby(e Event) bool {
+	if f.Near == nil {
+		return true
+	}
+	point, ok := e.Point()
+	return ok && geo.Distance(*f.Near, point) <= f.Radius
+}
+
+// Matches reports whether the event passes the filter.
+func (f EventFilter) Matches(eventID int64) (bool, error) {
+	where, args := f.where()
+	row := db.DB.QueryRow("SELECT "+eventColumns+" FROM events WHERE id = ? AND "+where, append([]any{eventID}, args...)...)
+	event, err := scanEvent(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return f.nearby(*event), nil
+}
+
+// FindEvents returns the events matching the filter, with their tags, in
+// order of start time.
+func FindEvents(filter EventFilter) ([]Event, error) {
+	where, args := filter.where()
+	rows, err := db.DB.Query("SELECT "+eventColumns+" FROM events WHERE "+where+" ORDER BY julianday(dateTime), id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		if filter.nearby(*event) {
+			events = append(events, *event)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, loadTags(events)
+}
+
+type CategoryFacet struct {
+	Slug  string
+	Name  string
+	Count int
+}
+
+type TagFacet struct {
+	Tag   string
+	Count int
+}
+
+// DateFacet counts the events starting in [Start, Start + interval). Bucket
+// is Start formatted as 2006-01 for months and 2006-01-02 otherwise.
+type DateFacet struct {
+	Bucket string
+	Start  time.Time
+	Count  int
+}
+
+// EventFacets breaks a filtered list of events down by category, tag and start
+// date, so clients can show how many results each refinement would leave.
+// Categories count the events assigned to them directly.
+type EventFacets struct {
+	Total      int
+	Categories []CategoryFacet
+	Tags       []TagFacet
+	Dates      []DateFacet
+}
+
+// bucketStart returns the start of the interval containing t, in t's location.
+func bucketStart(t time.Time, interval string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch interval {
+	case IntervalMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	case IntervalWeek:
+		// Weeks start on Monday
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return day
+}
+
+// Facets counts the events matching the filter by category, tag and date
+// bucket. Buckets are computed in loc.
+func Facets(filter EventFilter, interval string, loc *time.Location) (*EventFacets, error) {
+	if interval != IntervalDay && interval != IntervalWeek && interval != IntervalMonth {
+		return nil, errors.New("interval must be day, week or month")
+	}
+
+	events, err := FindEvents(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := GetCategories()
+	if err != nil {
+		return nil, err
+	}
+	categoryIndex := map[int64]int{}
+	for i, category := range categories {
+		categoryIndex[category.ID] = i
+	}
+
+	facets := EventFacets{Total: len(events), Categories: []CategoryFacet{}, Tags: []TagFacet{}, Dates: []DateFacet{}}
+	categoryCounts := map[int64]int{}
+	tagCounts := map[string]int{}
+	dates := map[string]*DateFacet{}
+	for _, event := range events {
+		if event.CategoryID != nil {
+			categoryCounts[*event.CategoryID]++
+		}
+		for _, tag := range event.Tags {
+			tagCounts[tag]++
+		}
+		start := bucketStart(event.DateTime.In(loc), interval)
+		bucket := start.Format("2006-01-02")
+		if interval == IntervalMonth {
+			bucket = start.Format("2006-01")
+		}
+		if dates[bucket] == nil {
+			dates[bucket] = &DateFacet{Bucket: bucket, Start: start}
+		}
+		dates[bucket].Count++
+	}
+
+	for id, count := range categoryCounts {
+		i, ok := categoryIndex[id]
+		if ok {
+			facets.Categories = append(facets.Categories, CategoryFacet{Slug: categories[i].Slug, Name: categories[i].Name, Count: count})
+		}
+	}
+	for tag, count := range tagCounts {
+		facets.Tags = append(facets.Tags, TagFacet{Tag: tag, Count: count})
+	}
+	for _, date := range dates {
+		facets.Dates = append(facets.Dates, *date)
+	}
+
+	// Most common first, then alphabetically; dates in order
+	sort.Slice(facets.Categories, func(i, j int) bool {
+		a, b := facets.Categories[i], facets.Categories[j]
+		return a.Count > b.Count || a.Count == b.Count && a.Name < b.Name
+	})
+	sort.Slice(facets.Tags, func(i, j int) bool {
+		a, b := facets.Tags[i], facets.Tags[j]
+		return a.Count > b.Count || a.Count == b.Count && a.Tag < b.Tag
+	})
+	sort.Slice(facets.Dates, func(i, j int) bool {
+		return facets.Dates[i].Start.Before(facets.Dates[j].Start)
+	})
+	return &facets, nil
+}
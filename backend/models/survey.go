@@ -0,0 +1,372 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"event-planner/db"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrAlreadyResponded = errors.New("already responded to this survey")
+
+// Survey question kinds. Ratings are 1 to 5 and NPS answers 0 to 10; choice
+// answers are the index of the picked option.
+const (
+	QuestionRating = "rating"
+	QuestionNPS    = "nps"
+	QuestionText   = "text"
+	QuestionChoice = "choice"
+)
+
+const maxSurveyTextLength = 2000
+
+// Survey is the feedback form attached to an event. Checked-in attendees are
+// invited to fill it in once the event has ended.
+type Survey struct {
+	ID            int64
+	EventID       int64
+	Title         string           `binding:"required"`
+	Questions     []SurveyQuestion `binding:"required"`
+	InvitesSentAt *time.Time
+	CreatedAt     time.Time
+}
+
+type SurveyQuestion struct {
+	Kind     string
+	Prompt   string
+	Options  []string
+	Required bool
+}
+
+// SurveyAnswer answers the question at the same index. Value is used by
+// rating, NPS and choice questions, Text by text questions.
+type SurveyAnswer struct {
+	Value *int
+	Text  string
+}
+
+type SurveyResponse struct {
+	ID        int64
+	SurveyID  int64
+	UserID    int64
+	Email     string
+	Answers   []SurveyAnswer
+	CreatedAt time.Time
+}
+
+// SurveyResults aggregates every response to a survey.
+type SurveyResults struct {
+	SurveyID  int64
+	Responses int
+	Questions []QuestionResult
+}
+
+// QuestionResult summarizes the answers to one question. Only the fields
+// that apply to the question's kind are set.
+type QuestionResult struct {
+	Kind    string
+	Prompt  string
+	Answers int
+	Average *float64
+	// Distribution counts each rating or NPS score.
+	Distribution map[int]int `json:",omitempty"`
+	// NPS is the percentage of promoters (9-10) minus detractors (0-6).
+	NPS          *float64 `json:",omitempty"`
+	OptionCounts []int    `json:",omitempty"`
+	Texts        []string `json:",omitempty"`
+}
+
+func (s Survey) Validate() error {
+	if strings.TrimSpace(s.Title) == "" {
+		return errors.New("survey title is required")
+	}
+	if len(s.Questions) == 0 {
+		return errors.New("survey needs at least one question")
+	}
+
+	for i, question := range s.Questions {
+		if strings.TrimSpace(question.Prompt) == "" {
+			return fmt.Errorf("question %d needs a prompt", i+1)
+		}
+
+		switch question.Kind {
+		case QuestionRating, QuestionNPS, QuestionText:
+			if len(question.Options) > 0 {
+				return fmt.Errorf("question %d: only choice questions have options", i+1)
+			}
+		case QuestionChoice:
+			if len(question.Options) < 2 {
+				return fmt.Errorf("question %d needs at least two options", i+1)
+			}
+		default:
+			return fmt.Errorf("question %d: kind must be rating, nps, text or choice", i+1)
+		}
+	}
+	return nil
+}
+
+// ValidateAnswers checks there is one answer per question and each answer
+// fits its question.
+func (s Survey) ValidateAnswers(answers []SurveyAnswer) error {
+	if len(answers) != len(s.Questions) {
+		return fmt.Errorf("expected %d answers", len(s.Questions))
+	}
+
+	for i, question := range s.Questions {
+		answer := answers[i]
+		if question.Kind == QuestionText {
+			if question.Required && strings.TrimSpace(answer.Text) == "" {
+				return fmt.Errorf("question %d is required", i+1)
+			}
+			if len(answer.Text) > maxSurveyTextLength {
+				return fmt.Errorf("answer to question %d is too long", i+1)
+			}
+			continue
+		}
+
+		if answer.Value == nil {
+			if question.Required {
+				return fmt.Errorf("question %d is required", i+1)
+			}
+			continue
+		}
+
+		low, high := 0, len(question.Options)-1
+		switch question.Kind {
+		case QuestionRating:
+			low, high = 1, 5
+		case QuestionNPS:
+			low, high = 0, 10
+		}
+
+		if *answer.Value < low || *answer.Value > high {
+			return fmt.Errorf("answer to question %d must be between %d and %d", i+1, low, high)
+		}
+	}
+	return nil
+}
+
+// Save creates the event's survey or replaces its questions.
+func (s *Survey) Save() error {
+	query := `
+	INSERT INTO surveys (event_id, title, questions, created_at)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT(event_id) DO UPDATE SET title = excluded.title, questions = excluded.questions`
+
+	questions, err := json.Marshal(s.Questions)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.DB.Exec(query, s.EventID, s.Title, string(questions), time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	saved, err := GetSurveyForEvent(s.EventID)
+	if err != nil {
+		return err
+	}
+
+	*s = *saved
+	return nil
+}
+
+const surveyColumns = "id, event_id, title, questions, invites_sent_at, created_at"
+
+func scanSurvey(row scanner) (*Survey, error) {
+	var s Survey
+	var questions string
+	var invitesSentAt sql.NullTime
+	err := row.Scan(&s.ID, &s.EventID, &s.Title, &questions, &invitesSentAt, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitesSentAt.Valid {
+		s.InvitesSentAt = &invitesSentAt.Time
+	}
+	return &s, json.Unmarshal([]byte(questions), &s.Questions)
+}
+
+func GetSurveyForEvent(eventID int64) (*Survey, error) {
+	query := "SELECT " + surveyColumns + " FROM surveys WHERE event_id = ?"
+	return scanSurvey(db.DB.QueryRow(query, eventID))
+}
+
+// GetSurveysAwaitingInvites returns surveys whose invitations have not gone
+// out yet. The caller decides whether each event has ended.
+func GetSurveysAwaitingInvites() ([]Survey, error) {
+	query := "SELECT " + surveyColumns + " FROM surveys WHERE invites_sent_at IS NULL ORDER BY id"
+	rows, err := db.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	surveys := []Survey{}
+
+	for rows.Next() {
+		survey, err := scanSurvey(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		surveys = append(surveys, *survey)
+	}
+	return surveys, rows.Err()
+}
+
+// ClaimInvites marks the survey's invitations as sent. It reports false when
+// another run already claimed them.
+func (s Survey) ClaimInvites(now time.Time) (bool, error) {
+	result, err := db.DB.Exec("UPDATE surveys SET invites_sent_at = ? WHERE id = ? AND invites_sent_at IS NULL", now.UTC(), s.ID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+func (s Survey) CountResponses() (int, error) {
+	var count int
+	err := db.DB.QueryRow("SELECT COUNT(*) FROM survey_responses WHERE survey_id = ?", s.ID).Scan(&count)
+	return count, err
+}
+
+// Save stores the response. Each user can respond to a survey once.
+func (r *SurveyResponse) Save() error {
+	query := `
+	INSERT OR IGNORE INTO survey_responses (survey_id, user_id, answers, created_at)
+	VALUES (?, ?, ?, ?)`
+
+	answers, err := json.Marshal(r.Answers)
+	if err != nil {
+		return err
+	}
+
+	r.CreatedAt = time.Now().UTC()
+	result, err := db.DB.Exec(query, r.SurveyID, r.UserID, string(answers), r.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrAlreadyResponded
+	}
+
+	id, err := result.LastInsertId()
+	r.ID = id
+	return err
+}
+
+func GetSurveyResponses(surveyID int64) ([]SurveyResponse, error) {
+	query := `
+	SELECT r.id, r.survey_id, r.user_id, u.email, r.answers, r.created_at
+	FROM survey_responses r
+	JOIN users u ON u.id = r.user_id
+	WHERE r.survey_id = ?
+	ORDER BY r.id`
+
+	rows, err := db.DB.Query(query, surveyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	responses := []SurveyResponse{}
+
+	for rows.Next() {
+		var r SurveyResponse
+		var answers string
+		err := rows.Scan(&r.ID, &r.SurveyID, &r.UserID, &r.Email, &answers, &r.CreatedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(answers), &r.Answers)
+		if err != nil {
+			return nil, err
+		}
+
+		responses = append(responses, r)
+	}
+	return responses, rows.Err()
+}
+
+// Results aggregates the responses question by question.
+func (s Survey) Results(responses []SurveyResponse) SurveyResults {
+	results := SurveyResults{SurveyID: s.ID, Responses: len(responses)}
+
+	for i, question := range s.Questions {
+		result := QuestionResult{Kind: question.Kind, Prompt: question.Prompt}
+		if question.Kind == QuestionChoice {
+			result.OptionCounts = make([]int, len(question.Options))
+		}
+		if question.Kind == QuestionRating || question.Kind == QuestionNPS {
+			result.Distribution = map[int]int{}
+		}
+
+		sum, promoters, detractors := 0, 0, 0
+		for _, response := range responses {
+			if i >= len(response.Answers) {
+				continue
+			}
+			answer := response.Answers[i]
+
+			if question.Kind == QuestionText {
+				if strings.TrimSpace(answer.Text) != "" {
+					result.Answers++
+					result.Texts = append(result.Texts, answer.Text)
+				}
+				continue
+			}
+
+			if answer.Value == nil {
+				continue
+			}
+			value := *answer.Value
+			result.Answers++
+
+			switch question.Kind {
+			case QuestionChoice:
+				if value >= 0 && value < len(result.OptionCounts) {
+					result.OptionCounts[value]++
+				}
+			case QuestionNPS:
+				if value >= 9 {
+					promoters++
+				} else if value <= 6 {
+					detractors++
+				}
+				fallthrough
+			case QuestionRating:
+				result.Distribution[value]++
+				sum += value
+			}
+		}
+
+		if result.Answers > 0 && result.Distribution != nil {
+			average := float64(sum) / float64(result.Answers)
+			result.Average = &average
+		}
+		if result.Answers > 0 && question.Kind == QuestionNPS {
+			nps := float64(promoters-detractors) * 100 / float64(result.Answers)
+			result.NPS = &nps
+		}
+
+		results.Questions = append(results.Questions, result)
+	}
+	return results
+}
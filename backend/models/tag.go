@@ -0,0 +1,89 @@
+package models
+
+import (
+	"errors"
+	"event-planner/db"
+	"strings"
+)
+
+// MaxEventTags is how many tags one event may carry.
+const MaxEventTags = 10
+
+// NormalizeTag lowercases a free-form tag and joins its words with hyphens,
+// so "Machine Learning" and "machine-learning" are the same tag.
+func NormalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+}
+
+// NormalizeTags normalizes and deduplicates an event's tags, keeping their
+// order.
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len([]rune(tag)) > 30 {
+			return nil, errors.New("tags must be at most 30 characters")
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > MaxEventTags {
+		return nil, errors.New("an event can have at most 10 tags")
+	}
+	return normalized, nil
+}
+
+// saveTags replaces the event's tags with e.Tags.
+func (e Event) saveTags() error {
+	_, err := db.DB.Exec("DELETE FROM event_tags WHERE event_id = ?", e.ID)
+	if err != nil {
+		return err
+	}
+
+	for i, tag := range e.Tags {
+		_, err = db.DB.Exec("INSERT INTO event_tags (event_id, tag, position) VALUES (?, ?, ?)", e.ID, tag, i)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadTags fills in the tags of the given events.
+func loadTags(events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	index := map[int64]int{}
+	args := make([]any, len(events))
+	for i := range events {
+		events[i].Tags = []string{}
+		index[events[i].ID] = i
+		args[i] = events[i].ID
+	}
+
+	query := "SELECT event_id, tag FROM event_tags WHERE event_id IN (?" + strings.Repeat(", ?", len(events)-1) + ") ORDER BY event_id, position"
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var eventID int64
+		var tag string
+		err = rows.Scan(&eventID, &tag)
+		if err != nil {
+			return err
+		}
+		i := index[eventID]
+		events[i].Tags = append(events[i].Tags, tag)
+	}
+	return rows.Err()
+}
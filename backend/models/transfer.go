@@ -0,0 +1,281 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"event-planner/db"
+	"time"
+)
+
+// Transfer statuses.
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferDeclined  = "declined"
+	TransferCancelled = "cancelled"
+)
+
+var (
+	ErrTransfersClosed     = errors.New("ticket transfers are closed for this event")
+	ErrTransferPending     = errors.New("this ticket already has a pending transfer")
+	ErrTransferUnavailable = errors.New("this transfer can no longer be accepted")
+	ErrTransferExpired     = errors.New("this transfer offer has expired")
+)
+
+// TransferRules are the organizer's settings for handing tickets on. Transfers
+// close CutoffHours before the event starts, and unanswered offers lapse after
+// OfferExpiryHours.
+type TransferRules struct {
+	EventID          int64
+	Allowed          bool
+	CutoffHours      int
+	OfferExpiryHours int
+}
+
+// DefaultTransferRules apply to events whose organizer never changed them.
+func DefaultTransferRules(eventID int64) TransferRules {
+	return TransferRules{EventID: eventID, Allowed: true, CutoffHours: 0, OfferExpiryHours: 48}
+}
+
+func (r TransferRules) Validate() error {
+	if r.CutoffHours < 0 || r.CutoffHours > 24*30 {
+		return errors.New("cutoff hours must be between 0 and 720")
+	}
+	if r.OfferExpiryHours < 1 || r.OfferExpiryHours > 24*30 {
+		return errors.New("offer expiry hours must be between 1 and 720")
+	}
+	return nil
+}
+
+// Open reports whether tickets for an event starting at start may change
+// hands at now.
+func (r TransferRules) Open(start, now time.Time) bool {
+	return r.Allowed && now.Before(start.Add(-time.Duration(r.CutoffHours)*time.Hour))
+}
+
+func GetTransferRules(eventID int64) (*TransferRules, error) {
+	rules := DefaultTransferRules(eventID)
+	err := db.DB.QueryRow("SELECT allowed, cutoff_hours, offer_expiry_hours FROM transfer_rules WHERE event_id = ?", eventID).
+		Scan(&rules.Allowed, &rules.CutoffHours, &rules.OfferExpiryHours)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &rules, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+func (r TransferRules) Save() error {
+	query := `
+	INSERT INTO transfer_rules (event_id, allowed, cutoff_hours, offer_expiry_hours)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT(event_id) DO UPDATE SET
+		allowed = excluded.allowed,
+		cutoff_hours = excluded.cutoff_hours,
+		offer_expiry_hours = excluded.offer_expiry_hours`
+
+	_, err := db.DB.Exec(query, r.EventID, r.Allowed, r.CutoffHours, r.OfferExpiryHours)
+	return err
+}
+
+// Transfer is an offer to hand a registration to another user.
+type Transfer struct {
+	ID         int64
+	EventID    int64
+	FromUserID int64
+	FromEmail  string
+	ToUserID   int64
+	ToEmail    string
+	Status     string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	ResolvedAt *time.Time
+}
+
+const transferColumns = `
+	t.id, t.event_id, t.from_user_id, f.email, t.to_user_id, r.email, t.status, t.created_at, t.expires_at, t.resolved_at
+	FROM ticket_transfers t
+	JOIN users f ON f.id = t.from_user_id
+	JOIN users r ON r.id = t.to_user_id`
+
+func scanTransfer(row scanner) (*Transfer, error) {
+	var t Transfer
+	err := row.Scan(&t.ID, &t.EventID, &t.FromUserID, &t.FromEmail, &t.ToUserID, &t.ToEmail, &t.Status, &t.CreatedAt, &t.ExpiresAt, &t.ResolvedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Save records a pending offer. A ticket can only have one pending offer at
+// a time.
+func (t *Transfer) Save() error {
+	query := `
+	INSERT INTO ticket_transfers (event_id, from_user_id, to_user_id, status, created_at, expires_at)
+	SELECT ?, ?, ?, ?, ?, ?
+	WHERE NOT EXISTS (
+		SELECT 1 FROM ticket_transfers
+		WHERE event_id = ? AND from_user_id = ? AND status = ? AND expires_at > ?
+	)`
+
+	t.Status = TransferPending
+	t.CreatedAt = time.Now().UTC()
+	result, err := db.DB.Exec(query,
+		t.EventID, t.FromUserID, t.ToUserID, t.Status, t.CreatedAt, t.ExpiresAt.UTC(),
+		t.EventID, t.FromUserID, TransferPending, t.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrTransferPending
+	}
+
+	id, err := result.LastInsertId()
+	t.ID = id
+	return err
+}
+
+func GetTransferByID(id int64) (*Transfer, error) {
+	return scanTransfer(db.DB.QueryRow("SELECT "+transferColumns+" WHERE t.id = ?", id))
+}
+
+// GetPendingTransfersForUser returns unexpired offers the user made or received.
+func GetPendingTransfersForUser(userID int64, now time.Time) ([]Transfer, error) {
+	query := "SELECT " + transferColumns + `
+	WHERE (t.from_user_id = ? OR t.to_user_id = ?) AND t.status = ? AND t.expires_at > ?
+	ORDER BY t.id`
+
+	rows, err := db.DB.Query(query, userID, userID, TransferPending, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := []Transfer{}
+
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		transfers = append(transfers, *transfer)
+	}
+	return transfers, rows.Err()
+}
+
+// Accept moves the registration to the recipient with their answers to the
+// registration form and a new ticket token, so the holder's old ticket stops
+// working. Guests and group membership stay with the seat.
+func (t *Transfer) Accept(event Event, answers RegistrationAnswers, now time.Time) error {
+	if t.Status != TransferPending {
+		return ErrTransferUnavailable
+	}
+	if !now.Before(t.ExpiresAt) {
+		return ErrTransferExpired
+	}
+
+	answers, err := event.validateAnswers(answers)
+	if err != nil {
+		return err
+	}
+
+	encodedAnswers, err := json.Marshal(answers)
+	if err != nil {
+		return err
+	}
+
+	token, err := newTicketToken()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+	UPDATE registrations
+	SET user_id = ?, answers = ?, ticket_token = ?
+	WHERE event_id = ? AND user_id = ? AND status = ? AND checked_in_at IS NULL
+	AND NOT EXISTS (SELECT 1 FROM registrations WHERE event_id = ? AND user_id = ?)`,
+		t.ToUserID, string(encodedAnswers), token,
+		t.EventID, t.FromUserID, RegistrationConfirmed,
+		t.EventID, t.ToUserID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrTransferUnavailable
+	}
+
+	result, err = tx.Exec("UPDATE ticket_transfers SET status = ?, resolved_at = ? WHERE id = ? AND status = ?",
+		TransferAccepted, now.UTC(), t.ID, TransferPending)
+	if err != nil {
+		return err
+	}
+
+	affected, err = result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrTransferUnavailable
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	t.Status = TransferAccepted
+	resolvedAt := now.UTC()
+	t.ResolvedAt = &resolvedAt
+
+	err = cancelReminders(t.EventID, t.FromUserID)
+	if err != nil {
+		return err
+	}
+	return ScheduleReminders(event, t.ToUserID)
+}
+
+// Resolve closes a pending offer without transferring the ticket.
+func (t *Transfer) Resolve(status string, now time.Time) error {
+	result, err := db.DB.Exec("UPDATE ticket_transfers SET status = ?, resolved_at = ? WHERE id = ? AND status = ?",
+		status, now.UTC(), t.ID, TransferPending)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrTransferUnavailable
+	}
+
+	t.Status = status
+	resolvedAt := now.UTC()
+	t.ResolvedAt = &resolvedAt
+	return nil
+}
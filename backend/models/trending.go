@@ -0,0 +1,144 @@
+package models
+
+import (
+	"event-planner/db"
+	"math"
+	"sort"
+	"time"
+)
+
+// ViewDedupeWindow is how long repeat views of an event by the same viewer
+// are ignored.
+const ViewDedupeWindow = 24 * time.Hour
+
+// MaxViewsPerSource caps the anonymous views of an event counted from one
+// source within ViewDedupeWindow, so clients dropping their session cookie
+// cannot inflate the count. It leaves room for shared campus addresses.
+const MaxViewsPerSource = 20
+
+// Weights of each kind of activity in the trending score.
+const (
+	registrationWeight = 3.0
+	bookmarkWeight     = 2.0
+	viewWeight         = 1.0
+)
+
+// RecordView counts a view of the event by viewer, an opaque key for a user
+// or a session, unless the same viewer already viewed it within
+// ViewDedupeWindow. Anonymous views also pass source, an opaque key for where
+// they came from, and are not counted once that source reached
+// MaxViewsPerSource. It reports whether the view was counted.
+func RecordView(eventID int64, viewer, source string, now time.Time) (bool, error) {
+	query := `
+	INSERT INTO event_views (event_id, viewer, source, viewed_at)
+	SELECT ?, ?, ?, ?
+	WHERE NOT EXISTS (
+		SELECT 1 FROM event_views
+		WHERE event_id = ? AND viewer = ? AND julianday(viewed_at) > julianday(?)
+	)
+	AND (? = '' OR (
+		SELECT COUNT(*) FROM event_views
+		WHERE event_id = ? AND source = ? AND julianday(viewed_at) > julianday(?)
+	) < ?)`
+
+	since := now.Add(-ViewDedupeWindow).UTC()
+	result, err := db.DB.Exec(query, eventID, viewer, source, now.UTC(),
+		eventID, viewer, since,
+		source, eventID, source, since, MaxViewsPerSource)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// TrendingEvent is an upcoming event with its trending score and the
+// activity counted towards it.
+type TrendingEvent struct {
+	Event         Event
+	Score         float64
+	Registrations int
+	Bookmarks     int
+	Views         int
+}
+
+// GetTrendingEvents ranks upcoming events by the registrations, bookmarks and
+// views they received in the window before now. Each counts for less the
+// older it is, losing half its weight every halfLife.
+func GetTrendingEvents(window, halfLife time.Duration, limit int, now time.Time) ([]TrendingEvent, error) {
+	query := `
+	SELECT a.event_id, a.kind, (julianday(?) - julianday(a.at)) * 24
+	FROM (
+		SELECT event_id, 'registration' AS kind, registered_at AS at FROM registrations
+		WHERE status = ? AND registered_at IS NOT NULL
+		UNION ALL
+		SELECT event_id, 'bookmark', created_at FROM bookmarks
+		UNION ALL
+		SELECT event_id, 'view', viewed_at FROM event_views
+	) a
+	JOIN events e ON e.id = a.event_id
+	WHERE julianday(a.at) >= julianday(?) AND julianday(e.dateTime) > julianday(?)`
+
+	rows, err := db.DB.Query(query, now.UTC(), RegistrationConfirmed, now.Add(-window).UTC(), now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scores := map[int64]*TrendingEvent{}
+	for rows.Next() {
+		var eventID int64
+		var kind string
+		var ageHours float64
+		err = rows.Scan(&eventID, &kind, &ageHours)
+		if err != nil {
+			return nil, err
+		}
+
+		trending := scores[eventID]
+		if trending == nil {
+			trending = &TrendingEvent{Event: Event{ID: eventID}}
+			scores[eventID] = trending
+		}
+
+		decay := math.Pow(0.5, math.Max(ageHours, 0)/halfLife.Hours())
+		switch kind {
+		case "registration":
+			trending.Registrations++
+			trending.Score += registrationWeight * decay
+		case "bookmark":
+			trending.Bookmarks++
+			trending.Score += bookmarkWeight * decay
+		case "view":
+			trending.Views++
+			trending.Score += viewWeight * decay
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	ranked := make([]TrendingEvent, 0, len(scores))
+	for _, trending := range scores {
+		trending.Score = math.Round(trending.Score*1000) / 1000
+		ranked = append(ranked, *trending)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		return a.Score > b.Score || a.Score == b.Score && a.Event.ID < b.Event.ID
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	for i := range ranked {
+		event, err := GetEventByID(ranked[i].Event.ID)
+		if err != nil {
+			return nil, err
+		}
+		ranked[i].Event = *event
+	}
+	return ranked, nil
+}
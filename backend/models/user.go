@@ -1,9 +1,11 @@
 package models
 
 import (
+	"database/sql"
 	"errors"
 	"event-planner/db"
 	"event-planner/utils"
+	"strings"
 )
 
 type User struct {
@@ -61,3 +63,48 @@ func (u *User) ValidateCredentials() error {
 
 	return nil
 }
+
+func GetUserByID(id int64) (*User, error) {
+	query := "SELECT id, email FROM users WHERE id = ?"
+	row := db.DB.QueryRow(query, id)
+
+	var user User
+	err := row.Scan(&user.ID, &user.Email)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func GetUserByEmail(email string) (*User, error) {
+	query := "SELECT id, email FROM users WHERE email = ?"
+	row := db.DB.QueryRow(query, email)
+
+	var user User
+	err := row.Scan(&user.ID, &user.Email)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// IsAdmin reports whether the user may moderate site-wide content.
+func IsAdmin(userID int64) (bool, error) {
+	var admin bool
+	err := db.DB.QueryRow("SELECT is_admin FROM users WHERE id = ?", userID).Scan(&admin)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return admin, err
+}
+
+// PromoteAdmins grants admin rights to the users with the given emails.
+func PromoteAdmins(emails []string) error {
+	for _, email := range emails {
+		_, err := db.DB.Exec("UPDATE users SET is_admin = 1 WHERE email = ?", strings.TrimSpace(email))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
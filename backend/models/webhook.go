@@ -0,0 +1,288 @@
+package models
+
+import (
+	"event-planner/db"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookEndpoint is a URL an organization wants event payloads posted to.
+// The secret is only revealed once, when the endpoint is created.
+type WebhookEndpoint struct {
+	ID             int64
+	OrganizationID int64
+	URL            string   `binding:"required"`
+	EventTypes     []string `binding:"required"`
+	Secret         string   `json:"-"`
+	CreatedAt      time.Time
+}
+
+// WebhookDelivery is one payload queued for one endpoint, retried until it
+// succeeds or runs out of attempts.
+type WebhookDelivery struct {
+	ID            int64
+	EndpointID    int64
+	EventType     string
+	Payload       string
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+}
+
+// WebhookAttempt records the outcome of a single HTTP request for a delivery.
+type WebhookAttempt struct {
+	ID          int64
+	DeliveryID  int64
+	AttemptedAt time.Time
+	StatusCode  int
+	Error       string
+	DurationMs  int64
+}
+
+const webhookEndpointColumns = "id, organization_id, url, event_types, secret, created_at"
+
+func scanWebhookEndpoint(row scanner) (*WebhookEndpoint, error) {
+	var w WebhookEndpoint
+	var eventTypes string
+	err := row.Scan(&w.ID, &w.OrganizationID, &w.URL, &eventTypes, &w.Secret, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	w.EventTypes = strings.Split(eventTypes, ",")
+	return &w, nil
+}
+
+func (w *WebhookEndpoint) Save() error {
+	query := `
+	INSERT INTO webhook_endpoints (organization_id, url, event_types, secret, created_at)
+	VALUES (?, ?, ?, ?, ?)`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	w.CreatedAt = time.Now().UTC()
+	result, err := stmt.Exec(w.OrganizationID, w.URL, strings.Join(w.EventTypes, ","), w.Secret, w.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	w.ID = id
+	return err
+}
+
+// Subscribes reports whether the endpoint wants payloads of eventType.
+func (w WebhookEndpoint) Subscribes(eventType string) bool {
+	return slices.Contains(w.EventTypes, eventType)
+}
+
+// Delete removes the endpoint together with its delivery history.
+func (w WebhookEndpoint) Delete() error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		"DELETE FROM webhook_attempts WHERE delivery_id IN (SELECT id FROM webhook_deliveries WHERE endpoint_id = ?)",
+		"DELETE FROM webhook_deliveries WHERE endpoint_id = ?",
+		"DELETE FROM webhook_endpoints WHERE id = ?",
+	}
+	for _, statement := range statements {
+		_, err = tx.Exec(statement, w.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func GetWebhookEndpointByID(id int64) (*WebhookEndpoint, error) {
+	query := "SELECT " + webhookEndpointColumns + " FROM webhook_endpoints WHERE id = ?"
+	return scanWebhookEndpoint(db.DB.QueryRow(query, id))
+}
+
+func GetWebhookEndpointsForOrganization(organizationID int64) ([]WebhookEndpoint, error) {
+	query := "SELECT " + webhookEndpointColumns + " FROM webhook_endpoints WHERE organization_id = ? ORDER BY id"
+	rows, err := db.DB.Query(query, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	endpoints := []WebhookEndpoint{}
+
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		endpoints = append(endpoints, *endpoint)
+	}
+	return endpoints, rows.Err()
+}
+
+const webhookDeliveryColumns = "id, endpoint_id, event_type, payload, status, attempts, next_attempt_at, created_at"
+
+func scanWebhookDelivery(row scanner) (*WebhookDelivery, error) {
+	var d WebhookDelivery
+	err := row.Scan(&d.ID, &d.EndpointID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func queryWebhookDeliveries(query string, args ...any) ([]WebhookDelivery, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (d *WebhookDelivery) Save() error {
+	query := `
+	INSERT INTO webhook_deliveries (endpoint_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+	VALUES (?, ?, ?, ?, 0, ?, ?)`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	d.CreatedAt = time.Now().UTC()
+	d.Status = DeliveryPending
+	if d.NextAttemptAt.IsZero() {
+		d.NextAttemptAt = d.CreatedAt
+	}
+
+	result, err := stmt.Exec(d.EndpointID, d.EventType, d.Payload, d.Status, d.NextAttemptAt.UTC(), d.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	d.ID = id
+	return err
+}
+
+func GetWebhookDeliveryByID(id int64) (*WebhookDelivery, error) {
+	query := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries WHERE id = ?"
+	return scanWebhookDelivery(db.DB.QueryRow(query, id))
+}
+
+// GetWebhookDeliveries returns the most recent deliveries for an endpoint.
+func GetWebhookDeliveries(endpointID int64, limit int) ([]WebhookDelivery, error) {
+	query := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries WHERE endpoint_id = ? ORDER BY id DESC LIMIT ?"
+	return queryWebhookDeliveries(query, endpointID, limit)
+}
+
+// GetDueWebhookDeliveries returns pending deliveries whose next attempt is due.
+func GetDueWebhookDeliveries(now time.Time, limit int) ([]WebhookDelivery, error) {
+	query := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?"
+	return queryWebhookDeliveries(query, DeliveryPending, now.UTC(), limit)
+}
+
+// Claim pushes the next attempt back by lease so that no other run picks the
+// delivery up while this one is sending it. It reports false when the delivery
+// was already claimed.
+func (d WebhookDelivery) Claim(now time.Time, lease time.Duration) (bool, error) {
+	query := `
+	UPDATE webhook_deliveries
+	SET next_attempt_at = ?
+	WHERE id = ? AND status = ? AND next_attempt_at <= ?`
+
+	result, err := db.DB.Exec(query, now.Add(lease).UTC(), d.ID, DeliveryPending, now.UTC())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// RecordAttempt stores the attempt and moves the delivery to its new status.
+func (d WebhookDelivery) RecordAttempt(attempt WebhookAttempt, status string, nextAttemptAt time.Time) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+	INSERT INTO webhook_attempts (delivery_id, attempted_at, status_code, error, duration_ms)
+	VALUES (?, ?, ?, ?, ?)`, d.ID, attempt.AttemptedAt.UTC(), attempt.StatusCode, attempt.Error, attempt.DurationMs)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+	UPDATE webhook_deliveries
+	SET status = ?, attempts = attempts + 1, next_attempt_at = ?
+	WHERE id = ?`, status, nextAttemptAt.UTC(), d.ID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func GetWebhookAttempts(deliveryID int64) ([]WebhookAttempt, error) {
+	query := `
+	SELECT id, delivery_id, attempted_at, status_code, error, duration_ms
+	FROM webhook_attempts
+	WHERE delivery_id = ?
+	ORDER BY id`
+
+	rows, err := db.DB.Query(query, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := []WebhookAttempt{}
+
+	for rows.Next() {
+		var a WebhookAttempt
+		err := rows.Scan(&a.ID, &a.DeliveryID, &a.AttemptedAt, &a.StatusCode, &a.Error, &a.DurationMs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
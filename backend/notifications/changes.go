@@ -0,0 +1,129 @@
+package notifications
+
+import (
+	"event-planner/models"
+	"event-planner/utils"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const displayTimeFormat = "Mon, 02 Jan 2006 15:04 MST"
+
+// Change is one attendee-facing difference between two versions of an event.
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// EventChanges returns the changes attendees need to hear about. Edits to the
+// name or description alone are not considered meaningful.
+func EventChanges(before, after models.Event) []Change {
+	var changes []Change
+
+	if !before.DateTime.Equal(after.DateTime) {
+		changes = append(changes, Change{
+			Field: "Time",
+			Old:   before.DateTime.Format(displayTimeFormat),
+			New:   after.DateTime.Format(displayTimeFormat),
+		})
+	}
+
+	// Without an explicit end time the end just follows the start, which is
+	// already reported above
+	hasEnd := before.EndTime != nil || after.EndTime != nil
+	if hasEnd && !before.Ends().Equal(after.Ends()) {
+		changes = append(changes, Change{
+			Field: "Ends",
+			Old:   before.Ends().Format(displayTimeFormat),
+			New:   after.Ends().Format(displayTimeFormat),
+		})
+	}
+
+	if before.Location != after.Location {
+		changes = append(changes, Change{Field: "Location", Old: before.Location, New: after.Location})
+	}
+	return changes
+}
+
+// EventChanged tells every registrant what changed between before and after,
+// attaching the updated calendar entry. It does nothing when no meaningful
+// field changed.
+func EventChanged(notifier Notifier, before, after models.Event) error {
+	changes := EventChanges(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	registrants, err := after.Registrants()
+	if err != nil {
+		return err
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s has been updated:\n\n", after.Name)
+	for _, change := range changes {
+		fmt.Fprintf(&body, "- %s: %s → %s\n", change.Field, change.Old, change.New)
+	}
+
+	attachment := eventAttachment(after, false)
+	return notifyAll(notifier, registrants, Message{
+		Type:        TypeEventChanged,
+		Subject:     "Updated: " + after.Name,
+		Body:        body.String(),
+		Attachments: []Attachment{attachment},
+	})
+}
+
+// EventCancelled tells the given registrants that the event will not take
+// place. Registrants are passed in because they must be read before the event
+// is deleted.
+func EventCancelled(notifier Notifier, event models.Event, registrants []models.User) error {
+	body := fmt.Sprintf("%s, scheduled for %s in %s, has been cancelled.\n",
+		event.Name, event.DateTime.Format(displayTimeFormat), event.Location)
+
+	attachment := eventAttachment(event, true)
+	return notifyAll(notifier, registrants, Message{
+		Type:        TypeEventCancelled,
+		Subject:     "Cancelled: " + event.Name,
+		Body:        body,
+		Attachments: []Attachment{attachment},
+	})
+}
+
+func notifyAll(notifier Notifier, users []models.User, msg Message) error {
+	var errs []error
+	for _, user := range users {
+		msg.UserID = user.ID
+		msg.To = user.Email
+		err := notifier.Notify(msg)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d notifications failed: %w", len(errs), len(users), errs[0])
+	}
+	return nil
+}
+
+func eventAttachment(event models.Event, cancelled bool) Attachment {
+	ics := utils.GenerateICS(utils.ICSEvent{
+		UID:         fmt.Sprintf("event-%d@campus-event-planner", event.ID),
+		Summary:     event.Name,
+		Description: event.Description,
+		Location:    event.Location,
+		Start:       event.DateTime,
+		End:         event.Ends(),
+		Sequence:    time.Now().Unix(),
+		Cancelled:   cancelled,
+	})
+
+	return Attachment{
+		Filename:    "event.ics",
+		ContentType: "text/calendar; charset=utf-8",
+		Data:        ics,
+	}
+}
@@ -0,0 +1,118 @@
+package notifications
+
+import (
+	"event-planner/db"
+	"event-planner/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventChanges(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	before := models.Event{Name: "Talk", Description: "A", Location: "Room 1", DateTime: start}
+
+	after := before
+	after.Name = "Renamed Talk"
+	after.Description = "B"
+	assert.Empty(t, EventChanges(before, after))
+
+	after.Location = "Room 2"
+	after.DateTime = start.Add(time.Hour)
+	changes := EventChanges(before, after)
+
+	assert.Len(t, changes, 2)
+	assert.Equal(t, "Time", changes[0].Field)
+	assert.Equal(t, "Sun, 01 Mar 2026 10:00 UTC", changes[0].Old)
+	assert.Equal(t, "Sun, 01 Mar 2026 11:00 UTC", changes[0].New)
+	assert.Equal(t, Change{Field: "Location", Old: "Room 1", New: "Room 2"}, changes[1])
+}
+
+func TestEventChanges_EndTime(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	before := models.Event{Name: "Talk", Location: "Room 1", DateTime: start, EndTime: &end}
+
+	later := end.Add(time.Hour)
+	after := before
+	after.EndTime = &later
+	changes := EventChanges(before, after)
+
+	assert.Equal(t, []Change{{Field: "Ends", Old: "Sun, 01 Mar 2026 12:00 UTC", New: "Sun, 01 Mar 2026 13:00 UTC"}}, changes)
+	assert.Contains(t, string(eventAttachment(after, false).Data), "DTEND:20260301T130000Z")
+}
+
+func TestEventChanged_NotifiesRegistrants(t *testing.T) {
+	before := createRegisteredEvent(t, time.Now().Add(96*time.Hour))
+	notifier := &recordingNotifier{}
+
+	after := before
+	after.Name = "Renamed"
+	err := EventChanged(notifier, before, after)
+	assert.NoError(t, err)
+	assert.Empty(t, notifier.messages)
+
+	after.Location = "Main Hall"
+	err = EventChanged(notifier, before, after)
+	assert.NoError(t, err)
+	assert.Len(t, notifier.messages, 1)
+
+	msg := notifier.messages[0]
+	assert.Equal(t, TypeEventChanged, msg.Type)
+	assert.Equal(t, "student@example.com", msg.To)
+	assert.Contains(t, msg.Body, "- Location: Room 1 → Main Hall")
+	assert.Len(t, msg.Attachments, 1)
+	assert.Contains(t, string(msg.Attachments[0].Data), "LOCATION:Main Hall")
+	assert.Contains(t, string(msg.Attachments[0].Data), "METHOD:REQUEST")
+}
+
+func TestEventCancelled_AttachesCancellation(t *testing.T) {
+	event := models.Event{ID: 7, Name: "Concert", Location: "Quad", DateTime: time.Now()}
+	registrants := []models.User{{ID: 1, Email: "a@example.com"}, {ID: 2, Email: "b@example.com"}}
+	notifier := &recordingNotifier{}
+
+	err := EventCancelled(notifier, event, registrants)
+
+	assert.NoError(t, err)
+	assert.Len(t, notifier.messages, 2)
+	assert.Equal(t, TypeEventCancelled, notifier.messages[1].Type)
+	assert.Equal(t, "b@example.com", notifier.messages[1].To)
+	assert.Contains(t, string(notifier.messages[1].Attachments[0].Data), "METHOD:CANCEL")
+}
+
+func TestInAppNotifier_StoresNotification(t *testing.T) {
+	err := InAppNotifier{}.Notify(Message{UserID: 1, Type: TypeEventChanged, Subject: "Updated: Talk", Body: "Details"})
+	assert.NoError(t, err)
+
+	var title string
+	err = db.DB.QueryRow("SELECT title FROM notifications WHERE user_id = 1 ORDER BY id DESC").Scan(&title)
+	assert.NoError(t, err)
+	assert.Equal(t, "Updated: Talk", title)
+}
+
+func TestBuildEmail_WithAttachment(t *testing.T) {
+	msg := Message{
+		To:          "a@example.com",
+		Subject:     "Updated: Talk",
+		Body:        "Details",
+		Attachments: []Attachment{{Filename: "event.ics", ContentType: "text/calendar", Data: []byte("BEGIN:VCALENDAR")}},
+	}
+
+	email, err := buildEmail("planner@example.com", msg)
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(email), "To: <a@example.com>\r\n")
+	assert.Contains(t, string(email), "Content-Type: multipart/mixed; boundary=")
+	assert.Contains(t, string(email), `filename="event.ics"`)
+	assert.True(t, strings.Contains(string(email), "QkVHSU46VkNBTEVOREFS"))
+}
+
+func TestBuildEmail_RejectsHeaderInjection(t *testing.T) {
+	msg := Message{To: "a@example.com\r\nBcc: b@example.com", Subject: "Hi", Body: "Details"}
+
+	_, err := buildEmail("planner@example.com", msg)
+
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
@@ -0,0 +1,347 @@
+package notifications
+
+import (
+	"errors"
+	"event-planner/models"
+	"event-planner/utils"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Delivery channels a user can set preferences for.
+const (
+	ChannelEmail   = "email"
+	ChannelInApp   = "in_app"
+	ChannelSMS     = "sms"
+	ChannelWebhook = "webhook"
+)
+
+// Channels lists every delivery channel in the order they are tried.
+var Channels = []string{ChannelEmail, ChannelInApp, ChannelSMS, ChannelWebhook}
+
+// AllTypes is used in unsubscribe links that turn off a channel entirely,
+// such as the one at the bottom of a digest.
+const AllTypes = "*"
+
+// DigestClock is the local time of day at which daily digests go out.
+const DigestClock = "08:00"
+
+// DefaultPreference is used when the user has not saved a preference. Email and
+// in-app are on by default; SMS and webhooks need the user to opt in.
+func DefaultPreference(notificationType, channel string) models.NotificationPreference {
+	return models.NotificationPreference{
+		Type:      notificationType,
+		Channel:   channel,
+		Enabled:   channel == ChannelEmail || channel == ChannelInApp,
+		Frequency: models.FrequencyImmediate,
+	}
+}
+
+// ValidatePreference rejects unknown types, channels and frequencies.
+func ValidatePreference(preference models.NotificationPreference) error {
+	if !slices.Contains(Types, preference.Type) {
+		return fmt.Errorf("unknown notification type %q", preference.Type)
+	}
+	if !slices.Contains(Channels, preference.Channel) {
+		return fmt.Errorf("unknown notification channel %q", preference.Channel)
+	}
+	if preference.Frequency != models.FrequencyImmediate && preference.Frequency != models.FrequencyDigest {
+		return fmt.Errorf("frequency must be %q or %q", models.FrequencyImmediate, models.FrequencyDigest)
+	}
+	return nil
+}
+
+// PreferencesFor returns the user's effective preference for every type and
+// channel, filling in defaults for combinations they never changed.
+func PreferencesFor(userID int64) ([]models.NotificationPreference, error) {
+	saved, err := models.GetNotificationPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	savedByKey := map[string]models.NotificationPreference{}
+	for _, preference := range saved {
+		savedByKey[preference.Type+"|"+preference.Channel] = preference
+	}
+
+	var preferences []models.NotificationPreference
+	for _, notificationType := range Types {
+		for _, channel := range Channels {
+			preference, ok := savedByKey[notificationType+"|"+channel]
+			if !ok {
+				preference = DefaultPreference(notificationType, channel)
+			}
+			preferences = append(preferences, preference)
+		}
+	}
+	return preferences, nil
+}
+
+// Dispatcher routes each message to the user's channels according to their
+// preferences. Digest messages and messages arriving during quiet hours are
+// queued and sent later by SendQueued. In-app notifications are never held
+// back by quiet hours since they make no noise.
+type Dispatcher struct {
+	Channels map[string]Notifier
+	// BaseURL is the public address of the API, used for unsubscribe links.
+	BaseURL string
+	Now     func() time.Time
+}
+
+// ChannelError is a failed delivery on one channel. Dispatcher.Notify joins
+// one per failed channel so callers can retry just those.
+type ChannelError struct {
+	Channel string
+	Err     error
+}
+
+func (e *ChannelError) Error() string {
+	return e.Channel + ": " + e.Err.Error()
+}
+
+func (e *ChannelError) Unwrap() error {
+	return e.Err
+}
+
+// FailedChannels returns the channels err reports failures on. It reports
+// false when err is not made up of ChannelErrors only, as when the message
+// could not be routed at all.
+func FailedChannels(err error) ([]string, bool) {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	channels := []string{}
+	for _, err := range errs {
+		var channelErr *ChannelError
+		if !errors.As(err, &channelErr) {
+			return nil, false
+		}
+		channels = append(channels, channelErr.Channel)
+	}
+	return channels, true
+}
+
+func (d *Dispatcher) Notify(msg Message) error {
+	settings, err := models.GetNotificationSettings(msg.UserID)
+	if err != nil {
+		return err
+	}
+
+	now := d.now()
+	var errs []error
+	for _, channel := range Channels {
+		notifier, ok := d.Channels[channel]
+		if !ok || (len(msg.Channels) > 0 && !slices.Contains(msg.Channels, channel)) {
+			continue
+		}
+
+		preference, err := models.GetNotificationPreference(msg.UserID, msg.Type, channel, DefaultPreference(msg.Type, channel))
+		if err != nil {
+			errs = append(errs, &ChannelError{Channel: channel, Err: err})
+			continue
+		}
+
+		if !preference.Enabled {
+			continue
+		}
+
+		if preference.Frequency == models.FrequencyDigest || msg.Digest {
+			err = queue(channel, msg, true, settings.NextLocalClock(now, DigestClock))
+		} else if until, quiet := settings.QuietUntil(now); quiet && channel != ChannelInApp {
+			err = queue(channel, msg, false, until)
+		} else {
+			err = d.deliver(notifier, channel, *settings, msg)
+		}
+
+		if err != nil {
+			errs = append(errs, &ChannelError{Channel: channel, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SendQueued delivers queued messages that are due. Messages held for quiet
+// hours are sent one by one; digest messages for the same user and channel are
+// combined into a single message.
+func (d *Dispatcher) SendQueued(now time.Time) error {
+	queued, err := models.GetDueQueuedNotifications(now)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	var digest []models.QueuedNotification
+
+	flush := func() {
+		if len(digest) == 0 {
+			return
+		}
+		err := d.deliverQueued(digest[0].UserID, digest[0].Channel, digestMessage(digest))
+		if err != nil {
+			errs = append(errs, err)
+		}
+		digest = nil
+	}
+
+	for _, q := range queued {
+		if len(digest) > 0 && (q.UserID != digest[0].UserID || q.Channel != digest[0].Channel) {
+			flush()
+		}
+
+		claimed, err := q.Claim()
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			continue
+		}
+
+		if q.Digest {
+			digest = append(digest, q)
+			continue
+		}
+
+		msg := Message{UserID: q.UserID, Type: q.Type, Subject: q.Subject, Body: q.Body, Attachments: unqueueAttachments(q.Attachments)}
+		err = d.deliverQueued(q.UserID, q.Channel, msg)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	flush()
+
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) deliverQueued(userID int64, channel string, msg Message) error {
+	notifier, ok := d.Channels[channel]
+	if !ok {
+		return nil
+	}
+
+	settings, err := models.GetNotificationSettings(userID)
+	if err != nil {
+		return err
+	}
+	return d.deliver(notifier, channel, *settings, msg)
+}
+
+// deliver addresses msg for the channel and hands it to the notifier. Channels
+// the user has not given an address for are skipped.
+func (d *Dispatcher) deliver(notifier Notifier, channel string, settings models.NotificationSettings, msg Message) error {
+	msg.UserID = settings.UserID
+
+	switch channel {
+	case ChannelEmail:
+		msg.To = settings.Email
+		msg.UnsubscribeURL = d.UnsubscribeURL(settings.UserID, msg.Type, channel)
+	case ChannelSMS:
+		msg.To = settings.Phone
+	case ChannelWebhook:
+		msg.To = settings.WebhookURL
+	}
+
+	if msg.To == "" && channel != ChannelInApp {
+		return nil
+	}
+	return notifier.Notify(msg)
+}
+
+// UnsubscribeURL returns a signed link that turns off this type of message on
+// the channel without logging in. Digests unsubscribe from the whole channel.
+func (d *Dispatcher) UnsubscribeURL(userID int64, notificationType, channel string) string {
+	if notificationType == TypeDigest {
+		notificationType = AllTypes
+	}
+
+	token := utils.GenerateUnsubscribeToken(userID, notificationType, channel)
+	return strings.TrimRight(d.BaseURL, "/") + "/unsubscribe?token=" + url.QueryEscape(token)
+}
+
+func (d *Dispatcher) now() time.Time {
+	if d.Now != nil {
+		return d.Now()
+	}
+	return time.Now()
+}
+
+func queue(channel string, msg Message, digest bool, deliverAfter time.Time) error {
+	attachments := make([]models.QueuedAttachment, len(msg.Attachments))
+	for i, attachment := range msg.Attachments {
+		attachments[i] = models.QueuedAttachment{Filename: attachment.Filename, ContentType: attachment.ContentType, Data: attachment.Data}
+	}
+
+	queued := models.QueuedNotification{
+		UserID:       msg.UserID,
+		Channel:      channel,
+		Type:         msg.Type,
+		Subject:      msg.Subject,
+		Body:         msg.Body,
+		Attachments:  attachments,
+		Digest:       digest,
+		DeliverAfter: deliverAfter,
+	}
+	return queued.Save()
+}
+
+func unqueueAttachments(queued []models.QueuedAttachment) []Attachment {
+	var attachments []Attachment
+	for _, attachment := range queued {
+		attachments = append(attachments, Attachment{Filename: attachment.Filename, ContentType: attachment.ContentType, Data: attachment.Data})
+	}
+	return attachments
+}
+
+// digestMessage combines the queued messages into one, keeping all of their
+// attachments.
+func digestMessage(queued []models.QueuedNotification) Message {
+	var body strings.Builder
+	var attachments []Attachment
+	for i, q := range queued {
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		fmt.Fprintf(&body, "%s\n%s\n", q.Subject, q.Body)
+		attachments = append(attachments, unqueueAttachments(q.Attachments)...)
+	}
+
+	return Message{
+		Type:        TypeDigest,
+		Subject:     fmt.Sprintf("Your daily digest: %d updates", len(queued)),
+		Body:        body.String(),
+		Attachments: attachments,
+	}
+}
+
+// Unsubscribe turns off the notification type on the channel for the user.
+// AllTypes turns off every type on that channel.
+func Unsubscribe(userID int64, notificationType, channel string) error {
+	types := []string{notificationType}
+	if notificationType == AllTypes {
+		types = Types
+	}
+
+	for _, t := range types {
+		preference, err := models.GetNotificationPreference(userID, t, channel, DefaultPreference(t, channel))
+		if err != nil {
+			return err
+		}
+
+		preference.Enabled = false
+		err = ValidatePreference(preference)
+		if err != nil {
+			return err
+		}
+
+		err = preference.Save(userID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package notifications
+
+import (
+	"event-planner/db"
+	"event-planner/models"
+	"event-planner/utils"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createUser(t *testing.T, email string) int64 {
+	result, err := db.DB.Exec("INSERT INTO users (email, password) VALUES (?, 'x')", email)
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	id, _ := result.LastInsertId()
+	return id
+}
+
+func newTestDispatcher(now time.Time) (*Dispatcher, *recordingNotifier, *recordingNotifier) {
+	email := &recordingNotifier{}
+	inApp := &recordingNotifier{}
+	dispatcher := &Dispatcher{
+		Channels: map[string]Notifier{ChannelEmail: email, ChannelInApp: inApp, ChannelSMS: &recordingNotifier{}},
+		BaseURL:  "https://events.example.com/",
+		Now:      func() time.Time { return now },
+	}
+	return dispatcher, email, inApp
+}
+
+func TestDispatcher_DeliversImmediatelyByDefault(t *testing.T) {
+	userID := createUser(t, "default@example.com")
+	dispatcher, email, inApp := newTestDispatcher(time.Now())
+
+	err := dispatcher.Notify(Message{UserID: userID, Type: TypeEventChanged, Subject: "Updated", Body: "Body"})
+
+	assert.NoError(t, err)
+	assert.Len(t, email.messages, 1)
+	assert.Len(t, inApp.messages, 1)
+	assert.Equal(t, "default@example.com", email.messages[0].To)
+
+	// The unsubscribe link is signed for exactly this type and channel
+	link, _ := url.Parse(email.messages[0].UnsubscribeURL)
+	assert.Equal(t, "/unsubscribe", link.Path)
+	tokenUser, tokenType, tokenChannel, err := utils.VerifyUnsubscribeToken(link.Query().Get("token"))
+	assert.NoError(t, err)
+	assert.Equal(t, userID, tokenUser)
+	assert.Equal(t, TypeEventChanged, tokenType)
+	assert.Equal(t, ChannelEmail, tokenChannel)
+}
+
+func TestDispatcher_RespectsDisabledChannel(t *testing.T) {
+	userID := createUser(t, "disabled@example.com")
+	err := Unsubscribe(userID, TypeEventChanged, ChannelEmail)
+	assert.NoError(t, err)
+
+	dispatcher, email, inApp := newTestDispatcher(time.Now())
+	err = dispatcher.Notify(Message{UserID: userID, Type: TypeEventChanged, Subject: "Updated"})
+	assert.NoError(t, err)
+	assert.Empty(t, email.messages)
+	assert.Len(t, inApp.messages, 1)
+
+	// Other types still arrive by email
+	err = dispatcher.Notify(Message{UserID: userID, Type: TypeReminder, Subject: "Reminder"})
+	assert.NoError(t, err)
+	assert.Len(t, email.messages, 1)
+}
+
+func TestDispatcher_HoldsEmailDuringQuietHours(t *testing.T) {
+	userID := createUser(t, "quiet@example.com")
+	settings := models.NotificationSettings{
+		UserID:          userID,
+		TimeZone:        "America/New_York",
+		QuietHoursStart: "22:00",
+		QuietHoursEnd:   "07:00",
+	}
+	assert.NoError(t, settings.Save())
+
+	// 23:30 in New York
+	location, _ := time.LoadLocation("America/New_York")
+	night := time.Date(2026, 3, 10, 23, 30, 0, 0, location)
+	dispatcher, email, inApp := newTestDispatcher(night)
+
+	attachment := Attachment{Filename: "event.ics", ContentType: "text/calendar", Data: []byte("BEGIN:VCALENDAR")}
+	err := dispatcher.Notify(Message{UserID: userID, Type: TypeEventChanged, Subject: "Updated", Body: "Body", Attachments: []Attachment{attachment}})
+	assert.NoError(t, err)
+	assert.Empty(t, email.messages)
+	assert.Len(t, inApp.messages, 1)
+
+	err = dispatcher.SendQueued(night.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, email.messages)
+
+	morning := time.Date(2026, 3, 11, 7, 0, 0, 0, location)
+	err = dispatcher.SendQueued(morning)
+	assert.NoError(t, err)
+	assert.Len(t, email.messages, 1)
+	assert.Equal(t, "Updated", email.messages[0].Subject)
+	assert.Equal(t, []Attachment{attachment}, email.messages[0].Attachments)
+
+	// Already delivered messages are not sent again
+	err = dispatcher.SendQueued(morning)
+	assert.NoError(t, err)
+	assert.Len(t, email.messages, 1)
+}
+
+func TestDispatcher_CombinesDigest(t *testing.T) {
+	userID := createUser(t, "digest@example.com")
+	for _, notificationType := range []string{TypeEventChanged, TypeRegistered} {
+		preference := DefaultPreference(notificationType, ChannelEmail)
+		preference.Frequency = models.FrequencyDigest
+		assert.NoError(t, preference.Save(userID))
+	}
+
+	noon := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	dispatcher, email, _ := newTestDispatcher(noon)
+
+	attachment := Attachment{Filename: "event.ics", ContentType: "text/calendar", Data: []byte("BEGIN:VCALENDAR")}
+	assert.NoError(t, dispatcher.Notify(Message{UserID: userID, Type: TypeEventChanged, Subject: "Updated: Talk", Body: "Moved", Attachments: []Attachment{attachment}}))
+	assert.NoError(t, dispatcher.Notify(Message{UserID: userID, Type: TypeRegistered, Subject: "Registered: Fair", Body: "See you"}))
+	assert.Empty(t, email.messages)
+
+	err := dispatcher.SendQueued(time.Date(2026, 3, 11, 8, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Len(t, email.messages, 1)
+
+	digest := email.messages[0]
+	assert.Equal(t, TypeDigest, digest.Type)
+	assert.Contains(t, digest.Subject, "2 updates")
+	assert.Contains(t, digest.Body, "Updated: Talk")
+	assert.Contains(t, digest.Body, "Registered: Fair")
+	assert.Equal(t, []Attachment{attachment}, digest.Attachments)
+
+	link, _ := url.Parse(digest.UnsubscribeURL)
+	_, tokenType, _, _ := utils.VerifyUnsubscribeToken(link.Query().Get("token"))
+	assert.Equal(t, AllTypes, tokenType)
+}
+
+func TestDispatcher_QueuesDigestMessages(t *testing.T) {
+	userID := createUser(t, "search-digest-queue@example.com")
+	noon := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	dispatcher, email, inApp := newTestDispatcher(noon)
+
+	// The sender asks for the digest even though the preference is immediate
+	err := dispatcher.Notify(Message{UserID: userID, Type: TypeSavedSearch, Subject: "New event: Talk", Digest: true})
+	assert.NoError(t, err)
+	assert.Empty(t, email.messages)
+	assert.Empty(t, inApp.messages)
+
+	assert.NoError(t, dispatcher.SendQueued(time.Date(2026, 3, 11, 8, 0, 0, 0, time.UTC)))
+	if assert.Len(t, email.messages, 1) {
+		assert.Contains(t, email.messages[0].Body, "New event: Talk")
+	}
+}
+
+func TestDispatcher_SkipsChannelsWithoutAddress(t *testing.T) {
+	userID := createUser(t, "sms@example.com")
+	preference := DefaultPreference(TypeReminder, ChannelSMS)
+	preference.Enabled = true
+	assert.NoError(t, preference.Save(userID))
+
+	sms := &recordingNotifier{}
+	dispatcher := &Dispatcher{Channels: map[string]Notifier{ChannelSMS: sms}}
+
+	assert.NoError(t, dispatcher.Notify(Message{UserID: userID, Type: TypeReminder}))
+	assert.Empty(t, sms.messages)
+
+	settings := models.NotificationSettings{UserID: userID, TimeZone: "UTC", Phone: "+15550100"}
+	assert.NoError(t, settings.Save())
+
+	assert.NoError(t, dispatcher.Notify(Message{UserID: userID, Type: TypeReminder}))
+	assert.Len(t, sms.messages, 1)
+	assert.Equal(t, "+15550100", sms.messages[0].To)
+}
+
+func TestQuietUntil(t *testing.T) {
+	settings := models.NotificationSettings{TimeZone: "UTC", QuietHoursStart: "22:00", QuietHoursEnd: "07:00"}
+	day := func(hour, minute int) time.Time { return time.Date(2026, 3, 10, hour, minute, 0, 0, time.UTC) }
+
+	until, quiet := settings.QuietUntil(day(23, 0))
+	assert.True(t, quiet)
+	assert.Equal(t, time.Date(2026, 3, 11, 7, 0, 0, 0, time.UTC), until)
+
+	until, quiet = settings.QuietUntil(day(6, 59))
+	assert.True(t, quiet)
+	assert.Equal(t, day(7, 0), until)
+
+	_, quiet = settings.QuietUntil(day(12, 0))
+	assert.False(t, quiet)
+
+	daytime := models.NotificationSettings{TimeZone: "UTC", QuietHoursStart: "09:00", QuietHoursEnd: "17:00"}
+	_, quiet = daytime.QuietUntil(day(8, 0))
+	assert.False(t, quiet)
+	until, quiet = daytime.QuietUntil(day(9, 0))
+	assert.True(t, quiet)
+	assert.Equal(t, day(17, 0), until)
+}
+
+func TestWebhookNotifier_RefusesInternalAddresses(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	err := WebhookNotifier{}.Notify(Message{To: server.URL, Subject: "Hi"})
+
+	assert.ErrorIs(t, err, utils.ErrInternalAddress)
+	assert.Zero(t, requests)
+}
@@ -0,0 +1,132 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+)
+
+// ErrInvalidAddress is returned for sender or recipient addresses containing
+// line breaks, which would let them inject extra headers.
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// EmailNotifier sends messages over SMTP.
+type EmailNotifier struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// EmailNotifierFromEnv reads the SMTP_* environment variables. It reports false
+// when SMTP_HOST is not set.
+func EmailNotifierFromEnv() (EmailNotifier, bool) {
+	notifier := EmailNotifier{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+
+	if notifier.Port == "" {
+		notifier.Port = "587"
+	}
+	if notifier.From == "" {
+		notifier.From = "no-reply@campus-event-planner.local"
+	}
+	return notifier, notifier.Host != ""
+}
+
+func (n EmailNotifier) Notify(msg Message) error {
+	if msg.To == "" {
+		return nil
+	}
+
+	body, err := buildEmail(n.From, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if n.Username != "" {
+		auth = smtp.PlainAuth("", n.Username, n.Password, n.Host)
+	}
+	return smtp.SendMail(n.Host+":"+n.Port, auth, n.From, []string{msg.To}, body)
+}
+
+// buildEmail renders msg as a MIME message. Messages with attachments are sent
+// as multipart/mixed with the text body as the first part.
+func buildEmail(from string, msg Message) ([]byte, error) {
+	if strings.ContainsAny(from, "\r\n") || strings.ContainsAny(msg.To, "\r\n") {
+		return nil, ErrInvalidAddress
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", (&mail.Address{Address: msg.To}).String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	text := msg.Body
+	if msg.UnsubscribeURL != "" {
+		// RFC 8058 one-click unsubscribe, plus a visible link for people
+		fmt.Fprintf(&buf, "List-Unsubscribe: <%s>\r\n", msg.UnsubscribeURL)
+		buf.WriteString("List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+		text += "\n\n--\nDon't want these emails? Unsubscribe: " + msg.UnsubscribeURL + "\n"
+	}
+
+	if len(msg.Attachments) == 0 {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		buf.WriteString(text)
+		return buf.Bytes(), nil
+	}
+
+	writer := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", writer.Boundary())
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"text/plain; charset=utf-8"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	part.Write([]byte(text))
+
+	for _, attachment := range msg.Attachments {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", attachment.Filename)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		part.Write([]byte(wrapBase64(attachment.Data)))
+	}
+
+	err = writer.Close()
+	return buf.Bytes(), err
+}
+
+func wrapBase64(data []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	var b strings.Builder
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return b.String()
+}
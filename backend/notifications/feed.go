@@ -0,0 +1,92 @@
+package notifications
+
+import (
+	"event-planner/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FeedReminderLead is how long before an event starts that bookmarkers and
+// registrants get a reminder in their feed.
+const FeedReminderLead = 24 * time.Hour
+
+// FeedEventCreated adds the new event to the feeds of everyone following its
+// organizer or organization.
+func FeedEventCreated(event models.Event) error {
+	followers, err := event.Followers()
+	if err != nil {
+		return err
+	}
+
+	return models.AddFeedItem(followers, models.FeedItem{
+		Kind:      models.FeedNewEvent,
+		EventID:   event.ID,
+		EventName: event.Name,
+		Message: fmt.Sprintf("New event: %s on %s in %s",
+			event.Name, event.DateTime.Format(displayTimeFormat), event.Location),
+	})
+}
+
+// FeedEventChanged tells the event's bookmarkers what changed between before
+// and after. It does nothing when no meaningful field changed.
+func FeedEventChanged(before, after models.Event) error {
+	changes := EventChanges(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	bookmarkers, err := after.Bookmarkers()
+	if err != nil {
+		return err
+	}
+
+	described := make([]string, len(changes))
+	for i, change := range changes {
+		described[i] = fmt.Sprintf("%s: %s → %s", change.Field, change.Old, change.New)
+	}
+
+	return models.AddFeedItem(bookmarkers, models.FeedItem{
+		Kind:      models.FeedEventChanged,
+		EventID:   after.ID,
+		EventName: after.Name,
+		Message:   fmt.Sprintf("%s has been updated. %s", after.Name, strings.Join(described, "; ")),
+	})
+}
+
+// FeedEventCancelled tells the given bookmarkers that the event will not take
+// place. Bookmarkers are passed in because they must be read before the event
+// is deleted.
+func FeedEventCancelled(event models.Event, bookmarkers []int64) error {
+	return models.AddFeedItem(bookmarkers, models.FeedItem{
+		Kind:      models.FeedEventCancelled,
+		EventID:   event.ID,
+		EventName: event.Name,
+		Message: fmt.Sprintf("%s, scheduled for %s, has been cancelled",
+			event.Name, event.DateTime.Format(displayTimeFormat)),
+	})
+}
+
+// AddFeedReminders puts a reminder in the feed of everyone who bookmarked or
+// registered for an event starting within FeedReminderLead of now. Each user
+// gets one reminder per event however often the job runs.
+func AddFeedReminders(now time.Time) error {
+	due, err := models.GetFeedRemindersDue(now, FeedReminderLead)
+	if err != nil {
+		return err
+	}
+
+	for _, reminder := range due {
+		err = models.AddFeedItem([]int64{reminder.UserID}, models.FeedItem{
+			Kind:      models.FeedReminder,
+			EventID:   reminder.Event.ID,
+			EventName: reminder.Event.Name,
+			Message: fmt.Sprintf("%s starts at %s in %s",
+				reminder.Event.Name, reminder.Event.DateTime.Format(displayTimeFormat), reminder.Event.Location),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package notifications
+
+import "event-planner/models"
+
+// InAppNotifier stores messages in the notifications table so the frontend can
+// show them to the user.
+type InAppNotifier struct{}
+
+func (InAppNotifier) Notify(msg Message) error {
+	notification := models.Notification{
+		UserID: msg.UserID,
+		Type:   msg.Type,
+		Title:  msg.Subject,
+		Body:   msg.Body,
+	}
+	return notification.Save()
+}
@@ -0,0 +1,71 @@
+package notifications
+
+import (
+	"event-planner/models"
+	"fmt"
+	"log"
+	"time"
+)
+
+// DrawLotteries draws every lottery that has closed and tells each entrant
+// whether they got a seat. A lottery is claimed as it is drawn, so outcomes
+// go out only once.
+func DrawLotteries(notifier Notifier, now time.Time) error {
+	lotteries, err := models.GetLotteriesDue(now)
+	if err != nil {
+		return err
+	}
+
+	for _, lottery := range lotteries {
+		event, err := models.GetEventByID(lottery.EventID)
+		if err != nil {
+			log.Printf("lottery for event %d has no event: %v", lottery.EventID, err)
+			continue
+		}
+
+		drawn, err := lottery.Draw(*event, now)
+		if err != nil {
+			return err
+		}
+
+		if !drawn {
+			continue
+		}
+
+		entries, err := lottery.Entries()
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			err = notifier.Notify(lotteryOutcome(*event, entry))
+			if err != nil {
+				log.Printf("could not send lottery outcome to user %d for event %d: %v", entry.UserID, event.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func lotteryOutcome(event models.Event, entry models.LotteryEntry) Message {
+	if entry.Outcome == models.LotteryWon {
+		return Message{
+			UserID:  entry.UserID,
+			To:      entry.Email,
+			Type:    TypeLotteryResult,
+			Subject: "You got a seat: " + event.Name,
+			Body: fmt.Sprintf("Your lottery entry %d was drawn. You are registered for %s on %s in %s.\n",
+				entry.ID, event.Name, event.DateTime.Format(displayTimeFormat), event.Location),
+			Attachments: []Attachment{eventAttachment(event, false)},
+		}
+	}
+
+	return Message{
+		UserID:  entry.UserID,
+		To:      entry.Email,
+		Type:    TypeLotteryResult,
+		Subject: "Lottery results: " + event.Name,
+		Body: fmt.Sprintf("Your lottery entry %d for %s was not drawn this time.\nSeats that free up are open to everyone on the event page.\n",
+			entry.ID, event.Name),
+	}
+}
@@ -0,0 +1,107 @@
+package notifications
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"event-planner/db"
+	"event-planner/models"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawLotteries_FillsCapacityInSeededOrder(t *testing.T) {
+	now := time.Now()
+	event := models.Event{
+		Name:        "Career Fair",
+		Description: "Test Description",
+		Location:    "Gym",
+		// Far enough out that its reminders never fall due in other tests
+		DateTime: now.Add(30 * 24 * time.Hour),
+		UserID:   1,
+		Capacity: 2,
+	}
+	err := event.Save()
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+
+	lottery := models.Lottery{
+		EventID:        event.ID,
+		OpensAt:        now.Add(-time.Hour),
+		ClosesAt:       now.Add(time.Hour),
+		PriorityGroups: []models.PriorityGroup{{Name: "Seniors", Emails: []string{"Senior@example.com"}}},
+	}
+	assert.NoError(t, lottery.Validate(event))
+	err = lottery.Save()
+	if err != nil {
+		t.Fatalf("Failed to save lottery: %v", err)
+	}
+
+	var userIDs []int64
+	for _, email := range []string{"entrant-1@example.com", "entrant-2@example.com", "entrant-3@example.com", "senior@example.com"} {
+		result, err := db.DB.Exec("INSERT INTO users (email, password) VALUES (?, 'x')", email)
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+		userID, _ := result.LastInsertId()
+		userIDs = append(userIDs, userID)
+
+		_, err = lottery.Enter(event, userID, 0, nil, now)
+		assert.NoError(t, err)
+	}
+
+	_, err = lottery.Enter(event, userIDs[0], 0, nil, now)
+	assert.ErrorIs(t, err, models.ErrAlreadyEntered)
+
+	notifier := &recordingNotifier{}
+
+	// The draw waits for the entry window to close
+	err = DrawLotteries(notifier, now)
+	assert.NoError(t, err)
+	assert.Empty(t, notifier.messages)
+
+	closed := lottery.ClosesAt
+	err = DrawLotteries(notifier, closed)
+	assert.NoError(t, err)
+	assert.Len(t, notifier.messages, 4)
+
+	err = DrawLotteries(notifier, closed.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, notifier.messages, 4)
+
+	drawn, err := models.GetLottery(event.ID)
+	if err != nil {
+		t.Fatalf("Failed to fetch lottery: %v", err)
+	}
+	// The draw used the seed committed to when the lottery was saved
+	assert.Len(t, drawn.Seed, 64)
+	seedHash := sha256.Sum256([]byte(drawn.Seed))
+	assert.Equal(t, lottery.SeedHash, hex.EncodeToString(seedHash[:]))
+
+	results, err := drawn.Results()
+	assert.NoError(t, err)
+	if !assert.Len(t, results, 4) {
+		return
+	}
+
+	// The priority group is drawn first, then everyone else by their key
+	assert.Equal(t, "Seniors", results[0].PriorityGroup)
+	assert.Equal(t, []string{models.LotteryWon, models.LotteryWon, models.LotteryLost, models.LotteryLost},
+		[]string{results[0].Outcome, results[1].Outcome, results[2].Outcome, results[3].Outcome})
+
+	rest := []int64{results[1].EntryID, results[2].EntryID, results[3].EntryID}
+	drawKey := func(entryID int64) string {
+		sum := sha256.Sum256([]byte(drawn.Seed + ":" + strconv.FormatInt(entryID, 10)))
+		return hex.EncodeToString(sum[:])
+	}
+	assert.True(t, sort.SliceIsSorted(rest, func(i, j int) bool { return drawKey(rest[i]) < drawKey(rest[j]) }))
+
+	senior, _ := event.IsRegistered(userIDs[3])
+	assert.True(t, senior)
+	seats, _ := event.Seats()
+	assert.Equal(t, 2, seats.Registered)
+}
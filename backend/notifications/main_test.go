@@ -0,0 +1,210 @@
+package notifications
+
+import (
+	"database/sql"
+	"event-planner/db"
+	"os"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type recordingNotifier struct {
+	messages []Message
+	err      error
+}
+
+func (n *recordingNotifier) Notify(msg Message) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+func TestMain(m *testing.M) {
+	var err error
+	db.DB, err = sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		panic(err)
+	}
+	db.DB.SetMaxOpenConns(1)
+
+	createTables := `
+	CREATE TABLE users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		email TEXT NOT NULL UNIQUE,
+		password TEXT NOT NULL,
+		timezone TEXT NOT NULL DEFAULT 'UTC'
+	);
+	CREATE TABLE events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		description TEXT NOT NULL,
+		location TEXT NOT NULL,
+		dateTime DATETIME NOT NULL,
+		userID INTEGER,
+		organization_id INTEGER,
+		capacity INTEGER NOT NULL DEFAULT 0,
+		end_time DATETIME,
+		guest_limit INTEGER NOT NULL DEFAULT 0,
+		category_id INTEGER,
+		recurrence TEXT,
+		latitude REAL,
+		longitude REAL
+	);
+	CREATE TABLE categories (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		slug TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		parent_id INTEGER
+	);
+	CREATE TABLE event_tags (
+		event_id INTEGER NOT NULL,
+		tag TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		PRIMARY KEY (event_id, tag)
+	);
+	CREATE TABLE feed_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		event_id INTEGER NOT NULL,
+		event_name TEXT NOT NULL,
+		message TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE saved_searches (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		query TEXT NOT NULL,
+		categories TEXT NOT NULL,
+		tags TEXT NOT NULL,
+		frequency TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE saved_search_queue (
+		event_id INTEGER PRIMARY KEY
+	);
+	CREATE TABLE follows (
+		user_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		target_id INTEGER NOT NULL,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (user_id, kind, target_id)
+	);
+	CREATE TABLE bookmarks (
+		user_id INTEGER NOT NULL,
+		event_id INTEGER NOT NULL,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (user_id, event_id)
+	);
+	CREATE TABLE registrations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER,
+		user_id INTEGER,
+		checked_in_at DATETIME,
+		answers TEXT NOT NULL DEFAULT '{}',
+		status TEXT NOT NULL DEFAULT 'confirmed',
+		guests INTEGER NOT NULL DEFAULT 0,
+		group_id INTEGER,
+		ticket_token TEXT,
+		registered_at DATETIME
+	);
+	CREATE TABLE registration_groups (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		leader_id INTEGER NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE registration_forms (
+		event_id INTEGER PRIMARY KEY,
+		fields TEXT NOT NULL
+	);
+	CREATE TABLE reminders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		send_at DATETIME NOT NULL,
+		sent_at DATETIME,
+		channels TEXT NOT NULL DEFAULT '',
+		UNIQUE (event_id, user_id, kind)
+	);
+	CREATE TABLE notifications (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		title TEXT NOT NULL,
+		body TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		read_at DATETIME
+	);
+	CREATE TABLE notification_settings (
+		user_id INTEGER PRIMARY KEY,
+		quiet_hours_start TEXT NOT NULL DEFAULT '',
+		quiet_hours_end TEXT NOT NULL DEFAULT '',
+		phone TEXT NOT NULL DEFAULT '',
+		webhook_url TEXT NOT NULL DEFAULT ''
+	);
+	CREATE TABLE notification_preferences (
+		user_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		channel TEXT NOT NULL,
+		enabled INTEGER NOT NULL,
+		frequency TEXT NOT NULL,
+		PRIMARY KEY (user_id, type, channel)
+	);
+	CREATE TABLE notification_queue (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		channel TEXT NOT NULL,
+		type TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		body TEXT NOT NULL,
+		attachments TEXT NOT NULL DEFAULT '[]',
+		digest INTEGER NOT NULL,
+		deliver_after DATETIME NOT NULL
+	);
+	CREATE TABLE surveys (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL UNIQUE,
+		title TEXT NOT NULL,
+		questions TEXT NOT NULL,
+		invites_sent_at DATETIME,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE lotteries (
+		event_id INTEGER PRIMARY KEY,
+		opens_at DATETIME NOT NULL,
+		closes_at DATETIME NOT NULL,
+		priority_groups TEXT NOT NULL DEFAULT '[]',
+		seed TEXT,
+		seed_hash TEXT,
+		drawn_at DATETIME
+	);
+	CREATE TABLE lottery_entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		guests INTEGER NOT NULL DEFAULT 0,
+		answers TEXT NOT NULL DEFAULT '{}',
+		created_at DATETIME NOT NULL,
+		priority INTEGER,
+		rank INTEGER,
+		outcome TEXT NOT NULL DEFAULT 'pending',
+		UNIQUE(event_id, user_id)
+	);
+	INSERT INTO users (email, password) VALUES ('student@example.com', 'x');
+	`
+	_, err = db.DB.Exec(createTables)
+	if err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	db.DB.Close()
+	os.Exit(code)
+}
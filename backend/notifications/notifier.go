@@ -0,0 +1,65 @@
+package notifications
+
+import "log"
+
+// Notification types carried in Message.Type.
+const (
+	TypeReminder       = "event.reminder"
+	TypeEventChanged   = "event.changed"
+	TypeEventCancelled = "event.cancelled"
+	TypeRegistered     = "registration.confirmed"
+	TypeSurveyInvite   = "survey.invite"
+	TypeGroupInvite    = "registration.invited"
+	TypeTransferOffer  = "registration.transfer"
+	TypeLotteryResult  = "lottery.result"
+	TypeSavedSearch    = "search.match"
+	TypeDigest         = "digest"
+)
+
+// Types lists the notification types users can set preferences for.
+var Types = []string{TypeReminder, TypeEventChanged, TypeEventCancelled, TypeRegistered, TypeGroupInvite, TypeTransferOffer, TypeLotteryResult, TypeSurveyInvite, TypeSavedSearch}
+
+// Message is a single notification addressed to one user.
+type Message struct {
+	UserID      int64
+	To          string
+	Type        string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+	// UnsubscribeURL is set on email messages so every email carries a
+	// one-click opt out.
+	UnsubscribeURL string
+	// Digest holds the message for the daily digest whatever the user's
+	// frequency preference, for senders with their own frequency setting.
+	Digest bool
+	// Channels limits a Dispatcher to these channels, such as the ones that
+	// failed last time. All channels are used when it is empty.
+	Channels []string
+}
+
+// Attachment is a file sent along with a message on channels that support it.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Notifier delivers messages to users. Implementations must be safe to call
+// from background jobs.
+type Notifier interface {
+	Notify(msg Message) error
+}
+
+// Default is the notifier used by request handlers and jobs. main replaces it
+// with the configured channels at startup.
+var Default Notifier = LogNotifier{}
+
+// LogNotifier writes messages to the server log. It stands in for channels that
+// have no provider configured.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(msg Message) error {
+	log.Printf("notify %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,52 @@
+package notifications
+
+import (
+	"event-planner/models"
+	"fmt"
+)
+
+// RegistrationConfirmed tells the user their registration went through and
+// attaches the calendar entry for the event.
+func RegistrationConfirmed(notifier Notifier, event models.Event, user models.User) error {
+	body := fmt.Sprintf("You are registered for %s on %s in %s.\n",
+		event.Name, event.DateTime.Format(displayTimeFormat), event.Location)
+
+	return notifier.Notify(Message{
+		UserID:      user.ID,
+		To:          user.Email,
+		Type:        TypeRegistered,
+		Subject:     "Registered: " + event.Name,
+		Body:        body,
+		Attachments: []Attachment{eventAttachment(event, false)},
+	})
+}
+
+// GroupInvitation tells a group member that a seat is being held for them
+// and that they need to accept or decline it.
+func GroupInvitation(notifier Notifier, event models.Event, leader models.User, member models.User) error {
+	body := fmt.Sprintf("%s registered you for %s on %s in %s.\nA seat is held for you until you accept or decline the invitation.\n",
+		leader.Email, event.Name, event.DateTime.Format(displayTimeFormat), event.Location)
+
+	return notifier.Notify(Message{
+		UserID:  member.ID,
+		To:      member.Email,
+		Type:    TypeGroupInvite,
+		Subject: "You're invited: " + event.Name,
+		Body:    body,
+	})
+}
+
+// TransferOffered tells the recipient someone wants to give them their ticket.
+func TransferOffered(notifier Notifier, event models.Event, transfer models.Transfer) error {
+	body := fmt.Sprintf("%s would like to give you their ticket for %s on %s in %s.\nThe offer expires on %s.\n",
+		transfer.FromEmail, event.Name, event.DateTime.Format(displayTimeFormat), event.Location,
+		transfer.ExpiresAt.Format(displayTimeFormat))
+
+	return notifier.Notify(Message{
+		UserID:  transfer.ToUserID,
+		To:      transfer.ToEmail,
+		Type:    TypeTransferOffer,
+		Subject: "Ticket offered: " + event.Name,
+		Body:    body,
+	})
+}
@@ -0,0 +1,65 @@
+package notifications
+
+import (
+	"event-planner/models"
+	"fmt"
+	"log"
+	"time"
+)
+
+// SendDueReminders delivers every reminder that is due at now. Each reminder is
+// claimed before it is sent so concurrent or repeated runs never send it twice;
+// a failed delivery releases the claim and is retried on the next run, only on
+// the channels that failed when the notifier reports them. Reminders of
+// recurring events move on to the next occurrence once delivered.
+func SendDueReminders(notifier Notifier, now time.Time) error {
+	reminders, err := models.GetDueReminders(now)
+	if err != nil {
+		return err
+	}
+
+	for _, reminder := range reminders {
+		claimed, err := reminder.Claim(now)
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			continue
+		}
+
+		err = notifier.Notify(reminderMessage(reminder))
+		if err != nil {
+			log.Printf("could not send reminder %d: %v", reminder.ID, err)
+			failed, ok := FailedChannels(err)
+			if !ok {
+				failed = reminder.Channels
+			}
+			err = reminder.Release(failed)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = reminder.Rearm(now)
+		if err != nil {
+			log.Printf("could not move reminder %d to the next occurrence: %v", reminder.ID, err)
+		}
+	}
+	return nil
+}
+
+func reminderMessage(reminder models.Reminder) Message {
+	return Message{
+		UserID:   reminder.UserID,
+		To:       reminder.Email,
+		Type:     TypeReminder,
+		Channels: reminder.Channels,
+		Subject:  fmt.Sprintf("Reminder: %s starts in %s", reminder.EventName, reminder.Kind),
+		Body: fmt.Sprintf("%s starts at %s in %s.",
+			reminder.EventName,
+			reminder.EventDateTime.Format(displayTimeFormat),
+			reminder.EventLocation),
+	}
+}
@@ -0,0 +1,171 @@
+package notifications
+
+import (
+	"errors"
+	"event-planner/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createRegisteredEvent(t *testing.T, start time.Time) models.Event {
+	event := models.Event{
+		Name:        "Workshop",
+		Description: "Test Description",
+		Location:    "Room 1",
+		DateTime:    start,
+		UserID:      1,
+	}
+	err := event.Save()
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+
+	err = event.Register(1, nil)
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	return event
+}
+
+func TestSendDueReminders_SendsOnce(t *testing.T) {
+	start := time.Now().Add(48 * time.Hour)
+	createRegisteredEvent(t, start)
+	notifier := &recordingNotifier{}
+
+	// Nothing is due yet
+	err := SendDueReminders(notifier, time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, notifier.messages)
+
+	// The 24h reminder is due, the 1h one is not
+	dayBefore := start.Add(-23 * time.Hour)
+	err = SendDueReminders(notifier, dayBefore)
+	assert.NoError(t, err)
+	assert.Len(t, notifier.messages, 1)
+	assert.Equal(t, "student@example.com", notifier.messages[0].To)
+	assert.Contains(t, notifier.messages[0].Subject, "24h")
+
+	// Running again must not resend
+	err = SendDueReminders(notifier, dayBefore)
+	assert.NoError(t, err)
+	assert.Len(t, notifier.messages, 1)
+
+	err = SendDueReminders(notifier, start.Add(-30*time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, notifier.messages, 2)
+	assert.Contains(t, notifier.messages[1].Subject, "1h")
+
+	// Nothing is sent once the event has started
+	err = SendDueReminders(notifier, start.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, notifier.messages, 2)
+}
+
+func TestSendDueReminders_RetriesFailedDelivery(t *testing.T) {
+	start := time.Now().Add(30 * time.Hour)
+	createRegisteredEvent(t, start)
+	due := start.Add(-23 * time.Hour)
+
+	failing := &recordingNotifier{err: errors.New("smtp down")}
+	err := SendDueReminders(failing, due)
+	assert.NoError(t, err)
+
+	notifier := &recordingNotifier{}
+	err = SendDueReminders(notifier, due)
+	assert.NoError(t, err)
+	assert.Len(t, notifier.messages, 1)
+}
+
+func TestSendDueReminders_RetriesOnlyFailedChannels(t *testing.T) {
+	start := time.Now().Add(30 * time.Hour)
+	createRegisteredEvent(t, start)
+	due := start.Add(-23 * time.Hour)
+
+	dispatcher, email, inApp := newTestDispatcher(due)
+	dispatcher.Channels[ChannelEmail] = &recordingNotifier{err: errors.New("smtp down")}
+	assert.NoError(t, SendDueReminders(dispatcher, due))
+	assert.Len(t, inApp.messages, 1)
+
+	dispatcher.Channels[ChannelEmail] = email
+	assert.NoError(t, SendDueReminders(dispatcher, due.Add(time.Minute)))
+	assert.Len(t, email.messages, 1)
+	assert.Len(t, inApp.messages, 1)
+
+	// Delivered everywhere, so nothing is left to retry
+	assert.NoError(t, SendDueReminders(dispatcher, due.Add(2*time.Minute)))
+	assert.Len(t, email.messages, 1)
+	assert.Len(t, inApp.messages, 1)
+}
+
+func TestSendDueReminders_EveryOccurrence(t *testing.T) {
+	start := time.Now().Add(30 * time.Hour).Truncate(time.Second)
+	event := models.Event{
+		Name:        "Weekly Seminar",
+		Description: "Test Description",
+		Location:    "Room 3",
+		DateTime:    start,
+		UserID:      1,
+		Recurrence:  &models.Recurrence{Frequency: models.RecurWeekly, Interval: 1, Count: 2},
+	}
+	assert.NoError(t, event.Save())
+	assert.NoError(t, event.Register(1, nil))
+	notifier := &recordingNotifier{}
+
+	assert.NoError(t, SendDueReminders(notifier, start.Add(-23*time.Hour)))
+	assert.Len(t, notifier.messages, 1)
+
+	// The 1h reminder of the first occurrence was missed and moves on too
+	next := start.AddDate(0, 0, 7)
+	assert.NoError(t, SendDueReminders(notifier, next.Add(-23*time.Hour)))
+	if assert.Len(t, notifier.messages, 2) {
+		assert.Contains(t, notifier.messages[1].Body, next.UTC().Format(displayTimeFormat))
+	}
+	assert.NoError(t, SendDueReminders(notifier, next.Add(-30*time.Minute)))
+	if assert.Len(t, notifier.messages, 3) {
+		assert.Contains(t, notifier.messages[2].Subject, "1h")
+	}
+
+	// The series ends after two occurrences
+	assert.NoError(t, SendDueReminders(notifier, next.AddDate(0, 0, 7).Add(-23*time.Hour)))
+	assert.Len(t, notifier.messages, 3)
+}
+
+func TestRescheduleReminders_KeepsSentReminders(t *testing.T) {
+	start := time.Now().Add(48 * time.Hour)
+	event := createRegisteredEvent(t, start)
+	notifier := &recordingNotifier{}
+
+	assert.NoError(t, SendDueReminders(notifier, start.Add(-23*time.Hour)))
+	assert.Len(t, notifier.messages, 1)
+
+	// Other edits must not resend the 24h reminder
+	event.Location = "Main Hall"
+	assert.NoError(t, event.Update())
+	assert.NoError(t, event.RescheduleReminders())
+	assert.NoError(t, SendDueReminders(notifier, start.Add(-23*time.Hour)))
+	assert.Len(t, notifier.messages, 1)
+
+	assert.NoError(t, SendDueReminders(notifier, start.Add(-30*time.Minute)))
+	if assert.Len(t, notifier.messages, 2) {
+		assert.Contains(t, notifier.messages[1].Subject, "1h")
+	}
+}
+
+func TestRescheduleReminders_ResendsAfterMove(t *testing.T) {
+	start := time.Now().Add(48 * time.Hour)
+	event := createRegisteredEvent(t, start)
+	notifier := &recordingNotifier{}
+
+	assert.NoError(t, SendDueReminders(notifier, start.Add(-23*time.Hour)))
+	assert.Len(t, notifier.messages, 1)
+
+	event.DateTime = start.Add(7 * 24 * time.Hour)
+	assert.NoError(t, event.Update())
+	assert.NoError(t, event.RescheduleReminders())
+	assert.NoError(t, SendDueReminders(notifier, event.DateTime.Add(-23*time.Hour)))
+	if assert.Len(t, notifier.messages, 2) {
+		assert.Contains(t, notifier.messages[1].Subject, "24h")
+	}
+}
@@ -0,0 +1,100 @@
+package notifications
+
+import (
+	"database/sql"
+	"errors"
+	"event-planner/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SendSavedSearchMatches runs SavedSearchesMatched for every event queued
+// with models.QueueSavedSearchMatch. Events deleted in the meantime are
+// skipped.
+func SendSavedSearchMatches(notifier Notifier, now time.Time) error {
+	eventIDs, err := models.GetQueuedSavedSearchMatches()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, eventID := range eventIDs {
+		claimed, err := models.ClaimSavedSearchMatch(eventID)
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			continue
+		}
+
+		event, err := models.GetEventByID(eventID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err == nil {
+			err = SavedSearchesMatched(notifier, *event)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("event %d: %w", eventID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SavedSearchesMatched tells everyone with a saved search matching the new
+// event about it, once per user however many of their searches match. Users
+// whose matching searches are all set to the digest get it in their daily
+// digest. The organizer's own searches are skipped.
+func SavedSearchesMatched(notifier Notifier, event models.Event) error {
+	searches, err := models.GetSavedSearchCandidates(event)
+	if err != nil {
+		return err
+	}
+
+	var users []int64
+	matched := map[int64][]models.SavedSearch{}
+	for _, search := range searches {
+		matches, err := search.Matches(event.ID)
+		if err != nil {
+			return err
+		}
+		if !matches {
+			continue
+		}
+		if matched[search.UserID] == nil {
+			users = append(users, search.UserID)
+		}
+		matched[search.UserID] = append(matched[search.UserID], search)
+	}
+
+	var errs []error
+	for _, userID := range users {
+		user, err := models.GetUserByID(userID)
+		if err == nil {
+			err = notifyAll(notifier, []models.User{*user}, savedSearchMessage(event, matched[userID]))
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func savedSearchMessage(event models.Event, searches []models.SavedSearch) Message {
+	names := make([]string, len(searches))
+	digest := true
+	for i, search := range searches {
+		names[i] = fmt.Sprintf("%q", search.Name)
+		digest = digest && search.Frequency == models.FrequencyDigest
+	}
+
+	return Message{
+		Type:    TypeSavedSearch,
+		Subject: "New event: " + event.Name,
+		Body: fmt.Sprintf("%s, on %s in %s, matches your saved search %s.\n",
+			event.Name, event.DateTime.Format(displayTimeFormat), event.Location, strings.Join(names, ", ")),
+		Digest: digest,
+	}
+}
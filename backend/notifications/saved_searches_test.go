@@ -0,0 +1,125 @@
+package notifications
+
+import (
+	"event-planner/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavedSearchesMatched_NotifiesOncePerUser(t *testing.T) {
+	organizerID := createUser(t, "search-organizer@example.com")
+	instantID := createUser(t, "search-instant@example.com")
+	digestID := createUser(t, "search-digest@example.com")
+	otherID := createUser(t, "search-other@example.com")
+
+	sports := models.Category{Name: "Sports", Slug: "search-sports"}
+	assert.NoError(t, sports.Save())
+	climbing := models.Category{Name: "Climbing", Slug: "search-climbing", ParentID: &sports.ID}
+	assert.NoError(t, climbing.Save())
+
+	searches := []models.SavedSearch{
+		{UserID: instantID, Name: "Sports", Categories: []string{"search-sports"}},
+		{UserID: instantID, Name: "Bouldering", Query: "boulder", Frequency: models.FrequencyDigest},
+		{UserID: digestID, Name: "Beginners", Query: "beginner", Tags: []string{"free"}, Frequency: models.FrequencyDigest},
+		{UserID: otherID, Name: "Chess", Query: "chess"},
+		{UserID: organizerID, Name: "Mine", Query: "boulder"},
+	}
+	for i := range searches {
+		assert.NoError(t, searches[i].Validate())
+		assert.NoError(t, searches[i].Save())
+	}
+
+	event := models.Event{
+		Name:        "Bouldering for Beginners",
+		Description: "Test Description",
+		Location:    "Climbing Wall",
+		DateTime:    time.Now().Add(30 * 24 * time.Hour),
+		UserID:      organizerID,
+		CategoryID:  &climbing.ID,
+		Tags:        []string{"free"},
+	}
+	assert.NoError(t, event.Save())
+
+	notifier := &recordingNotifier{}
+	assert.NoError(t, SavedSearchesMatched(notifier, event))
+
+	if assert.Len(t, notifier.messages, 2) {
+		instant := notifier.messages[0]
+		assert.Equal(t, instantID, instant.UserID)
+		assert.Equal(t, TypeSavedSearch, instant.Type)
+		assert.Contains(t, instant.Body, `"Sports", "Bouldering"`)
+		assert.False(t, instant.Digest)
+
+		digest := notifier.messages[1]
+		assert.Equal(t, "search-digest@example.com", digest.To)
+		assert.True(t, digest.Digest)
+	}
+}
+
+func TestSendSavedSearchMatches_PrefiltersAndRunsOnce(t *testing.T) {
+	organizerID := createUser(t, "queued-search-organizer@example.com")
+	userID := createUser(t, "queued-search-user@example.com")
+
+	music := models.Category{Name: "Music", Slug: "queued-music"}
+	assert.NoError(t, music.Save())
+	jazz := models.Category{Name: "Jazz", Slug: "queued-jazz", ParentID: &music.ID}
+	assert.NoError(t, jazz.Save())
+	film := models.Category{Name: "Film", Slug: "queued-film"}
+	assert.NoError(t, film.Save())
+
+	searches := []models.SavedSearch{
+		{UserID: userID, Name: "Music", Categories: []string{"queued-music"}},
+		{UserID: userID, Name: "Paid", Query: "quartet", Tags: []string{"paid"}},
+		{UserID: userID, Name: "Film", Categories: []string{"queued-film"}},
+	}
+	for i := range searches {
+		assert.NoError(t, searches[i].Validate())
+		assert.NoError(t, searches[i].Save())
+	}
+
+	event := models.Event{
+		Name:        "Jazz Quartet",
+		Description: "Test Description",
+		Location:    "Hall",
+		DateTime:    time.Now().Add(30 * 24 * time.Hour),
+		UserID:      organizerID,
+		CategoryID:  &jazz.ID,
+		Tags:        []string{"free"},
+	}
+	assert.NoError(t, event.Save())
+
+	// Neither the tag nor the category of the other searches fits
+	candidates, err := models.GetSavedSearchCandidates(event)
+	assert.NoError(t, err)
+	names := []string{}
+	for _, candidate := range candidates {
+		if candidate.UserID == userID {
+			names = append(names, candidate.Name)
+		}
+	}
+	assert.Equal(t, []string{"Music"}, names)
+
+	assert.NoError(t, models.QueueSavedSearchMatch(event.ID))
+	notifier := &recordingNotifier{}
+	assert.NoError(t, SendSavedSearchMatches(notifier, time.Now()))
+	assert.NoError(t, SendSavedSearchMatches(notifier, time.Now()))
+	if assert.Len(t, notifier.messages, 1) {
+		assert.Equal(t, userID, notifier.messages[0].UserID)
+		assert.Contains(t, notifier.messages[0].Body, `"Music"`)
+	}
+}
+
+func TestSavedSearch_Validate(t *testing.T) {
+	search := models.SavedSearch{Name: "  Talks ", Query: "  machine   learning ", Tags: []string{"AI Safety"}}
+	assert.NoError(t, search.Validate())
+	assert.Equal(t, "Talks", search.Name)
+	assert.Equal(t, "machine learning", search.Query)
+	assert.Equal(t, []string{"ai-safety"}, search.Tags)
+	assert.Equal(t, models.FrequencyImmediate, search.Frequency)
+
+	assert.Error(t, (&models.SavedSearch{Name: "Empty"}).Validate())
+	assert.Error(t, (&models.SavedSearch{Name: "Unknown", Categories: []string{"no-such-category"}}).Validate())
+	assert.Error(t, (&models.SavedSearch{Name: "Weekly", Query: "talk", Frequency: "weekly"}).Validate())
+}
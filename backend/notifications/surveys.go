@@ -0,0 +1,59 @@
+package notifications
+
+import (
+	"event-planner/models"
+	"fmt"
+	"log"
+	"time"
+)
+
+// SendSurveyInvites invites the checked-in attendees of every event that has
+// ended to fill in its feedback survey. Each survey's invitations are claimed
+// before sending so they go out only once.
+func SendSurveyInvites(notifier Notifier, now time.Time) error {
+	surveys, err := models.GetSurveysAwaitingInvites()
+	if err != nil {
+		return err
+	}
+
+	for _, survey := range surveys {
+		event, err := models.GetEventByID(survey.EventID)
+		if err != nil {
+			log.Printf("survey %d has no event: %v", survey.ID, err)
+			continue
+		}
+
+		if event.Ends().After(now) {
+			continue
+		}
+
+		claimed, err := survey.ClaimInvites(now)
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			continue
+		}
+
+		attendees, err := event.CheckedInAttendees()
+		if err != nil {
+			return err
+		}
+
+		for _, attendee := range attendees {
+			err = notifier.Notify(Message{
+				UserID:  attendee.ID,
+				To:      attendee.Email,
+				Type:    TypeSurveyInvite,
+				Subject: "How was " + event.Name + "?",
+				Body: fmt.Sprintf("Thanks for attending %s. The organizers would like your feedback: %s",
+					event.Name, survey.Title),
+			})
+			if err != nil {
+				log.Printf("could not invite user %d to survey %d: %v", attendee.ID, survey.ID, err)
+			}
+		}
+	}
+	return nil
+}
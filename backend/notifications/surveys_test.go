@@ -0,0 +1,58 @@
+package notifications
+
+import (
+	"event-planner/db"
+	"event-planner/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendSurveyInvites_InvitesCheckedInAttendeesOnce(t *testing.T) {
+	start := time.Now().Add(-3 * time.Hour)
+	end := start.Add(90 * time.Minute)
+	event := models.Event{
+		Name:        "Hackathon",
+		Description: "Test Description",
+		Location:    "Lab",
+		DateTime:    start,
+		EndTime:     &end,
+		UserID:      1,
+	}
+	err := event.Save()
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+
+	_, err = db.DB.Exec("INSERT INTO users (email, password) VALUES ('no-show@example.com', 'x')")
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	db.DB.Exec("INSERT INTO registrations (event_id, user_id) VALUES (?, 1), (?, 2)", event.ID, event.ID)
+	assert.NoError(t, event.CheckIn(1, start))
+
+	survey := models.Survey{EventID: event.ID, Title: "Hackathon feedback", Questions: []models.SurveyQuestion{{Kind: models.QuestionNPS, Prompt: "Recommend us?"}}}
+	err = survey.Save()
+	if err != nil {
+		t.Fatalf("Failed to save survey: %v", err)
+	}
+
+	notifier := &recordingNotifier{}
+
+	// Nothing goes out before the event ends
+	err = SendSurveyInvites(notifier, end.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, notifier.messages)
+
+	err = SendSurveyInvites(notifier, end)
+	assert.NoError(t, err)
+	if assert.Len(t, notifier.messages, 1) {
+		assert.Equal(t, "student@example.com", notifier.messages[0].To)
+		assert.Equal(t, TypeSurveyInvite, notifier.messages[0].Type)
+	}
+
+	err = SendSurveyInvites(notifier, end.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, notifier.messages, 1)
+}
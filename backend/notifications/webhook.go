@@ -0,0 +1,53 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"event-planner/utils"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifier posts messages as JSON to the URL in msg.To, which is the
+// personal webhook a user configured in their notification settings. Without
+// a Client, messages use one that refuses internal addresses.
+type WebhookNotifier struct {
+	Client *http.Client
+}
+
+var defaultWebhookClient = utils.NewOutboundClient(10 * time.Second)
+
+func (n WebhookNotifier) Notify(msg Message) error {
+	// URLs saved before they were validated are checked again here
+	err := utils.ValidateOutboundURL(msg.To)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"type":    msg.Type,
+		"subject": msg.Subject,
+		"body":    msg.Body,
+		"sentAt":  time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	client := n.Client
+	if client == nil {
+		client = defaultWebhookClient
+	}
+
+	response, err := client.Post(msg.To, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", response.Status)
+	}
+	return nil
+}
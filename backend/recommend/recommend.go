@@ -0,0 +1,268 @@
+package recommend
+
+import (
+	"event-planner/models"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PerUser is how many recommendations are kept for each user.
+const PerUser = 20
+
+// Weights of the signals combined into a score.
+const (
+	followWeight       = 3.0
+	organizationWeight = 1.5
+	categoryWeight     = 1.0
+	tagWeight          = 2.0
+	similarUsersWeight = 4.0
+)
+
+// Refresh recomputes every user's recommendations and replaces the cached
+// ones. It runs as a background job.
+func Refresh(now time.Time) error {
+	inputs, err := models.LoadRecommendationInputs()
+	if err != nil {
+		return err
+	}
+	return models.SaveRecommendations(Compute(inputs, now), now)
+}
+
+// candidate accumulates the score and reasons of one event for one user.
+type candidate struct {
+	eventID int64
+	score   float64
+	reasons []models.RecommendationReason
+}
+
+func (c *candidate) add(score float64, kind, message string) {
+	c.score += score
+	c.reasons = append(c.reasons, models.RecommendationReason{Kind: kind, Message: message})
+}
+
+// Compute ranks the upcoming events for every user who has registered for or
+// followed something. An event scores for:
+//
+//   - coming from an organization the user follows,
+//   - sharing an organization or category with events the user registered for,
+//   - carrying tags the user's registrations carry, by how often they do,
+//   - registrations from similar users, weighted by the Jaccard similarity
+//     of their registrations to the user's.
+//
+// Events the user organizes or registered for are left out.
+func Compute(inputs *models.RecommendationInputs, now time.Time) map[int64][]models.Recommendation {
+	attendees := map[int64][]int64{}
+	for userID, eventIDs := range inputs.Registrations {
+		for _, eventID := range eventIDs {
+			attendees[eventID] = append(attendees[eventID], userID)
+		}
+	}
+
+	upcoming := []models.Event{}
+	for _, event := range inputs.Events {
+		if event.DateTime.After(now) {
+			upcoming = append(upcoming, event)
+		}
+	}
+	sort.Slice(upcoming, func(i, j int) bool { return upcoming[i].ID < upcoming[j].ID })
+
+	users := map[int64]bool{}
+	for userID := range inputs.Registrations {
+		users[userID] = true
+	}
+	for userID := range inputs.Follows {
+		users[userID] = true
+	}
+
+	recommendations := map[int64][]models.Recommendation{}
+	for userID := range users {
+		list := recommendFor(userID, inputs, attendees, upcoming)
+		if len(list) > 0 {
+			recommendations[userID] = list
+		}
+	}
+	return recommendations
+}
+
+func recommendFor(userID int64, inputs *models.RecommendationInputs, attendees map[int64][]int64, upcoming []models.Event) []models.Recommendation {
+	registered := map[int64]bool{}
+	organizations := map[int64]int{}
+	categories := map[int64]int{}
+	tags := map[string]int{}
+	history := []models.Event{}
+	for _, eventID := range inputs.Registrations[userID] {
+		registered[eventID] = true
+		event, ok := inputs.Events[eventID]
+		if !ok {
+			continue
+		}
+		history = append(history, event)
+		if event.OrganizationID != nil {
+			organizations[*event.OrganizationID]++
+		}
+		if event.CategoryID != nil {
+			categories[*event.CategoryID]++
+		}
+		for _, tag := range event.Tags {
+			tags[tag]++
+		}
+	}
+
+	followed := map[int64]bool{}
+	for _, organizationID := range inputs.Follows[userID] {
+		followed[organizationID] = true
+	}
+
+	similarity := similarUsers(userID, inputs.Registrations, attendees)
+
+	candidates := []candidate{}
+	for _, event := range upcoming {
+		if registered[event.ID] || event.UserID == userID {
+			continue
+		}
+
+		c := candidate{eventID: event.ID}
+		if event.OrganizationID != nil {
+			name := inputs.Organizations[*event.OrganizationID]
+			if followed[*event.OrganizationID] {
+				c.add(followWeight, models.ReasonFollow, fmt.Sprintf("From %s, which you follow", name))
+			} else if count := organizations[*event.OrganizationID]; count > 0 {
+				past, _ := closest(history, func(past models.Event) int { return boolScore(sameID(past.OrganizationID, event.OrganizationID)) })
+				c.add(organizationWeight, models.ReasonHistory, fmt.Sprintf("From %s, like %s you registered for", name, andOthers(past, count)))
+			}
+		}
+		if event.CategoryID != nil {
+			if count := categories[*event.CategoryID]; count > 0 {
+				past, _ := closest(history, func(past models.Event) int { return boolScore(sameID(past.CategoryID, event.CategoryID)) })
+				c.add(categoryWeight*math.Min(float64(count), 3), models.ReasonHistory,
+					fmt.Sprintf("Because you registered for %s in %s", andOthers(past, count), inputs.Categories[*event.CategoryID]))
+			}
+		}
+
+		shared := []string{}
+		affinity := 0.0
+		for _, tag := range event.Tags {
+			if tags[tag] > 0 {
+				shared = append(shared, tag)
+				affinity += float64(tags[tag]) / float64(len(registered))
+			}
+		}
+		if len(shared) > 0 {
+			sort.SliceStable(shared, func(i, j int) bool { return tags[shared[i]] > tags[shared[j]] })
+			if len(shared) > 2 {
+				shared = shared[:2]
+			}
+			past, _ := closest(history, func(past models.Event) int { return sharedTags(past.Tags, event.Tags) })
+			c.add(tagWeight*affinity, models.ReasonTags, fmt.Sprintf("Tagged %s, like %s you registered for", strings.Join(shared, " and "), past.Name))
+		}
+
+		similar := 0
+		weight := 0.0
+		for _, attendee := range attendees[event.ID] {
+			if similarity[attendee] > 0 {
+				similar++
+				weight += similarity[attendee]
+			}
+		}
+		if similar > 0 {
+			message := fmt.Sprintf("%s who registered for the same events as you are going", plural(similar, "person"))
+			if similar == 1 {
+				message = "Someone who registered for the same events as you is going"
+			}
+			c.add(similarUsersWeight*weight, models.ReasonSimilarUsers, message)
+		}
+
+		if c.score > 0 {
+			candidates = append(candidates, c)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+	if len(candidates) > PerUser {
+		candidates = candidates[:PerUser]
+	}
+
+	list := make([]models.Recommendation, len(candidates))
+	for i, c := range candidates {
+		list[i] = models.Recommendation{EventID: c.eventID, Score: math.Round(c.score*1000) / 1000, Reasons: c.reasons}
+	}
+	return list
+}
+
+// closest returns the past event scoring highest on match, the latest one on
+// ties. It reports false when no event scores.
+func closest(history []models.Event, match func(models.Event) int) (models.Event, bool) {
+	var best models.Event
+	bestScore := 0
+	for _, past := range history {
+		score := match(past)
+		if score > bestScore || (score == bestScore && score > 0 && past.DateTime.After(best.DateTime)) {
+			best, bestScore = past, score
+		}
+	}
+	return best, bestScore > 0
+}
+
+func sameID(a, b *int64) bool {
+	return a != nil && b != nil && *a == *b
+}
+
+func boolScore(ok bool) int {
+	if ok {
+		return 1
+	}
+	return 0
+}
+
+func sharedTags(a, b []string) int {
+	count := 0
+	for _, tag := range a {
+		if slices.Contains(b, tag) {
+			count++
+		}
+	}
+	return count
+}
+
+// andOthers names the event and counts the rest of the count events, like
+// "Jazz Night and 2 other events".
+func andOthers(event models.Event, count int) string {
+	if count <= 1 {
+		return event.Name
+	}
+	return event.Name + " and " + plural(count-1, "other event")
+}
+
+// similarUsers returns the Jaccard similarity between the user's
+// registrations and those of every user sharing at least one of them.
+func similarUsers(userID int64, registrations, attendees map[int64][]int64) map[int64]float64 {
+	shared := map[int64]int{}
+	for _, eventID := range registrations[userID] {
+		for _, other := range attendees[eventID] {
+			if other != userID {
+				shared[other]++
+			}
+		}
+	}
+
+	similarity := map[int64]float64{}
+	for other, count := range shared {
+		union := len(registrations[userID]) + len(registrations[other]) - count
+		similarity[other] = float64(count) / float64(union)
+	}
+	return similarity
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	if noun == "person" {
+		return fmt.Sprintf("%d people", count)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
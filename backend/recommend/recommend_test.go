@@ -0,0 +1,117 @@
+package recommend
+
+import (
+	"event-planner/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func testInputs(now time.Time) *models.RecommendationInputs {
+	past := now.Add(-7 * 24 * time.Hour)
+	future := now.Add(7 * 24 * time.Hour)
+	return &models.RecommendationInputs{
+		Events: map[int64]models.Event{
+			// Past events users 1, 2 and 3 attended
+			1: {ID: 1, Name: "Jazz Night", DateTime: past, UserID: 9, OrganizationID: int64Ptr(100), CategoryID: int64Ptr(10), Tags: []string{"jazz", "live"}},
+			2: {ID: 2, Name: "Open Mic", DateTime: past.Add(-time.Hour), UserID: 9, CategoryID: int64Ptr(10), Tags: []string{"jazz"}},
+			// Upcoming events
+			3: {ID: 3, DateTime: future, UserID: 9, CategoryID: int64Ptr(10), Tags: []string{"jazz"}},
+			4: {ID: 4, DateTime: future, UserID: 9, OrganizationID: int64Ptr(200)},
+			5: {ID: 5, DateTime: future, UserID: 9},
+			6: {ID: 6, DateTime: future, UserID: 1, Tags: []string{"jazz"}},
+			7: {ID: 7, DateTime: future, UserID: 9, OrganizationID: int64Ptr(100)},
+			8: {ID: 8, DateTime: past.Add(time.Hour), UserID: 9, Tags: []string{"jazz"}},
+		},
+		Registrations: map[int64][]int64{
+			1: {1, 2},
+			2: {1, 2, 5},
+			3: {2},
+		},
+		Follows: map[int64][]int64{
+			1: {200},
+			4: {200},
+		},
+		Categories:    map[int64]string{10: "Music"},
+		Organizations: map[int64]string{100: "Jazz Society", 200: "Film Club"},
+	}
+}
+
+func findRecommendation(list []models.Recommendation, eventID int64) *models.Recommendation {
+	for i := range list {
+		if list[i].EventID == eventID {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+func reasonKinds(r *models.Recommendation) []string {
+	kinds := []string{}
+	for _, reason := range r.Reasons {
+		kinds = append(kinds, reason.Kind)
+	}
+	return kinds
+}
+
+func TestCompute_CombinesSignals(t *testing.T) {
+	now := time.Now()
+	recommendations := Compute(testInputs(now), now)
+	user := recommendations[1]
+
+	// Past events, events the user organizes and events without any signal are left out
+	assert.Nil(t, findRecommendation(user, 1))
+	assert.Nil(t, findRecommendation(user, 6))
+	assert.Nil(t, findRecommendation(user, 8))
+
+	music := findRecommendation(user, 3)
+	if assert.NotNil(t, music) {
+		assert.Equal(t, []string{models.ReasonHistory, models.ReasonTags}, reasonKinds(music))
+		assert.Equal(t, "Because you registered for Jazz Night and 1 other event in Music", music.Reasons[0].Message)
+		assert.Equal(t, "Tagged jazz, like Jazz Night you registered for", music.Reasons[1].Message)
+	}
+
+	film := findRecommendation(user, 4)
+	if assert.NotNil(t, film) {
+		assert.Equal(t, models.ReasonFollow, film.Reasons[0].Kind)
+		assert.Equal(t, "From Film Club, which you follow", film.Reasons[0].Message)
+	}
+
+	society := findRecommendation(user, 7)
+	if assert.NotNil(t, society) {
+		assert.Equal(t, "From Jazz Society, like Jazz Night you registered for", society.Reasons[0].Message)
+	}
+
+	// User 2 attended exactly the same events and registered for event 5
+	similar := findRecommendation(user, 5)
+	if assert.NotNil(t, similar) {
+		assert.Equal(t, []string{models.ReasonSimilarUsers}, reasonKinds(similar))
+		assert.Equal(t, "Someone who registered for the same events as you is going", similar.Reasons[0].Message)
+		// Jaccard similarity of {1, 2} and {1, 2, 5} is 2/3
+		assert.InDelta(t, similarUsersWeight*2.0/3.0, similar.Score, 0.001)
+	}
+
+	// Best first
+	for i := 1; i < len(user); i++ {
+		assert.GreaterOrEqual(t, user[i-1].Score, user[i].Score)
+	}
+}
+
+func TestCompute_FollowersWithoutHistory(t *testing.T) {
+	now := time.Now()
+	recommendations := Compute(testInputs(now), now)
+
+	assert.Len(t, recommendations[4], 1)
+	assert.Equal(t, int64(4), recommendations[4][0].EventID)
+
+	// Users who registered for nothing and follow nothing get nothing
+	_, ok := recommendations[5]
+	assert.False(t, ok)
+	// User 2 is registered for everything the similar users attend
+	assert.Nil(t, findRecommendation(recommendations[2], 5))
+}
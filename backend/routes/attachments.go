@@ -0,0 +1,383 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"event-planner/models"
+	"event-planner/storage"
+	"event-planner/utils"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// attachmentLinkTTL is how long a download link handed out with an
+// attachment keeps working.
+const attachmentLinkTTL = 15 * time.Minute
+
+// Scanner checks attachments for viruses before they are stored. main may
+// replace it with a real scanner.
+var Scanner storage.Scanner = storage.NopScanner{}
+
+type attachmentResponse struct {
+	models.Attachment
+	URL string
+}
+
+type attachmentUpdateRequest struct {
+	Visibility string `binding:"required"`
+}
+
+// Helper function to describe an attachment with a fresh signed download link
+func newAttachmentResponse(attachment models.Attachment, now time.Time) attachmentResponse {
+	expires := now.Add(attachmentLinkTTL)
+	url := fmt.Sprintf("%s/attachments/%d/download?expires=%d&signature=%s", strings.TrimRight(PublicURL, "/"),
+		attachment.ID, expires.Unix(), utils.SignDownload(attachment.ID, expires))
+	return attachmentResponse{Attachment: attachment, URL: url}
+}
+
+// Helper function to check whether the user may see registrants-only attachments
+func canSeeRegistrantAttachments(event *models.Event, userId int64) (bool, error) {
+	if userId == 0 {
+		return false, nil
+	}
+
+	organizer, err := isEventOrganizer(event, userId)
+	if err != nil || organizer {
+		return organizer, err
+	}
+	return event.IsRegistered(userId)
+}
+
+// Helper function to load the attachment in the URL and check it belongs to the event
+func getEventAttachment(context *gin.Context, eventId int64) (*models.Attachment, bool) {
+	attachmentId, err := strconv.ParseInt(context.Param("attachmentId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse attachment id"})
+		return nil, false
+	}
+
+	attachment, err := models.GetAttachment(attachmentId)
+	if err != nil || attachment.EventID != eventId {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Attachment not found"})
+		return nil, false
+	}
+	return attachment, true
+}
+
+// Helper function to read the uploaded file, replying if it is unusable
+func readAttachmentUpload(context *gin.Context) (string, []byte, bool) {
+	// Leave room for the rest of the multipart body
+	context.Request.Body = http.MaxBytesReader(context.Writer, context.Request.Body, models.MaxAttachmentSize+1<<20)
+
+	file, header, err := context.Request.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		context.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "Attachments must be at most 20MB"})
+		return "", nil, false
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Please upload a file in the file field"})
+		return "", nil, false
+	}
+	defer file.Close()
+
+	name := models.CleanAttachmentName(header.Filename)
+	if name == "" || name == "." || name == "/" {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "The uploaded file has no name"})
+		return "", nil, false
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, models.MaxAttachmentSize+1))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not read file"})
+		return "", nil, false
+	}
+	if len(data) > models.MaxAttachmentSize {
+		context.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "Attachments must be at most 20MB"})
+		return "", nil, false
+	}
+	return name, data, true
+}
+
+// Helper function to remove an event's attachments along with the event
+func deleteEventAttachments(ctx context.Context, event *models.Event) {
+	attachments, err := event.Attachments()
+	if err != nil {
+		log.Printf("could not fetch attachments of event %d: %v", event.ID, err)
+		return
+	}
+
+	for _, attachment := range attachments {
+		err = attachment.Delete()
+		if err == nil {
+			err = Storage.Delete(ctx, attachment.Key)
+		}
+		if err != nil {
+			log.Printf("could not delete attachment %d: %v", attachment.ID, err)
+		}
+	}
+}
+
+// getAttachments lists the event's attachments with download links. Anyone
+// sees the public ones; registrants and organizers who send their token also
+// see the rest.
+func getAttachments(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	seeAll, err := canSeeRegistrantAttachments(event, optionalUserID(context))
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not check registration"})
+		return
+	}
+
+	attachments, err := event.Attachments()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch attachments"})
+		return
+	}
+
+	now := time.Now()
+	response := []attachmentResponse{}
+	for _, attachment := range attachments {
+		if attachment.Visibility == models.AttachmentPublic || seeAll {
+			response = append(response, newAttachmentResponse(attachment, now))
+		}
+	}
+	context.JSON(http.StatusOK, response)
+}
+
+// uploadAttachment stores a file sent as multipart form data in the file
+// field, with an optional visibility field that defaults to registrants.
+func uploadAttachment(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	userId := context.GetInt64("userId")
+	if !checkEventOrganizer(context, event, userId, "manage attachments") {
+		return
+	}
+
+	name, data, ok := readAttachmentUpload(context)
+	if !ok {
+		return
+	}
+
+	visibility := context.DefaultPostForm("visibility", models.AttachmentRegistrants)
+	err := models.ValidateAttachmentVisibility(visibility)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	contentType, err := models.DetectAttachmentType(name, data)
+	if err != nil {
+		context.JSON(http.StatusUnsupportedMediaType, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Check the quota before scanning and storing; Save checks it again
+	used, err := models.AttachmentsSize(eventId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not check attachment quota"})
+		return
+	}
+	if used+int64(len(data)) > models.MaxEventAttachmentsSize {
+		context.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": models.ErrAttachmentQuota.Error()})
+		return
+	}
+
+	ctx := context.Request.Context()
+	err = Scanner.Scan(ctx, name, bytes.NewReader(data))
+	if errors.Is(err, storage.ErrInfected) {
+		log.Printf("rejected attachment %q for event %d: %v", name, eventId, err)
+		context.JSON(http.StatusUnprocessableEntity, gin.H{"message": "The file was rejected by the virus scanner"})
+		return
+	}
+	if err != nil {
+		log.Printf("could not scan attachment %q for event %d: %v", name, eventId, err)
+		context.JSON(http.StatusServiceUnavailable, gin.H{"message": "Could not scan file, please try again later"})
+		return
+	}
+
+	key, err := models.NewAttachmentKey(eventId)
+	if err == nil {
+		err = Storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType)
+	}
+	if err != nil {
+		log.Printf("could not store attachment %q for event %d: %v", name, eventId, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not store file"})
+		return
+	}
+
+	attachment := models.Attachment{
+		EventID:     eventId,
+		Name:        name,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Key:         key,
+		Visibility:  visibility,
+		UploadedBy:  userId,
+	}
+	err = attachment.Save()
+	if err != nil {
+		Storage.Delete(ctx, key)
+	}
+	if errors.Is(err, models.ErrAttachmentQuota) {
+		context.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not save attachment"})
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Attachment uploaded", "attachment": newAttachmentResponse(attachment, time.Now())})
+}
+
+func updateAttachment(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventOrganizer(context, event, context.GetInt64("userId"), "manage attachments") {
+		return
+	}
+
+	attachment, ok := getEventAttachment(context, eventId)
+	if !ok {
+		return
+	}
+
+	var request attachmentUpdateRequest
+	err := context.ShouldBindJSON(&request)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	err = models.ValidateAttachmentVisibility(request.Visibility)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	err = attachment.UpdateVisibility(request.Visibility)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update attachment"})
+		return
+	}
+
+	attachment.Visibility = request.Visibility
+	context.JSON(http.StatusOK, gin.H{"message": "Attachment updated", "attachment": newAttachmentResponse(*attachment, time.Now())})
+}
+
+func deleteAttachment(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventOrganizer(context, event, context.GetInt64("userId"), "manage attachments") {
+		return
+	}
+
+	attachment, ok := getEventAttachment(context, eventId)
+	if !ok {
+		return
+	}
+
+	err := attachment.Delete()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete attachment"})
+		return
+	}
+
+	err = Storage.Delete(context.Request.Context(), attachment.Key)
+	if err != nil {
+		log.Printf("could not delete %s: %v", attachment.Key, err)
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Attachment deleted"})
+}
+
+// downloadAttachment streams an attachment to anyone holding an unexpired
+// link from getAttachments. The link is the authorization, so it works in
+// plain <a href> tags without a token.
+func downloadAttachment(context *gin.Context) {
+	attachmentId, err := strconv.ParseInt(context.Param("attachmentId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse attachment id"})
+		return
+	}
+
+	expires, err := strconv.ParseInt(context.Query("expires"), 10, 64)
+	if err == nil {
+		err = utils.VerifyDownload(attachmentId, time.Unix(expires, 0), context.Query("signature"), time.Now())
+	}
+	if errors.Is(err, utils.ErrDownloadExpired) {
+		context.JSON(http.StatusGone, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusForbidden, gin.H{"message": utils.ErrInvalidDownload.Error()})
+		return
+	}
+
+	attachment, err := models.GetAttachment(attachmentId)
+	if err != nil {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Attachment not found"})
+		return
+	}
+
+	file, err := Storage.Open(context.Request.Context(), attachment.Key)
+	if errors.Is(err, storage.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Attachment not found"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not read attachment"})
+		return
+	}
+	defer file.Close()
+
+	context.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, file, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}),
+		"Cache-Control":          "private, no-store",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
@@ -0,0 +1,163 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"event-planner/models"
+	"event-planner/storage"
+	"event-planner/utils"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type rejectingScanner struct{}
+
+func (rejectingScanner) Scan(ctx context.Context, name string, body io.Reader) error {
+	data, _ := io.ReadAll(body)
+	if bytes.Contains(data, []byte("EICAR")) {
+		return fmt.Errorf("%w: test signature", storage.ErrInfected)
+	}
+	return nil
+}
+
+func setupAttachmentRouter(t *testing.T) *gin.Engine {
+	previous, previousScanner := Storage, Scanner
+	Storage = storage.Local{Dir: t.TempDir(), BaseURL: "http://localhost:8080/uploads"}
+	Scanner = rejectingScanner{}
+	t.Cleanup(func() { Storage, Scanner = previous, previousScanner })
+
+	router := setupTransferRouter()
+	router.GET("/events/:id/attachments", getAttachments)
+	router.POST("/events/:id/attachments", uploadAttachment)
+	router.PUT("/events/:id/attachments/:attachmentId", updateAttachment)
+	router.DELETE("/events/:id/attachments/:attachmentId", deleteAttachment)
+	router.GET("/attachments/:attachmentId/download", downloadAttachment)
+	router.GET("/uploads/*key", serveUpload)
+	return router
+}
+
+func listAttachments(t *testing.T, router *gin.Engine, path string, userId int64) []attachmentResponse {
+	req := httptest.NewRequest("GET", path, nil)
+	if userId != 0 {
+		token, err := utils.GenerateToken(userId, "attachments@example.com")
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+		req.Header.Set("Authorization", token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var attachments []attachmentResponse
+	json.Unmarshal(w.Body.Bytes(), &attachments)
+	return attachments
+}
+
+func TestAttachments_VisibilityAndSignedLinks(t *testing.T) {
+	organizerId := createTestUser(t, "attachment-organizer@example.com")
+	attendeeId := createTestUser(t, "attachment-attendee@example.com")
+	strangerId := createTestUser(t, "attachment-stranger@example.com")
+	event := createTestEvent(t, organizerId, time.Now().Add(24*time.Hour))
+	assert.NoError(t, event.Register(attendeeId, nil))
+	path := "/events/" + strconv.FormatInt(event.ID, 10) + "/attachments"
+	router := setupAttachmentRouter(t)
+
+	agenda := []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\n")
+	w := uploadFile(t, router, strangerId, "POST", path, "file", "agenda.pdf", agenda, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = uploadFile(t, router, organizerId, "POST", path, "file", "agenda.pdf", agenda, map[string]string{"visibility": "public"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = uploadFile(t, router, organizerId, "POST", path, "file", "../Waiver (final).csv", []byte("name,signed\n"), nil)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct{ Attachment attachmentResponse }
+	json.Unmarshal(w.Body.Bytes(), &response)
+	waiver := response.Attachment
+	assert.Equal(t, "Waiver (final).csv", waiver.Name)
+	assert.Equal(t, models.AttachmentRegistrants, waiver.Visibility)
+
+	// Registrants-only files are hidden from anonymous users and strangers
+	assert.Len(t, listAttachments(t, router, path, 0), 1)
+	assert.Len(t, listAttachments(t, router, path, strangerId), 1)
+	assert.Len(t, listAttachments(t, router, path, organizerId), 2)
+	attachments := listAttachments(t, router, path, attendeeId)
+	if !assert.Len(t, attachments, 2) {
+		return
+	}
+	assert.Equal(t, "agenda.pdf", attachments[0].Name)
+
+	link := strings.TrimPrefix(attachments[0].URL, "http://localhost:8080")
+	w = sendAs(router, 0, "GET", link, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=agenda.pdf`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, agenda, w.Body.Bytes())
+
+	// Links cannot be altered, and stop working once they expire
+	assert.Equal(t, http.StatusForbidden, sendAs(router, 0, "GET", strings.Replace(link, "/attachments/"+strconv.FormatInt(attachments[0].ID, 10), "/attachments/"+strconv.FormatInt(waiver.ID, 10), 1), nil).Code)
+	expired := time.Now().Add(-time.Minute)
+	expiredLink := fmt.Sprintf("/attachments/%d/download?expires=%d&signature=%s", waiver.ID, expired.Unix(), utils.SignDownload(waiver.ID, expired))
+	assert.Equal(t, http.StatusGone, sendAs(router, 0, "GET", expiredLink, nil).Code)
+
+	// Storage keys are not reachable through the public uploads route
+	storedWaiver, err := models.GetAttachment(waiver.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, sendAs(router, 0, "GET", "/uploads/"+storedWaiver.Key, nil).Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(router, 0, "GET", "/uploads/images/../"+storedWaiver.Key, nil).Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(router, 0, "GET", "/uploads/./"+storedWaiver.Key, nil).Code)
+
+	attachmentPath := path + "/" + strconv.FormatInt(waiver.ID, 10)
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, organizerId, "PUT", attachmentPath, gin.H{"Visibility": "secret"}).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, organizerId, "PUT", attachmentPath, gin.H{"Visibility": "public"}).Code)
+	assert.Len(t, listAttachments(t, router, path, 0), 2)
+
+	assert.Equal(t, http.StatusUnauthorized, sendAs(router, attendeeId, "DELETE", attachmentPath, nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, organizerId, "DELETE", attachmentPath, nil).Code)
+	assert.Len(t, listAttachments(t, router, path, organizerId), 1)
+	assert.Equal(t, http.StatusNotFound, sendAs(router, 0, "GET", strings.TrimPrefix(attachments[1].URL, "http://localhost:8080"), nil).Code)
+}
+
+func TestAttachments_RejectsBadUploads(t *testing.T) {
+	organizerId := createTestUser(t, "attachment-rejects@example.com")
+	event := createTestEvent(t, organizerId, time.Now().Add(24*time.Hour))
+	path := "/events/" + strconv.FormatInt(event.ID, 10) + "/attachments"
+	router := setupAttachmentRouter(t)
+
+	// The contents must match the extension
+	w := uploadFile(t, router, organizerId, "POST", path, "file", "slides.pdf", []byte("MZ\x90\x00 not a pdf"), nil)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	w = uploadFile(t, router, organizerId, "POST", path, "file", "setup.exe", []byte("MZ\x90\x00"), nil)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	w = uploadFile(t, router, organizerId, "POST", path, "file", "notes.txt", []byte("EICAR test file"), nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = uploadFile(t, router, organizerId, "POST", path, "file", "notes.txt", []byte("hello"), map[string]string{"visibility": "everyone"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	big := append([]byte("%PDF-1.4\n"), make([]byte, models.MaxAttachmentSize)...)
+	w = uploadFile(t, router, organizerId, "POST", path, "file", "big.pdf", big, nil)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// Five files just under the per-file limit fill the per-event quota
+	almost := big[:models.MaxAttachmentSize-1]
+	for range models.MaxEventAttachmentsSize / models.MaxAttachmentSize {
+		w = uploadFile(t, router, organizerId, "POST", path, "file", "part.pdf", almost, nil)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+	w = uploadFile(t, router, organizerId, "POST", path, "file", "one-more.pdf", big[:100], nil)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "100MB")
+}
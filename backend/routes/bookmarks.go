@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"event-planner/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func bookmarkEvent(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	_, ok = getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	err := models.AddBookmark(context.GetInt64("userId"), eventId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not bookmark event"})
+		return
+	}
+	context.JSON(http.StatusOK, gin.H{"message": "Event bookmarked"})
+}
+
+func removeBookmark(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	err := models.RemoveBookmark(context.GetInt64("userId"), eventId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not remove bookmark"})
+		return
+	}
+	context.JSON(http.StatusOK, gin.H{"message": "Bookmark removed"})
+}
+
+func getMyBookmarks(context *gin.Context) {
+	events, err := models.GetBookmarkedEvents(context.GetInt64("userId"))
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch bookmarks"})
+		return
+	}
+	context.JSON(http.StatusOK, events)
+}
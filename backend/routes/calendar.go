@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"event-planner/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Helper function to pick the calendar's time zone: ?tz=, else the signed-in
+// user's, else UTC
+func calendarLocation(context *gin.Context) (*time.Location, bool) {
+	name := context.Query("tz")
+	if name == "" {
+		name = "UTC"
+		if userId := optionalUserID(context); userId != 0 {
+			settings, err := models.GetNotificationSettings(userId)
+			if err == nil {
+				name = settings.TimeZone
+			}
+		}
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Unknown time zone"})
+		return nil, false
+	}
+	return loc, true
+}
+
+// getCalendar returns the ?view=month|week|day (default month) containing
+// ?date=YYYY-MM-DD (default today), bucketed by local day. It takes the
+// same ?q=, ?category= and ?tag= filters as GET /events.
+func getCalendar(context *gin.Context) {
+	filter, ok := parseEventFilter(context)
+	if !ok {
+		return
+	}
+
+	loc, ok := calendarLocation(context)
+	if !ok {
+		return
+	}
+
+	date := time.Now().In(loc)
+	if value := context.Query("date"); value != "" {
+		var err error
+		date, err = time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse date, use YYYY-MM-DD"})
+			return
+		}
+	}
+
+	view := context.DefaultQuery("view", models.IntervalMonth)
+	if view != models.IntervalDay && view != models.IntervalWeek && view != models.IntervalMonth {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "view must be day, week or month"})
+		return
+	}
+
+	calendar, err := models.GetCalendar(view, date, filter)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch calendar"})
+		return
+	}
+	context.JSON(http.StatusOK, calendar)
+}
@@ -0,0 +1,135 @@
+package routes
+
+import (
+	"encoding/json"
+	"event-planner/db"
+	"event-planner/models"
+	"event-planner/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCalendarRouter() *gin.Engine {
+	router := setupTransferRouter()
+	router.GET("/calendar", getCalendar)
+	return router
+}
+
+func getCalendarAs(t *testing.T, router *gin.Engine, token, query string) models.Calendar {
+	req := httptest.NewRequest("GET", "/calendar"+query, nil)
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to fetch calendar: %s", w.Body.String())
+	}
+	var calendar models.Calendar
+	json.Unmarshal(w.Body.Bytes(), &calendar)
+	return calendar
+}
+
+// Helper to list the names and local start times of a day's entries
+func dayEntries(calendar models.Calendar, date string) []string {
+	entries := []string{}
+	for _, day := range calendar.Days {
+		if day.Date == date {
+			for _, entry := range day.Entries {
+				entries = append(entries, entry.Event.Name+" "+entry.Start.Format("15:04"))
+			}
+		}
+	}
+	return entries
+}
+
+func TestCalendar_ExpandsRecurrenceAcrossDaylightSaving(t *testing.T) {
+	router := setupCalendarRouter()
+	organizerId := createTestUser(t, "calendar-organizer@example.com")
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	// Clocks go back in New York on 2 November 2031
+	weekly := models.Event{
+		Name:        "Chess Club",
+		Description: "Test Description",
+		Location:    "Library",
+		DateTime:    time.Date(2031, 10, 20, 18, 0, 0, 0, newYork),
+		UserID:      organizerId,
+		Recurrence:  &models.Recurrence{Frequency: models.RecurWeekly, Count: 3},
+	}
+	assert.NoError(t, weekly.Validate())
+	assert.NoError(t, weekly.Save())
+
+	// Spans midnight, so it shows on both days
+	end := time.Date(2031, 10, 26, 2, 0, 0, 0, newYork)
+	overnight := models.Event{
+		Name:        "Hackathon",
+		Description: "Test Description",
+		Location:    "Lab",
+		DateTime:    time.Date(2031, 10, 25, 20, 0, 0, 0, newYork),
+		EndTime:     &end,
+		UserID:      organizerId,
+	}
+	assert.NoError(t, overnight.Save())
+
+	calendar := getCalendarAs(t, router, "", "?view=month&date=2031-10-01&tz=America/New_York")
+	assert.Equal(t, "America/New_York", calendar.TimeZone)
+	assert.Len(t, calendar.Days, 31)
+	assert.Equal(t, []string{"Chess Club 18:00"}, dayEntries(calendar, "2031-10-20"))
+	assert.Equal(t, []string{"Chess Club 18:00"}, dayEntries(calendar, "2031-10-27"))
+	assert.Equal(t, []string{"Hackathon 20:00"}, dayEntries(calendar, "2031-10-25"))
+	assert.Equal(t, []string{"Hackathon 20:00"}, dayEntries(calendar, "2031-10-26"))
+
+	// The third occurrence keeps its local time after the change; there is no fourth
+	calendar = getCalendarAs(t, router, "", "?view=week&date=2031-11-05&tz=America/New_York")
+	assert.Len(t, calendar.Days, 7)
+	assert.Equal(t, "2031-11-03", calendar.Days[0].Date)
+	assert.Equal(t, []string{"Chess Club 18:00"}, dayEntries(calendar, "2031-11-03"))
+	calendar = getCalendarAs(t, router, "", "?view=week&date=2031-11-10&tz=America/New_York")
+	assert.Empty(t, dayEntries(calendar, "2031-11-10"))
+
+	// Days are cut in the requested time zone
+	calendar = getCalendarAs(t, router, "", "?view=day&date=2031-10-21&tz=Asia/Tokyo")
+	assert.Equal(t, []string{"Chess Club 07:00"}, dayEntries(calendar, "2031-10-21"))
+}
+
+func TestCalendar_DefaultsAndValidation(t *testing.T) {
+	router := setupCalendarRouter()
+	userId := createTestUser(t, "calendar-user@example.com")
+	_, err := db.DB.Exec("UPDATE users SET timezone = 'Europe/Paris' WHERE id = ?", userId)
+	assert.NoError(t, err)
+
+	monthly := models.Event{
+		Name:        "Book Swap",
+		Description: "Test Description",
+		Location:    "Cafe",
+		DateTime:    time.Date(2032, 1, 31, 12, 0, 0, 0, time.UTC),
+		UserID:      userId,
+		Recurrence:  &models.Recurrence{Frequency: models.RecurMonthly},
+	}
+	assert.NoError(t, monthly.Save())
+
+	token, _ := utils.GenerateToken(userId, "calendar-user@example.com")
+	calendar := getCalendarAs(t, router, token, "?date=2032-02-10")
+	assert.Equal(t, "Europe/Paris", calendar.TimeZone)
+	assert.Equal(t, models.IntervalMonth, calendar.View)
+	// February has no 31st, so the series skips it
+	for _, day := range calendar.Days {
+		assert.Empty(t, day.Entries, day.Date)
+	}
+	calendar = getCalendarAs(t, router, token, "?date=2032-03-10")
+	assert.Equal(t, []string{"Book Swap 13:00"}, dayEntries(calendar, "2032-03-31"))
+
+	for _, query := range []string{"?view=year", "?date=10/03/2032", "?tz=Mars/Olympus"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/calendar"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	assert.Error(t, (&models.Recurrence{Frequency: "yearly"}).Validate(monthly.DateTime))
+}
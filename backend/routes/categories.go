@@ -0,0 +1,150 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"event-planner/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Helper function to load the category in the URL
+func getCategoryParam(context *gin.Context) (*models.Category, bool) {
+	categoryId, err := strconv.ParseInt(context.Param("categoryId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse category id"})
+		return nil, false
+	}
+
+	category, err := models.GetCategory(categoryId)
+	if errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Category not found"})
+		return nil, false
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch category"})
+		return nil, false
+	}
+	return category, true
+}
+
+func getCategories(context *gin.Context) {
+	categories, err := models.GetCategories()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch categories"})
+		return
+	}
+	context.JSON(http.StatusOK, categories)
+}
+
+func createCategory(context *gin.Context) {
+	var category models.Category
+	err := context.ShouldBindJSON(&category)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	category.ID = 0
+	err = category.Validate()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	err = category.Save()
+	if errors.Is(err, models.ErrCategoryExists) {
+		context.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create category"})
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Category created", "category": category})
+}
+
+func updateCategory(context *gin.Context) {
+	existing, ok := getCategoryParam(context)
+	if !ok {
+		return
+	}
+
+	var category models.Category
+	err := context.ShouldBindJSON(&category)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	category.ID = existing.ID
+	err = category.Validate()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	err = category.Update()
+	if errors.Is(err, models.ErrCategoryExists) {
+		context.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update category"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Category updated", "category": category})
+}
+
+func deleteCategory(context *gin.Context) {
+	category, ok := getCategoryParam(context)
+	if !ok {
+		return
+	}
+
+	err := category.Delete()
+	if errors.Is(err, models.ErrCategoryInUse) {
+		context.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete category"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Category deleted"})
+}
+
+// getEventFacets counts the events matching the same filters as GetEvents by
+// category, tag and start date. Dates are bucketed by ?interval=day, week or
+// month (the default) in the IANA time zone ?tz=, UTC by default.
+func getEventFacets(context *gin.Context) {
+	filter, ok := parseEventFilter(context)
+	if !ok {
+		return
+	}
+
+	loc, err := time.LoadLocation(context.DefaultQuery("tz", "UTC"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Unknown time zone"})
+		return
+	}
+
+	interval := context.DefaultQuery("interval", models.IntervalMonth)
+	if interval != models.IntervalDay && interval != models.IntervalWeek && interval != models.IntervalMonth {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "interval must be day, week or month"})
+		return
+	}
+
+	facets, err := models.Facets(filter, interval, loc)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not count events"})
+		return
+	}
+	context.JSON(http.StatusOK, facets)
+}
@@ -0,0 +1,176 @@
+package routes
+
+import (
+	"encoding/json"
+	"event-planner/models"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCategoryRouter() *gin.Engine {
+	router := setupTransferRouter()
+	router.GET("/events", GetEvents)
+	router.GET("/events/facets", getEventFacets)
+	router.POST("/events", CreateEvent)
+	router.PUT("/events/:id", UpdateEvent)
+	router.GET("/categories", getCategories)
+	router.POST("/admin/categories", createCategory)
+	router.PUT("/admin/categories/:categoryId", updateCategory)
+	router.DELETE("/admin/categories/:categoryId", deleteCategory)
+	return router
+}
+
+func createCategoryAs(t *testing.T, router *gin.Engine, body gin.H) models.Category {
+	w := sendAs(router, 0, "POST", "/admin/categories", body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create category: %s", w.Body.String())
+	}
+	var response struct{ Category models.Category }
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response.Category
+}
+
+func createTaggedEvent(t *testing.T, router *gin.Engine, userId int64, categoryId int64, start time.Time, tags ...string) models.Event {
+	w := sendAs(router, userId, "POST", "/events", gin.H{
+		"Name":        "Tagged Event",
+		"Description": "Test Description",
+		"Location":    "Test Location",
+		"DateTime":    start,
+		"CategoryID":  categoryId,
+		"Tags":        tags,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create event: %s", w.Body.String())
+	}
+	var response struct{ Event models.Event }
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response.Event
+}
+
+func TestCategories_ManageTaxonomy(t *testing.T) {
+	router := setupCategoryRouter()
+
+	arts := createCategoryAs(t, router, gin.H{"Name": "Arts & Culture"})
+	assert.Equal(t, "arts-culture", arts.Slug)
+
+	theatre := createCategoryAs(t, router, gin.H{"Name": "Theatre", "ParentID": arts.ID})
+	assert.Equal(t, arts.ID, *theatre.ParentID)
+
+	assert.Equal(t, http.StatusConflict, sendAs(router, 0, "POST", "/admin/categories", gin.H{"Name": "Arts Culture"}).Code)
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, 0, "POST", "/admin/categories", gin.H{"Name": "Film", "Slug": "Film!"}).Code)
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, 0, "POST", "/admin/categories", gin.H{"Name": "Film", "ParentID": 99999}).Code)
+
+	// A category cannot move under its own descendant
+	artsPath := "/admin/categories/" + strconv.FormatInt(arts.ID, 10)
+	w := sendAs(router, 0, "PUT", artsPath, gin.H{"Name": "Arts & Culture", "ParentID": theatre.ID})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, 0, "PUT", artsPath, gin.H{"Name": "The Arts"}).Code)
+
+	var categories []models.Category
+	w = sendAs(router, 0, "GET", "/categories", nil)
+	json.Unmarshal(w.Body.Bytes(), &categories)
+	assert.Contains(t, categories, models.Category{ID: arts.ID, Slug: "the-arts", Name: "The Arts"})
+
+	// Categories in use cannot be deleted
+	assert.Equal(t, http.StatusConflict, sendAs(router, 0, "DELETE", artsPath, nil).Code)
+	theatrePath := "/admin/categories/" + strconv.FormatInt(theatre.ID, 10)
+	assert.Equal(t, http.StatusOK, sendAs(router, 0, "DELETE", theatrePath, nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, 0, "DELETE", artsPath, nil).Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(router, 0, "DELETE", artsPath, nil).Code)
+}
+
+func TestEvents_FilterAndFacets(t *testing.T) {
+	router := setupCategoryRouter()
+	userId := createTestUser(t, "facets@example.com")
+
+	sports := createCategoryAs(t, router, gin.H{"Name": "Sports"})
+	running := createCategoryAs(t, router, gin.H{"Name": "Running", "ParentID": sports.ID})
+	chess := createCategoryAs(t, router, gin.H{"Name": "Chess"})
+
+	october := time.Date(2031, 10, 30, 18, 0, 0, 0, time.UTC)
+	november := time.Date(2031, 11, 2, 9, 0, 0, 0, time.UTC)
+	marathon := createTaggedEvent(t, router, userId, running.ID, october, "Outdoor", "beginner friendly", "outdoor")
+	assert.Equal(t, []string{"outdoor", "beginner-friendly"}, marathon.Tags)
+	createTaggedEvent(t, router, userId, sports.ID, november, "outdoor")
+	createTaggedEvent(t, router, userId, chess.ID, november, "indoor", "beginner-friendly")
+
+	findEvents := func(query string) []models.Event {
+		var events []models.Event
+		w := sendAs(router, 0, "GET", "/events?"+query, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		json.Unmarshal(w.Body.Bytes(), &events)
+		return events
+	}
+
+	// Filtering by a category includes its subcategories
+	assert.Len(t, findEvents("category=sports"), 2)
+	assert.Len(t, findEvents("category=running"), 1)
+	events := findEvents("category=sports&tag=beginner-friendly")
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, marathon.ID, events[0].ID)
+		assert.Equal(t, running.ID, *events[0].CategoryID)
+	}
+	assert.Len(t, findEvents("tag=Beginner+Friendly&tag=indoor"), 1)
+	assert.Len(t, findEvents("category=sports&from=2031-11-01T00:00:00Z"), 1)
+	assert.Len(t, findEvents("category=sports&to=2031-10-31T00:00:00%2B02:00"), 1)
+	assert.Empty(t, findEvents("category=no-such-category"))
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, 0, "GET", "/events?from=tomorrow", nil).Code)
+
+	var facets models.EventFacets
+	w := sendAs(router, 0, "GET", "/events/facets?tag=beginner-friendly", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &facets)
+	assert.Equal(t, 2, facets.Total)
+	assert.ElementsMatch(t, []models.CategoryFacet{{Slug: "running", Name: "Running", Count: 1}, {Slug: "chess", Name: "Chess", Count: 1}}, facets.Categories)
+	assert.Equal(t, models.TagFacet{Tag: "beginner-friendly", Count: 2}, facets.Tags[0])
+	if assert.Len(t, facets.Dates, 2) {
+		assert.Equal(t, "2031-10", facets.Dates[0].Bucket)
+		assert.Equal(t, "2031-11", facets.Dates[1].Bucket)
+	}
+
+	// Buckets follow the requested time zone
+	w = sendAs(router, 0, "GET", "/events/facets?category=sports&interval=week&tz=Pacific/Auckland", nil)
+	json.Unmarshal(w.Body.Bytes(), &facets)
+	if assert.Len(t, facets.Dates, 1) {
+		assert.Equal(t, "2031-10-27", facets.Dates[0].Bucket)
+		assert.Equal(t, 2, facets.Dates[0].Count)
+	}
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, 0, "GET", "/events/facets?interval=year", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, 0, "GET", "/events/facets?tz=Mars/Olympus", nil).Code)
+}
+
+func TestEvents_RejectsInvalidTaxonomy(t *testing.T) {
+	router := setupCategoryRouter()
+	userId := createTestUser(t, "taxonomy@example.com")
+	event := gin.H{
+		"Name":        "Test Event",
+		"Description": "Test Description",
+		"Location":    "Test Location",
+		"DateTime":    time.Now().Add(24 * time.Hour),
+		"CategoryID":  99999,
+	}
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, userId, "POST", "/events", event).Code)
+
+	delete(event, "CategoryID")
+	event["Tags"] = []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, userId, "POST", "/events", event).Code)
+
+	event["Tags"] = []string{"music"}
+	w := sendAs(router, userId, "POST", "/events", event)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response struct{ Event models.Event }
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	// Updates replace the tags
+	event["Tags"] = []string{"Live Music"}
+	path := "/events/" + strconv.FormatInt(response.Event.ID, 10)
+	assert.Equal(t, http.StatusOK, sendAs(router, userId, "PUT", path, event).Code)
+	updated, err := models.GetEventByID(response.Event.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"live-music"}, updated.Tags)
+}
@@ -0,0 +1,249 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"event-planner/export"
+	"event-planner/models"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PublicURL is the address printed on certificates for verification. main
+// sets it from PUBLIC_URL.
+var PublicURL = "http://localhost:8080"
+
+// Helper function to build the link that verifies a certificate code
+func certificateVerifyURL(code string) string {
+	return strings.TrimRight(PublicURL, "/") + "/certificates/" + code
+}
+
+// Helper function to load an event's certificate template
+func getEventCertificateTemplate(context *gin.Context, eventId int64) (*models.CertificateTemplate, bool) {
+	template, err := models.GetCertificateTemplate(eventId)
+	if errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "This event does not issue certificates"})
+		return nil, false
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch certificate template"})
+		return nil, false
+	}
+	return template, true
+}
+
+func getCertificateTemplate(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventOrganizer(context, event, context.GetInt64("userId"), "manage certificates") {
+		return
+	}
+
+	template, ok := getEventCertificateTemplate(context, eventId)
+	if !ok {
+		return
+	}
+	context.JSON(http.StatusOK, template)
+}
+
+func saveCertificateTemplate(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventOrganizer(context, event, context.GetInt64("userId"), "manage certificates") {
+		return
+	}
+
+	var template models.CertificateTemplate
+	err := context.ShouldBindJSON(&template)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	err = template.Validate()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	template.EventID = eventId
+	err = template.Save()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not save certificate template"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Certificate template saved"})
+}
+
+// downloadCertificate issues the user's certificate on first download and
+// returns it as a PDF. It is made out to the name the organizer approved, or
+// the user's masked email.
+func downloadCertificate(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	template, ok := getEventCertificateTemplate(context, eventId)
+	if !ok {
+		return
+	}
+
+	certificate, err := models.IssueCertificate(*event, context.GetInt64("userId"), time.Now())
+	if errors.Is(err, models.ErrNotRegistered) {
+		context.JSON(http.StatusForbidden, gin.H{"message": "Certificates are only issued to checked-in attendees"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not issue certificate"})
+		return
+	}
+
+	signatures := make([]export.CertificateSignature, len(template.Signatures))
+	for i, signature := range template.Signatures {
+		signatures[i] = export.CertificateSignature{Name: signature.Name, Role: signature.Role}
+	}
+
+	context.Header("Content-Type", "application/pdf")
+	context.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="certificate-%s.pdf"`, certificate.Code))
+	context.Status(http.StatusOK)
+
+	err = export.WriteCertificate(context.Writer, export.Certificate{
+		Title:      template.Title,
+		Recipient:  certificate.RecipientName,
+		Body:       template.BodyFor(*event),
+		Signatures: signatures,
+		Logo:       template.Logo,
+		Code:       certificate.Code,
+		VerifyURL:  certificateVerifyURL(certificate.Code),
+	})
+	if err != nil {
+		log.Printf("could not render certificate %s: %v", certificate.Code, err)
+	}
+}
+
+func getEventCertificates(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventOrganizer(context, event, context.GetInt64("userId"), "view certificates") {
+		return
+	}
+
+	certificates, err := event.Certificates()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch certificates"})
+		return
+	}
+	context.JSON(http.StatusOK, certificates)
+}
+
+type certificateNameRequest struct {
+	Name string `binding:"required"`
+}
+
+// nameCertificate lets organizers set the name printed on an attendee's
+// certificate, such as the full name the attendee asked for.
+func nameCertificate(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	attendeeId, err := strconv.ParseInt(context.Param("userId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse user id"})
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventOrganizer(context, event, context.GetInt64("userId"), "name certificates") {
+		return
+	}
+
+	var request certificateNameRequest
+	err = context.ShouldBindJSON(&request)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	certificate, err := models.NameCertificate(*event, attendeeId, request.Name, time.Now())
+	if errors.Is(err, models.ErrNotRegistered) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Certificates are only issued to checked-in attendees"})
+		return
+	}
+	if errors.Is(err, models.ErrCertificateName) {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not name certificate"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Certificate name saved", "certificate": certificate})
+}
+
+// verifyCertificate lets anyone holding a certificate code confirm it was
+// issued, and to whom, without logging in.
+func verifyCertificate(context *gin.Context) {
+	certificate, err := models.GetCertificateByCode(context.Param("code"))
+	if err != nil {
+		context.JSON(http.StatusNotFound, gin.H{"message": "No certificate was issued with this code", "valid": false})
+		return
+	}
+
+	event, ok := getEventByID(context, certificate.EventID)
+	if !ok {
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{
+		"valid":     true,
+		"code":      certificate.Code,
+		"recipient": certificate.RecipientName,
+		"event":     event.Name,
+		"eventDate": event.DateTime,
+		"issuedAt":  certificate.IssuedAt,
+	})
+}
@@ -0,0 +1,117 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"event-planner/models"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func testLogo(t *testing.T) []byte {
+	logo := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for x := range 40 {
+		logo.Set(x, 10, color.RGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	err := png.Encode(&buf, logo)
+	if err != nil {
+		t.Fatalf("Failed to encode logo: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCertificates_IssueAndVerify(t *testing.T) {
+	organizerId := createTestUser(t, "certificate-organizer@example.com")
+	attendeeId := createTestUser(t, "certificate-attendee@example.com")
+	event := createTestEvent(t, organizerId, time.Now().Add(-3*time.Hour))
+	basePath := "/events/" + strconv.FormatInt(event.ID, 10)
+	assert.NoError(t, event.Register(attendeeId, nil))
+
+	router := setupTransferRouter()
+	router.POST("/events/:id/registrations/:userId/check-in", checkInAttendee)
+	router.PUT("/events/:id/certificate-template", saveCertificateTemplate)
+	router.GET("/events/:id/certificate", downloadCertificate)
+	router.GET("/events/:id/certificates", getEventCertificates)
+	router.PUT("/events/:id/certificates/:userId", nameCertificate)
+	router.GET("/certificates/:code", verifyCertificate)
+
+	assert.Equal(t, http.StatusNotFound, sendAs(router, attendeeId, "GET", basePath+"/certificate", nil).Code)
+
+	template := gin.H{
+		"Title":      "Certificate of Attendance",
+		"Body":       "for completing the {event} workshop on {date}.",
+		"Signatures": []gin.H{{"Name": "Dr. Grace Hopper", "Role": "Workshop Lead"}},
+		"Logo":       []byte("not an image"),
+	}
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, organizerId, "PUT", basePath+"/certificate-template", template).Code)
+	template["Logo"] = testLogo(t)
+	assert.Equal(t, http.StatusUnauthorized, sendAs(router, attendeeId, "PUT", basePath+"/certificate-template", template).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, organizerId, "PUT", basePath+"/certificate-template", template).Code)
+
+	// Only checked-in attendees get a certificate
+	assert.Equal(t, http.StatusForbidden, sendAs(router, attendeeId, "GET", basePath+"/certificate", nil).Code)
+	w := sendAs(router, organizerId, "POST", basePath+"/registrations/"+strconv.FormatInt(attendeeId, 10)+"/check-in", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Attendees cannot choose the name themselves
+	w = sendAs(router, attendeeId, "GET", basePath+"/certificate?name=Someone+Else", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.True(t, bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")))
+
+	var certificates []models.Certificate
+	w = sendAs(router, organizerId, "GET", basePath+"/certificates", nil)
+	json.Unmarshal(w.Body.Bytes(), &certificates)
+	if !assert.Len(t, certificates, 1) {
+		return
+	}
+	assert.Equal(t, "c***@example.com", certificates[0].RecipientName)
+	assert.NotContains(t, sendAs(router, 0, "GET", "/certificates/"+certificates[0].Code, nil).Body.String(), "certificate-attendee")
+	assert.Contains(t, w.Header().Get("Content-Type"), "json")
+
+	// Organizers approve the full name
+	namePath := basePath + "/certificates/" + strconv.FormatInt(attendeeId, 10)
+	assert.Equal(t, http.StatusUnauthorized, sendAs(router, attendeeId, "PUT", namePath, gin.H{"Name": "Someone Else"}).Code)
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, organizerId, "PUT", namePath, gin.H{"Name": strings.Repeat("x", 101)}).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, organizerId, "PUT", namePath, gin.H{"Name": " Ada Lovelace "}).Code)
+
+	w = sendAs(router, organizerId, "GET", basePath+"/certificates", nil)
+	json.Unmarshal(w.Body.Bytes(), &certificates)
+	assert.Equal(t, "Ada Lovelace", certificates[0].RecipientName)
+
+	// The sign-in sheet uses the approved name
+	_, row, err := newRegistrationTable(io.Discard, &event, nil, "pdf")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Ada Lovelace", row(models.Registration{UserID: attendeeId, Email: "certificate-attendee@example.com"}, 1)[1])
+		assert.Equal(t, "other@example.com", row(models.Registration{UserID: organizerId, Email: "other@example.com"}, 2)[1])
+	}
+
+	code := certificates[0].Code
+	w = sendAs(router, 0, "GET", "/certificates/"+strings.ToLower(strings.ReplaceAll(code, "-", "")), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var verification struct {
+		Valid     bool   `json:"valid"`
+		Code      string `json:"code"`
+		Recipient string `json:"recipient"`
+		Event     string `json:"event"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &verification)
+	assert.True(t, verification.Valid)
+	assert.Equal(t, code, verification.Code)
+	assert.Equal(t, "Ada Lovelace", verification.Recipient)
+	assert.Equal(t, event.Name, verification.Event)
+
+	assert.Equal(t, http.StatusNotFound, sendAs(router, 0, "GET", "/certificates/AAAAA-BBBBB", nil).Code)
+}
@@ -0,0 +1,302 @@
+package routes
+
+import (
+	"errors"
+	"event-planner/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Each user may post at most commentRateLimit comments per commentRateWindow.
+var (
+	commentRateLimit  = 5
+	commentRateWindow = time.Minute
+)
+
+const maxCommentLength = 2000
+
+type commentRequest struct {
+	Body     string `binding:"required"`
+	ParentID *int64
+}
+
+type reportRequest struct {
+	Reason string `binding:"required"`
+}
+
+// Helper function to load the comment in the URL and check it belongs to the event
+func getEventComment(context *gin.Context, eventId int64) (*models.Comment, bool) {
+	commentId, err := strconv.ParseInt(context.Param("commentId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse comment id"})
+		return nil, false
+	}
+
+	comment, err := models.GetCommentByID(commentId)
+	if err != nil || comment.EventID != eventId {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Comment not found"})
+		return nil, false
+	}
+	return comment, true
+}
+
+// Helper function to validate and trim a comment body
+func parseCommentBody(context *gin.Context, body string) (string, bool) {
+	body = strings.TrimSpace(body)
+	if body == "" || len(body) > maxCommentLength {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Comment must be between 1 and 2000 characters"})
+		return "", false
+	}
+	return body, true
+}
+
+func getComments(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	if _, ok := getEventByID(context, eventId); !ok {
+		return
+	}
+
+	comments, err := models.GetCommentThread(eventId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch comments"})
+		return
+	}
+	context.JSON(http.StatusOK, comments)
+}
+
+func createComment(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	var request commentRequest
+	err := context.ShouldBindJSON(&request)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	body, ok := parseCommentBody(context, request.Body)
+	if !ok {
+		return
+	}
+
+	if _, ok := getEventByID(context, eventId); !ok {
+		return
+	}
+
+	if request.ParentID != nil {
+		parent, err := models.GetCommentByID(*request.ParentID)
+		if err != nil || parent.EventID != eventId {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "Parent comment not found on this event"})
+			return
+		}
+	}
+
+	userId := context.GetInt64("userId")
+	recent, err := models.CountCommentsSince(userId, time.Now().Add(-commentRateWindow))
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create comment"})
+		return
+	}
+
+	if recent >= commentRateLimit {
+		context.Header("Retry-After", strconv.Itoa(int(commentRateWindow.Seconds())))
+		context.JSON(http.StatusTooManyRequests, gin.H{"message": "You are commenting too quickly, please wait a moment"})
+		return
+	}
+
+	comment := models.Comment{EventID: eventId, UserID: userId, ParentID: request.ParentID, Body: body}
+	err = comment.Save()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create comment"})
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Comment created successfully", "comment": comment})
+}
+
+func updateComment(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	comment, ok := getEventComment(context, eventId)
+	if !ok {
+		return
+	}
+
+	if comment.UserID != context.GetInt64("userId") || comment.Deleted || comment.Hidden {
+		context.JSON(http.StatusUnauthorized, gin.H{"message": "You are not authorized to edit this comment"})
+		return
+	}
+
+	var request commentRequest
+	err := context.ShouldBindJSON(&request)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	body, ok := parseCommentBody(context, request.Body)
+	if !ok {
+		return
+	}
+
+	err = comment.Edit(body)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update comment"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Comment updated successfully", "comment": comment})
+}
+
+func deleteComment(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	comment, ok := getEventComment(context, eventId)
+	if !ok {
+		return
+	}
+
+	if comment.UserID != context.GetInt64("userId") {
+		context.JSON(http.StatusUnauthorized, gin.H{"message": "You are not authorized to delete this comment"})
+		return
+	}
+
+	err := comment.Delete()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete comment"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+func pinComment(context *gin.Context) {
+	setCommentPinned(context, true)
+}
+
+func unpinComment(context *gin.Context) {
+	setCommentPinned(context, false)
+}
+
+// Helper function to pin or unpin a top-level comment as the event organizer
+func setCommentPinned(context *gin.Context, pinned bool) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventOrganizer(context, event, context.GetInt64("userId"), "pin comments") {
+		return
+	}
+
+	comment, ok := getEventComment(context, eventId)
+	if !ok {
+		return
+	}
+
+	if comment.ParentID != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Only top-level comments can be pinned"})
+		return
+	}
+
+	err := comment.SetPinned(pinned)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update comment"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Comment updated successfully", "comment": comment})
+}
+
+func reportComment(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	comment, ok := getEventComment(context, eventId)
+	if !ok {
+		return
+	}
+
+	var request reportRequest
+	err := context.ShouldBindJSON(&request)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Please give a reason for the report"})
+		return
+	}
+
+	err = comment.Report(context.GetInt64("userId"), strings.TrimSpace(request.Reason))
+	if errors.Is(err, models.ErrAlreadyReported) {
+		context.JSON(http.StatusConflict, gin.H{"message": "You have already reported this comment"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not report comment"})
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Comment reported, a moderator will review it"})
+}
+
+func getModerationQueue(context *gin.Context) {
+	queue, err := models.GetModerationQueue()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch moderation queue"})
+		return
+	}
+	context.JSON(http.StatusOK, queue)
+}
+
+func hideComment(context *gin.Context) {
+	moderateComment(context, models.ResolutionHidden)
+}
+
+func dismissCommentReports(context *gin.Context) {
+	moderateComment(context, models.ResolutionDismissed)
+}
+
+// Helper function to resolve the open reports on a comment
+func moderateComment(context *gin.Context, resolution string) {
+	commentId, err := strconv.ParseInt(context.Param("commentId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse comment id"})
+		return
+	}
+
+	comment, err := models.GetCommentByID(commentId)
+	if err != nil {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Comment not found"})
+		return
+	}
+
+	err = comment.Moderate(context.GetInt64("userId"), resolution)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not moderate comment"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Comment moderated", "comment": comment})
+}
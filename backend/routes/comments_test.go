@@ -0,0 +1,164 @@
+package routes
+
+import (
+	"encoding/json"
+	"event-planner/db"
+	"event-planner/middlewares"
+	"event-planner/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCommentsRouter(userId int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/events/:id/comments", getComments)
+	authenticated := router.Group("/", func(c *gin.Context) {
+		c.Set("userId", userId)
+	})
+	authenticated.POST("/events/:id/comments", createComment)
+	authenticated.PUT("/events/:id/comments/:commentId", updateComment)
+	authenticated.DELETE("/events/:id/comments/:commentId", deleteComment)
+	authenticated.POST("/events/:id/comments/:commentId/pin", pinComment)
+	authenticated.POST("/events/:id/comments/:commentId/report", reportComment)
+
+	admin := authenticated.Group("/admin", middlewares.RequireAdmin)
+	admin.GET("/moderation/comments", getModerationQueue)
+	admin.POST("/moderation/comments/:commentId/hide", hideComment)
+	return router
+}
+
+func postComment(t *testing.T, router *gin.Engine, eventId int64, body gin.H) (int, models.Comment) {
+	w := sendAs(router, 0, "POST", "/events/"+strconv.FormatInt(eventId, 10)+"/comments", body)
+
+	var response struct{ Comment models.Comment }
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w.Code, response.Comment
+}
+
+func getThread(t *testing.T, eventId int64) []models.Comment {
+	req, _ := http.NewRequest("GET", "/events/"+strconv.FormatInt(eventId, 10)+"/comments", nil)
+	w := httptest.NewRecorder()
+	setupCommentsRouter(0).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var thread []models.Comment
+	err := json.Unmarshal(w.Body.Bytes(), &thread)
+	if err != nil {
+		t.Fatalf("Failed to parse comments: %v", err)
+	}
+	return thread
+}
+
+func TestComments_ThreadsAndPinning(t *testing.T) {
+	organizerId := createTestUser(t, "comments-organizer@example.com")
+	authorId := createTestUser(t, "comments-author@example.com")
+	event := createTestEvent(t, organizerId, time.Now().Add(24*time.Hour))
+	author := setupCommentsRouter(authorId)
+	organizer := setupCommentsRouter(organizerId)
+
+	code, first := postComment(t, author, event.ID, gin.H{"Body": "Is there parking?"})
+	assert.Equal(t, http.StatusCreated, code)
+	_, second := postComment(t, author, event.ID, gin.H{"Body": "Will it be recorded?"})
+	code, reply := postComment(t, organizer, event.ID, gin.H{"Body": "Yes, lot B.", "ParentID": first.ID})
+	assert.Equal(t, http.StatusCreated, code)
+
+	// Only the organizer can pin
+	path := "/events/" + strconv.FormatInt(event.ID, 10) + "/comments/" + strconv.FormatInt(second.ID, 10)
+	assert.Equal(t, http.StatusUnauthorized, sendAs(author, 0, "POST", path+"/pin", nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(organizer, 0, "POST", path+"/pin", nil).Code)
+
+	thread := getThread(t, event.ID)
+	if assert.Len(t, thread, 2) {
+		assert.Equal(t, second.ID, thread[0].ID)
+		assert.True(t, thread[0].Pinned)
+		if assert.Len(t, thread[1].Replies, 1) {
+			assert.Equal(t, reply.ID, thread[1].Replies[0].ID)
+		}
+	}
+
+	// Only the author can edit or delete
+	firstPath := "/events/" + strconv.FormatInt(event.ID, 10) + "/comments/" + strconv.FormatInt(first.ID, 10)
+	req, _ := http.NewRequest("PUT", firstPath, nil)
+	w := httptest.NewRecorder()
+	organizer.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req, _ = http.NewRequest("DELETE", firstPath, nil)
+	w = httptest.NewRecorder()
+	author.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	thread = getThread(t, event.ID)
+	assert.True(t, thread[1].Deleted)
+	assert.Empty(t, thread[1].Body)
+	assert.Len(t, thread[1].Replies, 1)
+}
+
+func TestComments_RateLimited(t *testing.T) {
+	userId := createTestUser(t, "comments-spammer@example.com")
+	event := createTestEvent(t, userId, time.Now().Add(24*time.Hour))
+	router := setupCommentsRouter(userId)
+
+	for i := 0; i < commentRateLimit; i++ {
+		code, _ := postComment(t, router, event.ID, gin.H{"Body": "Hello"})
+		assert.Equal(t, http.StatusCreated, code)
+	}
+
+	w := sendAs(router, 0, "POST", "/events/"+strconv.FormatInt(event.ID, 10)+"/comments", gin.H{"Body": "Hello again"})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+}
+
+func TestComments_ReportAndModerate(t *testing.T) {
+	authorId := createTestUser(t, "comments-troll@example.com")
+	reporterId := createTestUser(t, "comments-reporter@example.com")
+	adminId := createTestUser(t, "comments-admin@example.com")
+	err := models.PromoteAdmins([]string{"comments-admin@example.com"})
+	if err != nil {
+		t.Fatalf("Failed to promote admin: %v", err)
+	}
+
+	event := createTestEvent(t, authorId, time.Now().Add(24*time.Hour))
+	_, comment := postComment(t, setupCommentsRouter(authorId), event.ID, gin.H{"Body": "Something abusive"})
+
+	reporter := setupCommentsRouter(reporterId)
+	reportPath := "/events/" + strconv.FormatInt(event.ID, 10) + "/comments/" + strconv.FormatInt(comment.ID, 10) + "/report"
+	assert.Equal(t, http.StatusCreated, sendAs(reporter, 0, "POST", reportPath, gin.H{"Reason": "Harassment"}).Code)
+	assert.Equal(t, http.StatusConflict, sendAs(reporter, 0, "POST", reportPath, gin.H{"Reason": "Harassment"}).Code)
+
+	// Non-admins cannot see the queue
+	req, _ := http.NewRequest("GET", "/admin/moderation/comments", nil)
+	w := httptest.NewRecorder()
+	reporter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	admin := setupCommentsRouter(adminId)
+	w = httptest.NewRecorder()
+	admin.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var queue []models.ModerationItem
+	json.Unmarshal(w.Body.Bytes(), &queue)
+	if assert.Len(t, queue, 1) {
+		assert.Equal(t, "Something abusive", queue[0].Comment.Body)
+		assert.Equal(t, "Harassment", queue[0].Reports[0].Reason)
+	}
+
+	w = sendAs(admin, 0, "POST", "/admin/moderation/comments/"+strconv.FormatInt(comment.ID, 10)+"/hide", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	thread := getThread(t, event.ID)
+	assert.True(t, thread[0].Hidden)
+	assert.Empty(t, thread[0].Body)
+
+	var open int
+	db.DB.QueryRow("SELECT COUNT(*) FROM comment_reports WHERE resolved_at IS NULL").Scan(&open)
+	assert.Equal(t, 0, open)
+}
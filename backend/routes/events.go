@@ -1,13 +1,32 @@
 package routes
 
 import (
+	"database/sql"
+	"errors"
+	"event-planner/geo"
 	"event-planner/models"
+	"event-planner/notifications"
+	"event-planner/utils"
+	"event-planner/webhooks"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Bounds of the ?radius= of nearby event searches, in meters.
+const (
+	defaultNearRadius = 1000
+	maxNearRadius     = 50000
+)
+
+// Geocoder looks up coordinates for events saved without them. It is nil
+// unless main configures a provider.
+var Geocoder geo.Geocoder
+
 // Helper function to parse event ID from URL parameter
 func parseEventID(context *gin.Context) (int64, bool) {
 	eventId, err := strconv.ParseInt(context.Param("id"), 10, 64)
@@ -28,6 +47,15 @@ func getEventByID(context *gin.Context, eventId int64) (*models.Event, bool) {
 	return event, true
 }
 
+// Helper function to get the user from an optional Authorization header
+func optionalUserID(context *gin.Context) int64 {
+	userId, err := utils.VerifyToken(context.GetHeader("Authorization"))
+	if err != nil {
+		return 0
+	}
+	return userId
+}
+
 // Helper function to check if user is authorized to modify event
 func checkEventAuthorization(context *gin.Context, event *models.Event, userId int64, action string) bool {
 	if event.UserID != userId {
@@ -37,8 +65,152 @@ func checkEventAuthorization(context *gin.Context, event *models.Event, userId i
 	return true
 }
 
+// Helper function to check if user organizes the event, either directly or as
+// owner of the organization it belongs to
+func isEventOrganizer(event *models.Event, userId int64) (bool, error) {
+	if event.UserID == userId {
+		return true, nil
+	}
+	if event.OrganizationID == nil {
+		return false, nil
+	}
+
+	organization, err := models.GetOrganizationByID(*event.OrganizationID)
+	if err != nil {
+		return false, err
+	}
+	return organization.OwnerID == userId, nil
+}
+
+// Helper function to check if user organizes the event and reply if not
+func checkEventOrganizer(context *gin.Context, event *models.Event, userId int64, action string) bool {
+	organizer, err := isEventOrganizer(event, userId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not check event organizer"})
+		return false
+	}
+
+	if !organizer {
+		context.JSON(http.StatusUnauthorized, gin.H{"message": "You are not authorized to " + action + " for this event"})
+		return false
+	}
+	return true
+}
+
+// Helper function to read an optional RFC 3339 time from the query string
+func parseTimeQuery(context *gin.Context, name string) (*time.Time, bool) {
+	value := context.Query(name)
+	if value == "" {
+		return nil, true
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse " + name + ", use RFC 3339"})
+		return nil, false
+	}
+	return &t, true
+}
+
+// Helper function to read the optional ?near=lat,lng and ?radius= in meters
+func parseNearQuery(context *gin.Context) (*geo.Point, float64, bool) {
+	near := context.Query("near")
+	if near == "" {
+		return nil, 0, true
+	}
+
+	latitude, longitude, found := strings.Cut(near, ",")
+	lat, latErr := strconv.ParseFloat(strings.TrimSpace(latitude), 64)
+	lng, lngErr := strconv.ParseFloat(strings.TrimSpace(longitude), 64)
+	point := geo.Point{Latitude: lat, Longitude: lng}
+	if !found || latErr != nil || lngErr != nil || !point.Valid() {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "near must be latitude,longitude in decimal degrees"})
+		return nil, 0, false
+	}
+
+	radius, err := strconv.ParseFloat(context.DefaultQuery("radius", strconv.Itoa(defaultNearRadius)), 64)
+	if err != nil || radius <= 0 || radius > maxNearRadius {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "radius must be between 0 and " + strconv.Itoa(maxNearRadius) + " meters"})
+		return nil, 0, false
+	}
+	return &point, radius, true
+}
+
+// Helper function to read the q, category, tag, from, to, near and radius
+// query parameters
+func parseEventFilter(context *gin.Context) (models.EventFilter, bool) {
+	filter := models.EventFilter{
+		Query:    context.Query("q"),
+		Category: context.Query("category"),
+		Tags:     context.QueryArray("tag"),
+	}
+
+	var ok bool
+	filter.From, ok = parseTimeQuery(context, "from")
+	if !ok {
+		return filter, false
+	}
+	filter.To, ok = parseTimeQuery(context, "to")
+	if !ok {
+		return filter, false
+	}
+	filter.Near, filter.Radius, ok = parseNearQuery(context)
+	return filter, ok
+}
+
+// Helper function to normalize an event's tags and check its category exists
+func checkEventTaxonomy(context *gin.Context, event *models.Event) bool {
+	tags, err := models.NormalizeTags(event.Tags)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return false
+	}
+	event.Tags = tags
+
+	if event.CategoryID == nil {
+		return true
+	}
+
+	_, err = models.GetCategory(*event.CategoryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Unknown category"})
+		return false
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch category"})
+		return false
+	}
+	return true
+}
+
+// Helper function to fill in the coordinates of an event saved without them
+// from its location, when a geocoder is configured
+func geocodeEvent(context *gin.Context, event *models.Event) {
+	if Geocoder == nil || event.Latitude != nil {
+		return
+	}
+
+	point, err := Geocoder.Geocode(context.Request.Context(), event.Location)
+	if errors.Is(err, geo.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		log.Printf("could not geocode %q: %v", event.Location, err)
+		return
+	}
+	event.Latitude = &point.Latitude
+	event.Longitude = &point.Longitude
+}
+
+// GetEvents lists events by start time, narrowed down by ?q=, ?category=,
+// repeated ?tag= (all must match), ?from=, ?to= and ?near= with ?radius=.
 func GetEvents(context *gin.Context) {
-	events, err := models.GetAllEvents()
+	filter, ok := parseEventFilter(context)
+	if !ok {
+		return
+	}
+
+	events, err := models.FindEvents(filter)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve events"})
 		return
@@ -54,15 +226,47 @@ func CreateEvent(context *gin.Context) {
 		return
 	}
 
+	err = event.Validate()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if !checkEventTaxonomy(context, &event) {
+		return
+	}
+
+	geocodeEvent(context, &event)
+
 	userId := context.GetInt64("userId")
 	event.UserID = userId
 
+	if event.OrganizationID != nil {
+		organization, err := models.GetOrganizationByID(*event.OrganizationID)
+		if err != nil || organization.OwnerID != userId {
+			context.JSON(http.StatusUnauthorized, gin.H{"message": "You are not authorized to create events for this organization"})
+			return
+		}
+	}
+
 	err = event.Save()
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create events"})
 		return
 	}
 
+	err = notifications.FeedEventCreated(event)
+	if err != nil {
+		log.Printf("could not add event %d to followers' feeds: %v", event.ID, err)
+	}
+
+	err = models.QueueSavedSearchMatch(event.ID)
+	if err != nil {
+		log.Printf("could not queue saved searches matching event %d: %v", event.ID, err)
+	}
+
+	publishWebhook(event.OrganizationID, webhooks.EventCreated, event)
+
 	context.JSON(http.StatusCreated, gin.H{"message": "Event created successfully", "event": event})
 }
 
@@ -77,6 +281,7 @@ func GetEvent(context *gin.Context) {
 		return
 	}
 
+	recordEventView(context, event)
 	context.JSON(http.StatusOK, event)
 }
 
@@ -103,13 +308,47 @@ func UpdateEvent(context *gin.Context) {
 		return
 	}
 
+	err = updateEvent.Validate()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if !checkEventTaxonomy(context, &updateEvent) {
+		return
+	}
+
+	geocodeEvent(context, &updateEvent)
+
 	updateEvent.ID = eventId
+	updateEvent.UserID = event.UserID
+	updateEvent.OrganizationID = event.OrganizationID
 	err = updateEvent.Update()
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update event"})
 		return
 	}
 
+	if !updateEvent.DateTime.Equal(event.DateTime) {
+		err = updateEvent.RescheduleReminders()
+		if err != nil {
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not reschedule event reminders"})
+			return
+		}
+	}
+
+	err = notifications.EventChanged(notifications.Default, *event, updateEvent)
+	if err != nil {
+		log.Printf("could not notify registrants of event %d: %v", eventId, err)
+	}
+
+	err = notifications.FeedEventChanged(*event, updateEvent)
+	if err != nil {
+		log.Printf("could not add changes of event %d to bookmarkers' feeds: %v", eventId, err)
+	}
+
+	publishWebhook(updateEvent.OrganizationID, webhooks.EventUpdated, updateEvent)
+
 	context.JSON(http.StatusOK, gin.H{"message": "Event updated successfully"})
 }
 
@@ -129,11 +368,45 @@ func DeleteEvent(context *gin.Context) {
 		return
 	}
 
-	err := event.Delete()
+	registrants, err := event.Registrants()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch event registrants"})
+		return
+	}
+
+	bookmarkers, err := event.Bookmarkers()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch event bookmarks"})
+		return
+	}
+
+	err = event.Delete()
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete event"})
 		return
 	}
 
+	image, err := models.GetEventImage(eventId)
+	if err == nil {
+		err = image.Delete()
+		if err != nil {
+			log.Printf("could not delete image of event %d: %v", eventId, err)
+		}
+		deleteImageVariants(context.Request.Context(), image)
+	}
+	deleteEventAttachments(context.Request.Context(), event)
+
+	err = notifications.EventCancelled(notifications.Default, *event, registrants)
+	if err != nil {
+		log.Printf("could not notify registrants of event %d: %v", eventId, err)
+	}
+
+	err = notifications.FeedEventCancelled(*event, bookmarkers)
+	if err != nil {
+		log.Printf("could not add cancellation of event %d to bookmarkers' feeds: %v", eventId, err)
+	}
+
+	publishWebhook(event.OrganizationID, webhooks.EventDeleted, event)
+
 	context.JSON(http.StatusOK, gin.H{"message": "Event deleted successfully"})
 }
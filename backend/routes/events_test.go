@@ -33,13 +33,17 @@ func TestMain(m *testing.M) {
 	if err != nil {
 		panic(err)
 	}
+	// Every pooled connection to :memory: would get its own empty database
+	db.DB.SetMaxOpenConns(1)
 
 	// Create tables
 	createTables := `
 	CREATE TABLE IF NOT EXISTS users (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		email TEXT NOT NULL UNIQUE,
-		password TEXT NOT NULL
+		password TEXT NOT NULL,
+		timezone TEXT NOT NULL DEFAULT 'UTC',
+		is_admin INTEGER NOT NULL DEFAULT 0
 	);
 	CREATE TABLE IF NOT EXISTS events (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -48,15 +52,325 @@ func TestMain(m *testing.M) {
 		location TEXT NOT NULL,
 		dateTime DATETIME NOT NULL,
 		userID INTEGER,
+		organization_id INTEGER,
+		capacity INTEGER NOT NULL DEFAULT 0,
+		end_time DATETIME,
+		guest_limit INTEGER NOT NULL DEFAULT 0,
+		category_id INTEGER,
+		recurrence TEXT,
+		latitude REAL,
+		longitude REAL,
 		FOREIGN KEY (userID) REFERENCES users(id)
 	);
+	CREATE TABLE IF NOT EXISTS categories (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		slug TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		parent_id INTEGER
+	);
+	CREATE TABLE IF NOT EXISTS event_tags (
+		event_id INTEGER NOT NULL,
+		tag TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		PRIMARY KEY (event_id, tag)
+	);
+	CREATE TABLE IF NOT EXISTS feed_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		event_id INTEGER NOT NULL,
+		event_name TEXT NOT NULL,
+		message TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS saved_searches (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		query TEXT NOT NULL,
+		categories TEXT NOT NULL,
+		tags TEXT NOT NULL,
+		frequency TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS saved_search_queue (
+		event_id INTEGER PRIMARY KEY
+	);
 	CREATE TABLE IF NOT EXISTS registrations (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		event_id INTEGER,
 		user_id INTEGER,
+		checked_in_at DATETIME,
+		answers TEXT NOT NULL DEFAULT '{}',
+		status TEXT NOT NULL DEFAULT 'confirmed',
+		guests INTEGER NOT NULL DEFAULT 0,
+		group_id INTEGER,
+		ticket_token TEXT,
+		registered_at DATETIME,
 		FOREIGN KEY (event_id) REFERENCES events(id),
 		FOREIGN KEY (user_id) REFERENCES users(id)
 	);
+	CREATE TABLE IF NOT EXISTS registration_groups (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		leader_id INTEGER NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS registration_forms (
+		event_id INTEGER PRIMARY KEY,
+		fields TEXT NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS reminders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		send_at DATETIME NOT NULL,
+		sent_at DATETIME,
+		channels TEXT NOT NULL DEFAULT '',
+		UNIQUE (event_id, user_id, kind)
+	);
+	CREATE TABLE IF NOT EXISTS notifications (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		title TEXT NOT NULL,
+		body TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		read_at DATETIME
+	);
+	CREATE TABLE IF NOT EXISTS notification_settings (
+		user_id INTEGER PRIMARY KEY,
+		quiet_hours_start TEXT NOT NULL DEFAULT '',
+		quiet_hours_end TEXT NOT NULL DEFAULT '',
+		phone TEXT NOT NULL DEFAULT '',
+		webhook_url TEXT NOT NULL DEFAULT ''
+	);
+	CREATE TABLE IF NOT EXISTS notification_preferences (
+		user_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		channel TEXT NOT NULL,
+		enabled INTEGER NOT NULL,
+		frequency TEXT NOT NULL,
+		PRIMARY KEY (user_id, type, channel)
+	);
+	CREATE TABLE IF NOT EXISTS notification_queue (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		channel TEXT NOT NULL,
+		type TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		body TEXT NOT NULL,
+		attachments TEXT NOT NULL DEFAULT '[]',
+		digest INTEGER NOT NULL,
+		deliver_after DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS organizations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		owner_id INTEGER NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS webhook_endpoints (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		organization_id INTEGER NOT NULL,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		event_types TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		endpoint_id INTEGER NOT NULL,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS webhook_attempts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		delivery_id INTEGER NOT NULL,
+		attempted_at DATETIME NOT NULL,
+		status_code INTEGER NOT NULL,
+		error TEXT NOT NULL,
+		duration_ms INTEGER NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS polls (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		question TEXT NOT NULL,
+		options TEXT NOT NULL,
+		closed INTEGER NOT NULL DEFAULT 0,
+		created_by INTEGER NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS poll_votes (
+		poll_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		option_index INTEGER NOT NULL,
+		PRIMARY KEY (poll_id, user_id)
+	);
+	CREATE TABLE IF NOT EXISTS questions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		text TEXT NOT NULL,
+		answered INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS question_votes (
+		question_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		PRIMARY KEY (question_id, user_id)
+	);
+	CREATE TABLE IF NOT EXISTS comments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		parent_id INTEGER,
+		body TEXT NOT NULL,
+		pinned INTEGER NOT NULL DEFAULT 0,
+		hidden INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		edited_at DATETIME,
+		deleted_at DATETIME
+	);
+	CREATE TABLE IF NOT EXISTS comment_reports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		comment_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		reason TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		resolution TEXT,
+		resolved_by INTEGER,
+		resolved_at DATETIME,
+		UNIQUE(comment_id, user_id)
+	);
+	CREATE TABLE IF NOT EXISTS surveys (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL UNIQUE,
+		title TEXT NOT NULL,
+		questions TEXT NOT NULL,
+		invites_sent_at DATETIME,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS survey_responses (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		survey_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		answers TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		UNIQUE(survey_id, user_id)
+	);
+	CREATE TABLE IF NOT EXISTS transfer_rules (
+		event_id INTEGER PRIMARY KEY,
+		allowed INTEGER NOT NULL,
+		cutoff_hours INTEGER NOT NULL,
+		offer_expiry_hours INTEGER NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS ticket_transfers (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		from_user_id INTEGER NOT NULL,
+		to_user_id INTEGER NOT NULL,
+		status TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL,
+		resolved_at DATETIME
+	);
+	CREATE TABLE IF NOT EXISTS lotteries (
+		event_id INTEGER PRIMARY KEY,
+		opens_at DATETIME NOT NULL,
+		closes_at DATETIME NOT NULL,
+		priority_groups TEXT NOT NULL DEFAULT '[]',
+		seed TEXT,
+		seed_hash TEXT,
+		drawn_at DATETIME
+	);
+	CREATE TABLE IF NOT EXISTS lottery_entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		guests INTEGER NOT NULL DEFAULT 0,
+		answers TEXT NOT NULL DEFAULT '{}',
+		created_at DATETIME NOT NULL,
+		priority INTEGER,
+		rank INTEGER,
+		outcome TEXT NOT NULL DEFAULT 'pending',
+		UNIQUE(event_id, user_id)
+	);
+	CREATE TABLE IF NOT EXISTS certificate_templates (
+		event_id INTEGER PRIMARY KEY,
+		title TEXT NOT NULL,
+		body TEXT NOT NULL,
+		signatures TEXT NOT NULL DEFAULT '[]',
+		logo BLOB
+	);
+	CREATE TABLE IF NOT EXISTS certificates (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		code TEXT NOT NULL UNIQUE,
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		recipient_name TEXT NOT NULL,
+		issued_at DATETIME NOT NULL,
+		UNIQUE(event_id, user_id)
+	);
+	CREATE TABLE IF NOT EXISTS event_images (
+		event_id INTEGER PRIMARY KEY,
+		storage_key TEXT NOT NULL,
+		width INTEGER NOT NULL,
+		height INTEGER NOT NULL,
+		uploaded_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS attachments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		storage_key TEXT NOT NULL,
+		visibility TEXT NOT NULL,
+		uploaded_by INTEGER NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS follows (
+		user_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		target_id INTEGER NOT NULL,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (user_id, kind, target_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS recommendations (
+		user_id INTEGER NOT NULL,
+		event_id INTEGER NOT NULL,
+		rank INTEGER NOT NULL,
+		score REAL NOT NULL,
+		reasons TEXT NOT NULL,
+		computed_at DATETIME NOT NULL,
+		PRIMARY KEY (user_id, event_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS bookmarks (
+		user_id INTEGER NOT NULL,
+		event_id INTEGER NOT NULL,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (user_id, event_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS event_views (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		viewer TEXT NOT NULL,
+		source TEXT NOT NULL DEFAULT '',
+		viewed_at DATETIME NOT NULL
+	);
 	`
 	_, err = db.DB.Exec(createTables)
 	if err != nil {
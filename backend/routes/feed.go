@@ -0,0 +1,64 @@
+package routes
+
+import (
+	"encoding/base64"
+	"event-planner/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Helper function to encode the position after a feed item as an opaque cursor
+func encodeFeedCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+// Helper function to read the optional ?cursor= of the feed, 0 meaning the start
+func parseFeedCursor(context *gin.Context) (int64, bool) {
+	cursor := context.Query("cursor")
+	if cursor == "" {
+		return 0, true
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	var id int64
+	if err == nil {
+		id, err = strconv.ParseInt(string(decoded), 10, 64)
+	}
+	if err != nil || id < 1 {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid cursor"})
+		return 0, false
+	}
+	return id, true
+}
+
+// getMyFeed lists new events from followed organizations and organizers,
+// changes to bookmarked events and reminders, newest first. NextCursor is
+// empty on the last page.
+func getMyFeed(context *gin.Context) {
+	before, ok := parseFeedCursor(context)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(context.DefaultQuery("limit", strconv.Itoa(defaultPageSize)))
+	if err != nil || limit < 1 || limit > maxPageSize {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Limit must be between 1 and " + strconv.Itoa(maxPageSize)})
+		return
+	}
+
+	// Fetch one extra item to know whether there is another page
+	items, err := models.GetFeed(context.GetInt64("userId"), before, limit+1)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch feed"})
+		return
+	}
+
+	nextCursor := ""
+	if len(items) > limit {
+		items = items[:limit]
+		nextCursor = encodeFeedCursor(items[limit-1].ID)
+	}
+	context.JSON(http.StatusOK, gin.H{"items": items, "nextCursor": nextCursor})
+}
@@ -0,0 +1,148 @@
+package routes
+
+import (
+	"encoding/json"
+	"event-planner/models"
+	"event-planner/notifications"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type feedResponse struct {
+	Items      []models.FeedItem
+	NextCursor string
+}
+
+func setupFeedRouter() *gin.Engine {
+	router := setupTransferRouter()
+	router.POST("/events", CreateEvent)
+	router.PUT("/events/:id", UpdateEvent)
+	router.DELETE("/events/:id", DeleteEvent)
+	router.POST("/events/:id/bookmark", bookmarkEvent)
+	router.POST("/organizers/:id/follow", followOrganizer)
+	router.DELETE("/organizers/:id/follow", unfollowOrganizer)
+	router.GET("/me/follows", getMyFollows)
+	router.GET("/me/feed", getMyFeed)
+	return router
+}
+
+func getFeed(t *testing.T, router *gin.Engine, userId int64, query string) feedResponse {
+	w := sendAs(router, userId, "GET", "/me/feed"+query, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to fetch feed: %s", w.Body.String())
+	}
+	var feed feedResponse
+	json.Unmarshal(w.Body.Bytes(), &feed)
+	return feed
+}
+
+func TestFeed_FollowsBookmarksAndReminders(t *testing.T) {
+	router := setupFeedRouter()
+	organizerId := createTestUser(t, "feed-organizer@example.com")
+	studentId := createTestUser(t, "feed-student@example.com")
+	followPath := "/organizers/" + strconv.FormatInt(organizerId, 10) + "/follow"
+
+	assert.Equal(t, http.StatusNotFound, sendAs(router, studentId, "POST", "/organizers/99999/follow", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, organizerId, "POST", followPath, nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, studentId, "POST", followPath, nil).Code)
+
+	var follows []models.Follow
+	json.Unmarshal(sendAs(router, studentId, "GET", "/me/follows", nil).Body.Bytes(), &follows)
+	if assert.Len(t, follows, 1) {
+		assert.Equal(t, models.FollowOrganizer, follows[0].Kind)
+		assert.Equal(t, "feed-organizer@example.com", follows[0].Name)
+	}
+
+	start := time.Now().Add(72 * time.Hour).Truncate(time.Second)
+	w := sendAs(router, organizerId, "POST", "/events", gin.H{
+		"Name":        "Poetry Slam",
+		"Description": "Test Description",
+		"Location":    "Hall A",
+		"DateTime":    start,
+	})
+	if !assert.Equal(t, http.StatusCreated, w.Code) {
+		return
+	}
+	var created struct{ Event models.Event }
+	json.Unmarshal(w.Body.Bytes(), &created)
+	eventPath := "/events/" + strconv.FormatInt(created.Event.ID, 10)
+
+	feed := getFeed(t, router, studentId, "")
+	if assert.Len(t, feed.Items, 1) {
+		assert.Equal(t, models.FeedNewEvent, feed.Items[0].Kind)
+		assert.Equal(t, created.Event.ID, feed.Items[0].EventID)
+	}
+	assert.Empty(t, getFeed(t, router, organizerId, "").Items)
+
+	// Changes reach bookmarkers only when something meaningful changed
+	assert.Equal(t, http.StatusOK, sendAs(router, studentId, "POST", eventPath+"/bookmark", nil).Code)
+	update := gin.H{"Name": "Poetry Slam", "Description": "New description", "Location": "Hall A", "DateTime": start}
+	assert.Equal(t, http.StatusOK, sendAs(router, organizerId, "PUT", eventPath, update).Code)
+	assert.Len(t, getFeed(t, router, studentId, "").Items, 1)
+
+	update["Location"] = "Hall B"
+	assert.Equal(t, http.StatusOK, sendAs(router, organizerId, "PUT", eventPath, update).Code)
+	feed = getFeed(t, router, studentId, "")
+	if assert.Len(t, feed.Items, 2) {
+		assert.Equal(t, models.FeedEventChanged, feed.Items[0].Kind)
+		assert.Contains(t, feed.Items[0].Message, "Hall A → Hall B")
+	}
+
+	// One reminder per event however often the job runs
+	assert.NoError(t, notifications.AddFeedReminders(start.Add(-25*time.Hour)))
+	assert.Len(t, getFeed(t, router, studentId, "").Items, 2)
+	assert.NoError(t, notifications.AddFeedReminders(start.Add(-23*time.Hour)))
+	assert.NoError(t, notifications.AddFeedReminders(start.Add(-2*time.Hour)))
+	feed = getFeed(t, router, studentId, "")
+	if assert.Len(t, feed.Items, 3) {
+		assert.Equal(t, models.FeedReminder, feed.Items[0].Kind)
+	}
+
+	assert.Equal(t, http.StatusOK, sendAs(router, organizerId, "DELETE", eventPath, nil).Code)
+	feed = getFeed(t, router, studentId, "")
+	if assert.Len(t, feed.Items, 4) {
+		assert.Equal(t, models.FeedEventCancelled, feed.Items[0].Kind)
+		assert.Equal(t, "Poetry Slam", feed.Items[0].EventName)
+	}
+
+	assert.Equal(t, http.StatusOK, sendAs(router, studentId, "DELETE", followPath, nil).Code)
+	json.Unmarshal(sendAs(router, studentId, "GET", "/me/follows", nil).Body.Bytes(), &follows)
+	assert.Empty(t, follows)
+}
+
+func TestFeed_CursorPagination(t *testing.T) {
+	router := setupFeedRouter()
+	userId := createTestUser(t, "feed-pages@example.com")
+
+	for i := 1; i <= 5; i++ {
+		err := models.AddFeedItem([]int64{userId}, models.FeedItem{
+			Kind:      models.FeedNewEvent,
+			EventID:   int64(i),
+			EventName: "Event " + strconv.Itoa(i),
+			Message:   "New event",
+		})
+		assert.NoError(t, err)
+	}
+
+	seen := []int64{}
+	query := "?limit=2"
+	for pages := 0; pages < 5; pages++ {
+		feed := getFeed(t, router, userId, query)
+		for _, item := range feed.Items {
+			seen = append(seen, item.EventID)
+		}
+		if feed.NextCursor == "" {
+			break
+		}
+		query = "?limit=2&cursor=" + feed.NextCursor
+	}
+	assert.Equal(t, []int64{5, 4, 3, 2, 1}, seen)
+
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, userId, "GET", "/me/feed?cursor=not-a-cursor", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, userId, "GET", "/me/feed?limit=0", nil).Code)
+}
@@ -0,0 +1,103 @@
+package routes
+
+import (
+	"event-planner/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func followOrganization(context *gin.Context) {
+	organizationId, ok := parseOrganizationID(context)
+	if !ok {
+		return
+	}
+
+	_, err := models.GetOrganizationByID(organizationId)
+	if err != nil {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Organization not found"})
+		return
+	}
+
+	err = models.AddFollow(context.GetInt64("userId"), models.FollowOrganization, organizationId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not follow organization"})
+		return
+	}
+	context.JSON(http.StatusOK, gin.H{"message": "Following organization"})
+}
+
+func unfollowOrganization(context *gin.Context) {
+	organizationId, ok := parseOrganizationID(context)
+	if !ok {
+		return
+	}
+
+	err := models.RemoveFollow(context.GetInt64("userId"), models.FollowOrganization, organizationId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not unfollow organization"})
+		return
+	}
+	context.JSON(http.StatusOK, gin.H{"message": "Unfollowed organization"})
+}
+
+func getMyFollows(context *gin.Context) {
+	follows, err := models.GetFollows(context.GetInt64("userId"))
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch follows"})
+		return
+	}
+	context.JSON(http.StatusOK, follows)
+}
+
+// Helper function to parse the organizer ID from the URL and check they exist
+func getOrganizerID(context *gin.Context) (int64, bool) {
+	organizerId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse organizer id"})
+		return 0, false
+	}
+
+	_, err = models.GetUserByID(organizerId)
+	if err != nil {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Organizer not found"})
+		return 0, false
+	}
+	return organizerId, true
+}
+
+func followOrganizer(context *gin.Context) {
+	organizerId, ok := getOrganizerID(context)
+	if !ok {
+		return
+	}
+
+	userId := context.GetInt64("userId")
+	if organizerId == userId {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "You cannot follow yourself"})
+		return
+	}
+
+	err := models.AddFollow(userId, models.FollowOrganizer, organizerId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not follow organizer"})
+		return
+	}
+	context.JSON(http.StatusOK, gin.H{"message": "Following organizer"})
+}
+
+func unfollowOrganizer(context *gin.Context) {
+	organizerId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse organizer id"})
+		return
+	}
+
+	err = models.RemoveFollow(context.GetInt64("userId"), models.FollowOrganizer, organizerId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not unfollow organizer"})
+		return
+	}
+	context.JSON(http.StatusOK, gin.H{"message": "Unfollowed organizer"})
+}
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"event-planner/models"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sendAs sends body as JSON, or no body when it is nil. userId goes in the
+// X-User header read by setupTransferRouter; routers set up for a fixed user
+// ignore it.
+func sendAs(router *gin.Engine, userId int64, method, path string, body any) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if body != nil {
+		jsonValue, _ := json.Marshal(body)
+		req = httptest.NewRequest(method, path, bytes.NewReader(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-User", strconv.FormatInt(userId, 10))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// createTestEvent saves an event with a single seat, so tests can fill it.
+func createTestEvent(t *testing.T, organizerId int64, start time.Time) models.Event {
+	event := models.Event{
+		Name:        "Test Event",
+		Description: "Test Description",
+		Location:    "Test Location",
+		DateTime:    start,
+		UserID:      organizerId,
+		Capacity:    1,
+	}
+	err := event.Save()
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+	return event
+}
@@ -0,0 +1,252 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"event-planner/images"
+	"event-planner/models"
+	"event-planner/storage"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImageUpload is the largest image file accepted, matching what the
+// frontend allows.
+const maxImageUpload = 5 << 20
+
+// Storage holds uploaded files. main replaces it with the configured backend.
+var Storage storage.Storage = storage.Local{Dir: "uploads", BaseURL: "http://localhost:8080/uploads"}
+
+type eventImageResponse struct {
+	Thumbnail  string
+	Card       string
+	Full       string
+	Width      int
+	Height     int
+	UploadedAt time.Time
+}
+
+// Helper function to describe an event image with the URLs of its variants
+func newEventImageResponse(image *models.EventImage) eventImageResponse {
+	return eventImageResponse{
+		Thumbnail:  Storage.URL(image.VariantKey(images.Thumbnail.Name)),
+		Card:       Storage.URL(image.VariantKey(images.Card.Name)),
+		Full:       Storage.URL(image.VariantKey(images.Full.Name)),
+		Width:      image.Width,
+		Height:     image.Height,
+		UploadedAt: image.UploadedAt,
+	}
+}
+
+// Helper function to remove every stored variant of an image
+func deleteImageVariants(ctx context.Context, image *models.EventImage) {
+	for _, variant := range images.Variants {
+		err := Storage.Delete(ctx, image.VariantKey(variant.Name))
+		if err != nil {
+			log.Printf("could not delete %s: %v", image.VariantKey(variant.Name), err)
+		}
+	}
+}
+
+// Helper function to read the uploaded image, replying if it is unusable
+func readImageUpload(context *gin.Context) ([]byte, bool) {
+	// Leave room for the rest of the multipart body
+	context.Request.Body = http.MaxBytesReader(context.Writer, context.Request.Body, maxImageUpload+1<<20)
+
+	file, header, err := context.Request.FormFile("image")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		context.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "Image must be at most 5MB"})
+		return nil, false
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Please upload an image file in the image field"})
+		return nil, false
+	}
+	defer file.Close()
+
+	if header.Size > maxImageUpload {
+		context.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "Image must be at most 5MB"})
+		return nil, false
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImageUpload+1))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not read image"})
+		return nil, false
+	}
+	if len(data) > maxImageUpload {
+		context.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "Image must be at most 5MB"})
+		return nil, false
+	}
+
+	// Trust the bytes, not the client's Content-Type
+	_, err = images.Sniff(data)
+	if err != nil {
+		context.JSON(http.StatusUnsupportedMediaType, gin.H{"message": err.Error()})
+		return nil, false
+	}
+	return data, true
+}
+
+// uploadEventImage stores an uploaded picture for the event in each of the
+// image variants, replacing the previous one.
+func uploadEventImage(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventAuthorization(context, event, context.GetInt64("userId"), "update") {
+		return
+	}
+
+	data, ok := readImageUpload(context)
+	if !ok {
+		return
+	}
+
+	img, err := images.Decode(data)
+	if errors.Is(err, images.ErrTooLarge) {
+		context.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusUnsupportedMediaType, gin.H{"message": err.Error()})
+		return
+	}
+
+	bounds := img.Bounds()
+	image, err := models.NewEventImage(eventId, bounds.Dx(), bounds.Dy())
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not store image"})
+		return
+	}
+
+	ctx := context.Request.Context()
+	for _, variant := range images.Variants {
+		var buf bytes.Buffer
+		err = images.EncodeJPEG(&buf, images.Resize(img, variant))
+		if err == nil {
+			err = Storage.Put(ctx, image.VariantKey(variant.Name), &buf, int64(buf.Len()), "image/jpeg")
+		}
+		if err != nil {
+			log.Printf("could not store %s image for event %d: %v", variant.Name, eventId, err)
+			deleteImageVariants(ctx, image)
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not store image"})
+			return
+		}
+	}
+
+	previous, err := models.GetEventImage(eventId)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("could not fetch previous image for event %d: %v", eventId, err)
+	}
+
+	err = image.Save()
+	if err != nil {
+		deleteImageVariants(ctx, image)
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not save image"})
+		return
+	}
+
+	if previous != nil {
+		deleteImageVariants(ctx, previous)
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Image uploaded", "image": newEventImageResponse(image)})
+}
+
+func getEventImage(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	image, err := models.GetEventImage(eventId)
+	if err != nil {
+		context.JSON(http.StatusNotFound, gin.H{"message": "This event has no image"})
+		return
+	}
+	context.JSON(http.StatusOK, newEventImageResponse(image))
+}
+
+func deleteEventImage(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventAuthorization(context, event, context.GetInt64("userId"), "update") {
+		return
+	}
+
+	image, err := models.GetEventImage(eventId)
+	if err != nil {
+		context.JSON(http.StatusNotFound, gin.H{"message": "This event has no image"})
+		return
+	}
+
+	err = image.Delete()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete image"})
+		return
+	}
+
+	deleteImageVariants(context.Request.Context(), image)
+	context.JSON(http.StatusOK, gin.H{"message": "Image deleted"})
+}
+
+// serveUpload streams a stored file. Keys change with every upload, so
+// clients may cache them for good.
+func serveUpload(context *gin.Context) {
+	// Cleaned first, so "images/../attachments/..." cannot get past the checks
+	key := path.Clean(strings.TrimPrefix(context.Param("key"), "/"))
+	if key == "." || strings.HasPrefix(key, "..") || strings.HasPrefix(key, "/") {
+		context.JSON(http.StatusNotFound, gin.H{"message": "File not found"})
+		return
+	}
+
+	if strings.HasPrefix(key, "attachments/") {
+		// Attachments are only served through signed links
+		context.JSON(http.StatusNotFound, gin.H{"message": "File not found"})
+		return
+	}
+
+	file, err := Storage.Open(context.Request.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "File not found"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not read file"})
+		return
+	}
+	defer file.Close()
+
+	contentType := "application/octet-stream"
+	if strings.HasSuffix(key, ".jpg") {
+		contentType = "image/jpeg"
+	}
+	context.Header("Cache-Control", "public, max-age=31536000, immutable")
+	context.Header("X-Content-Type-Options", "nosniff")
+	context.DataFromReader(http.StatusOK, -1, contentType, file, nil)
+}
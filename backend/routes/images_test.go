@@ -0,0 +1,146 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"event-planner/storage"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupImageRouter(t *testing.T) *gin.Engine {
+	previous := Storage
+	Storage = storage.Local{Dir: t.TempDir(), BaseURL: "http://localhost:8080/uploads"}
+	t.Cleanup(func() { Storage = previous })
+
+	router := setupTransferRouter()
+	router.PUT("/events/:id/image", uploadEventImage)
+	router.GET("/events/:id/image", getEventImage)
+	router.DELETE("/events/:id/image", deleteEventImage)
+	router.GET("/uploads/*key", serveUpload)
+	return router
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	picture := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := range width {
+		picture.Set(x, height/2, color.RGBA{B: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	err := png.Encode(&buf, picture)
+	if err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func uploadFile(t *testing.T, router *gin.Engine, userId int64, method, path, field, filename string, data []byte, values map[string]string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range values {
+		form.WriteField(name, value)
+	}
+	part, err := form.CreateFormFile(field, filename)
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(data)
+	form.Close()
+
+	req, _ := http.NewRequest(method, path, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("X-User", strconv.FormatInt(userId, 10))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func uploadImage(t *testing.T, router *gin.Engine, userId int64, path string, data []byte) *httptest.ResponseRecorder {
+	return uploadFile(t, router, userId, "PUT", path, "image", "poster.png", data, nil)
+}
+
+func TestEventImage_UploadServeAndDelete(t *testing.T) {
+	organizerId := createTestUser(t, "image-organizer@example.com")
+	otherId := createTestUser(t, "image-other@example.com")
+	event := createTestEvent(t, organizerId, time.Now().Add(24*time.Hour))
+	path := "/events/" + strconv.FormatInt(event.ID, 10) + "/image"
+	router := setupImageRouter(t)
+
+	assert.Equal(t, http.StatusNotFound, sendAs(router, otherId, "GET", path, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, uploadImage(t, router, otherId, path, testPNG(t, 800, 600)).Code)
+
+	w := uploadImage(t, router, organizerId, path, testPNG(t, 800, 600))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct{ Image eventImageResponse }
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 800, response.Image.Width)
+	assert.Equal(t, 600, response.Image.Height)
+
+	sizes := map[string][2]int{
+		response.Image.Thumbnail: {200, 200},
+		response.Image.Card:      {640, 360},
+		response.Image.Full:      {800, 600}, // never scaled up
+	}
+	for url, size := range sizes {
+		if !assert.True(t, strings.HasPrefix(url, "http://localhost:8080/uploads/")) {
+			continue
+		}
+		w = sendAs(router, otherId, "GET", strings.TrimPrefix(url, "http://localhost:8080"), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/jpeg", w.Header().Get("Content-Type"))
+		config, format, err := image.DecodeConfig(w.Body)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "jpeg", format)
+			assert.Equal(t, size, [2]int{config.Width, config.Height})
+		}
+	}
+
+	// Replacing the image removes the old files
+	w = uploadImage(t, router, organizerId, path, testPNG(t, 300, 300))
+	assert.Equal(t, http.StatusOK, w.Code)
+	oldThumbnail := strings.TrimPrefix(response.Image.Thumbnail, "http://localhost:8080")
+	assert.Equal(t, http.StatusNotFound, sendAs(router, otherId, "GET", oldThumbnail, nil).Code)
+
+	w = sendAs(router, otherId, "GET", path, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &response.Image)
+	assert.Equal(t, 300, response.Image.Width)
+
+	assert.Equal(t, http.StatusUnauthorized, sendAs(router, otherId, "DELETE", path, nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, organizerId, "DELETE", path, nil).Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(router, otherId, "GET", path, nil).Code)
+	thumbnail := strings.TrimPrefix(response.Image.Thumbnail, "http://localhost:8080")
+	assert.Equal(t, http.StatusNotFound, sendAs(router, otherId, "GET", thumbnail, nil).Code)
+}
+
+func TestEventImage_RejectsBadUploads(t *testing.T) {
+	organizerId := createTestUser(t, "image-rejects@example.com")
+	event := createTestEvent(t, organizerId, time.Now().Add(24*time.Hour))
+	path := "/events/" + strconv.FormatInt(event.ID, 10) + "/image"
+	router := setupImageRouter(t)
+
+	// The bytes decide the type, not the file name
+	w := uploadImage(t, router, organizerId, path, []byte("%PDF-1.4 not a picture"))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	w = uploadImage(t, router, organizerId, path, append(testPNG(t, 10, 10), make([]byte, maxImageUpload)...))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// A valid header with a corrupt body cannot be decoded
+	w = uploadImage(t, router, organizerId, path, testPNG(t, 10, 10)[:40])
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	assert.Equal(t, http.StatusNotFound, sendAs(router, organizerId, "GET", "/uploads/../../etc/passwd", nil).Code)
+}
@@ -0,0 +1,90 @@
+package routes
+
+import (
+	"event-planner/live"
+	"event-planner/utils"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// AllowedOrigins are the browser origins allowed to call the API, shared by
+// the CORS middleware and the WebSocket origin check.
+var AllowedOrigins = []string{"http://localhost:5173", "http://localhost:3000"}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     checkOrigin,
+}
+
+// checkOrigin accepts non-browser clients, same-host pages and the
+// configured frontend origins.
+func checkOrigin(request *http.Request) bool {
+	origin := request.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return parsed.Host == request.Host || slices.Contains(AllowedOrigins, origin)
+}
+
+// liveEvent upgrades to the event's live polls and Q&A channel. Browsers
+// cannot set headers on a WebSocket handshake, so the JWT may also be passed
+// as the token query parameter.
+func liveEvent(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	token := context.GetHeader("Authorization")
+	if token == "" {
+		token = context.Query("token")
+	}
+
+	userId, err := utils.VerifyToken(token)
+	if err != nil {
+		context.JSON(http.StatusUnauthorized, gin.H{"message": "Invalid/No authorization token"})
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	canModerate, err := isEventOrganizer(event, userId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not check event organizer"})
+		return
+	}
+
+	if !canModerate {
+		registered, err := event.IsRegistered(userId)
+		if err != nil {
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not check registration"})
+			return
+		}
+
+		if !registered {
+			context.JSON(http.StatusUnauthorized, gin.H{"message": "Only registered attendees can join the live session"})
+			return
+		}
+	}
+
+	conn, err := upgrader.Upgrade(context.Writer, context.Request, nil)
+	if err != nil {
+		// The upgrader has already written an error response
+		return
+	}
+
+	live.Serve(&live.Session{Conn: conn, EventID: eventId, UserID: userId, CanModerate: canModerate})
+}
@@ -0,0 +1,133 @@
+package routes
+
+import (
+	"event-planner/live"
+	"event-planner/models"
+	"event-planner/utils"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupLiveServer() *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/events/:id/live", liveEvent)
+	return httptest.NewServer(router)
+}
+
+func dialLive(t *testing.T, server *httptest.Server, eventId, userId int64) *websocket.Conn {
+	token, err := utils.GenerateToken(userId, "live@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/events/" + strconv.FormatInt(eventId, 10) + "/live?token=" + token
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	return conn
+}
+
+// readResponse returns the next message of the given type, skipping others.
+func readResponse(t *testing.T, conn *websocket.Conn, responseType string) live.Response {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var response live.Response
+		err := conn.ReadJSON(&response)
+		if err != nil {
+			t.Fatalf("Failed waiting for %q: %v", responseType, err)
+		}
+		if response.Type == responseType {
+			return response
+		}
+	}
+}
+
+func TestLiveEvent_RequiresRegistration(t *testing.T) {
+	organizerId := createTestUser(t, "live-organizer-1@example.com")
+	outsiderId := createTestUser(t, "live-outsider@example.com")
+	event := createTestEvent(t, organizerId, time.Now().Add(time.Hour))
+
+	server := setupLiveServer()
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/events/" + strconv.FormatInt(event.ID, 10) + "/live"
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	token, _ := utils.GenerateToken(outsiderId, "live-outsider@example.com")
+	_, resp, err = websocket.DefaultDialer.Dial(url+"?token="+token, nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestLiveEvent_PollsAndQuestions(t *testing.T) {
+	organizerId := createTestUser(t, "live-organizer-2@example.com")
+	attendeeId := createTestUser(t, "live-attendee@example.com")
+	event := createTestEvent(t, organizerId, time.Now().Add(time.Hour))
+	err := event.Register(attendeeId, nil)
+	if err != nil {
+		t.Fatalf("Failed to register attendee: %v", err)
+	}
+
+	server := setupLiveServer()
+	defer server.Close()
+
+	organizer := dialLive(t, server, event.ID, organizerId)
+	defer organizer.Close()
+	readResponse(t, organizer, live.State)
+
+	attendee := dialLive(t, server, event.ID, attendeeId)
+	defer attendee.Close()
+	readResponse(t, attendee, live.State)
+
+	// Attendees cannot create polls
+	attendee.WriteJSON(live.Request{Type: live.PollCreate, Question: "Tabs or spaces?", Options: []string{"Tabs", "Spaces"}})
+	assert.Equal(t, "only organizers can create polls", readResponse(t, attendee, live.Error).Message)
+
+	organizer.WriteJSON(live.Request{Type: live.PollCreate, Question: "Tabs or spaces?", Options: []string{"Tabs", "Spaces"}})
+	poll := readResponse(t, attendee, live.PollUpdated).Poll
+	readResponse(t, organizer, live.PollUpdated)
+	assert.Equal(t, []string{"Tabs", "Spaces"}, poll.Options)
+
+	attendee.WriteJSON(live.Request{Type: live.PollVote, PollID: poll.ID, Option: 1})
+	updated := readResponse(t, organizer, live.PollUpdated).Poll
+	readResponse(t, attendee, live.PollUpdated)
+	assert.Equal(t, []int{0, 1}, updated.Tallies)
+
+	attendee.WriteJSON(live.Request{Type: live.PollVote, PollID: poll.ID, Option: 0})
+	assert.Equal(t, models.ErrAlreadyVoted.Error(), readResponse(t, attendee, live.Error).Message)
+
+	attendee.WriteJSON(live.Request{Type: live.QuestionAsk, Text: "Will the slides be shared?"})
+	question := readResponse(t, organizer, live.QuestionUpdated).Question
+	readResponse(t, attendee, live.QuestionUpdated)
+
+	organizer.WriteJSON(live.Request{Type: live.QuestionUpvote, QuestionID: question.ID})
+	assert.Equal(t, 1, readResponse(t, attendee, live.QuestionUpdated).Question.Upvotes)
+	readResponse(t, organizer, live.QuestionUpdated)
+
+	organizer.WriteJSON(live.Request{Type: live.QuestionAnswer, QuestionID: question.ID})
+	assert.True(t, readResponse(t, attendee, live.QuestionUpdated).Question.Answered)
+
+	// A reconnecting client gets the persisted results
+	attendee.Close()
+	attendee = dialLive(t, server, event.ID, attendeeId)
+	state := readResponse(t, attendee, live.State)
+	if assert.Len(t, state.Polls, 1) {
+		assert.Equal(t, []int{0, 1}, state.Polls[0].Tallies)
+	}
+	if assert.Len(t, state.Questions, 1) {
+		assert.True(t, state.Questions[0].Answered)
+		assert.Equal(t, 1, state.Questions[0].Upvotes)
+	}
+}
@@ -0,0 +1,243 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"event-planner/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type lotteryEntryRequest struct {
+	Answers models.RegistrationAnswers
+	Guests  int
+}
+
+// Helper function to load the lottery for an event
+func getEventLottery(context *gin.Context, eventId int64) (*models.Lottery, bool) {
+	lottery, err := models.GetLottery(eventId)
+	if errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "This event has no lottery"})
+		return nil, false
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch lottery"})
+		return nil, false
+	}
+	return lottery, true
+}
+
+// Helper function to turn away direct registrations while a lottery is pending
+func checkNoPendingLottery(context *gin.Context, eventId int64) bool {
+	lottery, err := models.GetLottery(eventId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch lottery"})
+		return false
+	}
+
+	if lottery.DrawnAt == nil {
+		context.JSON(http.StatusConflict, gin.H{"message": "Seats for this event are allocated by lottery"})
+		return false
+	}
+	return true
+}
+
+// Helper function to reply to lottery errors, reporting whether there was one
+func handleLotteryError(context *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, models.ErrLotteryClosed), errors.Is(err, models.ErrLotteryDrawn):
+		context.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+	case errors.Is(err, models.ErrAlreadyEntered):
+		context.JSON(http.StatusConflict, gin.H{"message": "You have already entered this lottery"})
+	case errors.Is(err, models.ErrNoLotteryEntry):
+		context.JSON(http.StatusNotFound, gin.H{"message": "You have no pending lottery entry for this event"})
+	default:
+		return handleRegistrationError(context, err)
+	}
+	return true
+}
+
+// getLottery returns the lottery settings. Priority group members are only
+// shown to organizers, and the seed only once it has been drawn.
+func getLottery(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	lottery, ok := getEventLottery(context, eventId)
+	if !ok {
+		return
+	}
+
+	for i := range lottery.PriorityGroups {
+		lottery.PriorityGroups[i].Emails = nil
+	}
+	if lottery.DrawnAt == nil {
+		lottery.Seed = ""
+	}
+	context.JSON(http.StatusOK, lottery)
+}
+
+func saveLottery(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventOrganizer(context, event, context.GetInt64("userId"), "manage the lottery") {
+		return
+	}
+
+	var lottery models.Lottery
+	err := context.ShouldBindJSON(&lottery)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	err = lottery.Validate(*event)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	lottery.EventID = eventId
+	lottery.DrawnAt = nil
+	err = lottery.Save()
+	if handleLotteryError(context, err) {
+		return
+	}
+
+	// Organizers only see the commitment, like everyone else
+	lottery.Seed = ""
+	context.JSON(http.StatusOK, gin.H{"message": "Lottery saved", "lottery": lottery})
+}
+
+func deleteLottery(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventOrganizer(context, event, context.GetInt64("userId"), "manage the lottery") {
+		return
+	}
+
+	lottery, ok := getEventLottery(context, eventId)
+	if !ok {
+		return
+	}
+
+	err := lottery.Delete()
+	if handleLotteryError(context, err) {
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Lottery removed"})
+}
+
+func enterLottery(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	var request lotteryEntryRequest
+	if context.Request.ContentLength != 0 {
+		err := context.ShouldBindJSON(&request)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+			return
+		}
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	lottery, ok := getEventLottery(context, eventId)
+	if !ok {
+		return
+	}
+
+	entry, err := lottery.Enter(*event, context.GetInt64("userId"), request.Guests, request.Answers, time.Now())
+	if handleLotteryError(context, err) {
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Entered the lottery", "entry": entry})
+}
+
+func getMyLotteryEntry(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	entry, err := models.GetLotteryEntry(eventId, context.GetInt64("userId"))
+	if err != nil {
+		context.JSON(http.StatusNotFound, gin.H{"message": "You have not entered this lottery"})
+		return
+	}
+	context.JSON(http.StatusOK, entry)
+}
+
+func withdrawLotteryEntry(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	err := models.WithdrawLotteryEntry(eventId, context.GetInt64("userId"))
+	if handleLotteryError(context, err) {
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Lottery entry withdrawn"})
+}
+
+// getLotteryResults publishes the seed and the draw order so anyone can check
+// the allocation.
+func getLotteryResults(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	lottery, ok := getEventLottery(context, eventId)
+	if !ok {
+		return
+	}
+
+	if lottery.DrawnAt == nil {
+		context.JSON(http.StatusNotFound, gin.H{"message": "The lottery has not been drawn yet"})
+		return
+	}
+
+	results, err := lottery.Results()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch lottery results"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"seed": lottery.Seed, "seedHash": lottery.SeedHash, "drawnAt": lottery.DrawnAt, "results": results})
+}
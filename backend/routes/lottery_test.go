@@ -0,0 +1,108 @@
+package routes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"event-planner/db"
+	"event-planner/models"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupLotteryRouter() *gin.Engine {
+	router := setupTransferRouter()
+	router.GET("/events/:id/lottery", getLottery)
+	router.PUT("/events/:id/lottery", saveLottery)
+	router.DELETE("/events/:id/lottery", deleteLottery)
+	router.POST("/events/:id/lottery/entry", enterLottery)
+	router.GET("/events/:id/lottery/entry", getMyLotteryEntry)
+	router.DELETE("/events/:id/lottery/entry", withdrawLotteryEntry)
+	router.GET("/events/:id/lottery/results", getLotteryResults)
+	return router
+}
+
+func TestLottery_EntriesAndResults(t *testing.T) {
+	organizerId := createTestUser(t, "lottery-organizer@example.com")
+	firstId := createTestUser(t, "lottery-first@example.com")
+	secondId := createTestUser(t, "lottery-second@example.com")
+	lateId := createTestUser(t, "lottery-late@example.com")
+	event := createTestEvent(t, organizerId, time.Now().Add(72*time.Hour))
+	event.Capacity = 3
+	if err := event.Update(); err != nil {
+		t.Fatalf("Failed to update test event: %v", err)
+	}
+	basePath := "/events/" + strconv.FormatInt(event.ID, 10)
+	router := setupLotteryRouter()
+
+	lottery := gin.H{
+		"OpensAt":        time.Now().Add(-time.Hour),
+		"ClosesAt":       time.Now().Add(time.Hour),
+		"PriorityGroups": []gin.H{{"Name": "Majors", "Emails": []string{"lottery-second@example.com"}}},
+	}
+	assert.Equal(t, http.StatusUnauthorized, sendAs(router, firstId, "PUT", basePath+"/lottery", lottery).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, organizerId, "PUT", basePath+"/lottery", lottery).Code)
+
+	// Priority group members stay private, and only the seed's hash is published
+	var published models.Lottery
+	w := sendAs(router, firstId, "GET", basePath+"/lottery", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "lottery-second@example.com")
+	json.Unmarshal(w.Body.Bytes(), &published)
+	assert.Empty(t, published.Seed)
+	assert.Len(t, published.SeedHash, 64)
+
+	assert.Equal(t, http.StatusConflict, sendAs(router, firstId, "POST", basePath+"/register", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, firstId, "POST", basePath+"/lottery/entry", gin.H{"Guests": 1}).Code)
+	assert.Equal(t, http.StatusCreated, sendAs(router, firstId, "POST", basePath+"/lottery/entry", nil).Code)
+	assert.Equal(t, http.StatusConflict, sendAs(router, firstId, "POST", basePath+"/lottery/entry", nil).Code)
+	assert.Equal(t, http.StatusCreated, sendAs(router, secondId, "POST", basePath+"/lottery/entry", nil).Code)
+	assert.Equal(t, http.StatusCreated, sendAs(router, lateId, "POST", basePath+"/lottery/entry", nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, lateId, "DELETE", basePath+"/lottery/entry", nil).Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(router, lateId, "GET", basePath+"/lottery/entry", nil).Code)
+
+	assert.Equal(t, http.StatusNotFound, sendAs(router, firstId, "GET", basePath+"/lottery/results", nil).Code)
+
+	// Close the entry window and draw
+	closedAt := time.Now().Add(-time.Minute).UTC()
+	_, err := db.DB.Exec("UPDATE lotteries SET closes_at = ? WHERE event_id = ?", closedAt, event.ID)
+	if err != nil {
+		t.Fatalf("Failed to close lottery: %v", err)
+	}
+	saved, _ := models.GetLottery(event.ID)
+	drawn, err := saved.Draw(event, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, drawn)
+
+	assert.Equal(t, http.StatusConflict, sendAs(router, organizerId, "PUT", basePath+"/lottery", lottery).Code)
+
+	w = sendAs(router, firstId, "GET", basePath+"/lottery/results", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Seed    string                 `json:"seed"`
+		Results []models.LotteryResult `json:"results"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	seedHash := sha256.Sum256([]byte(response.Seed))
+	assert.Equal(t, published.SeedHash, hex.EncodeToString(seedHash[:]))
+	if assert.Len(t, response.Results, 2) {
+		assert.Equal(t, "Majors", response.Results[0].PriorityGroup)
+		assert.Equal(t, models.LotteryWon, response.Results[1].Outcome)
+	}
+
+	var entry models.LotteryEntry
+	w = sendAs(router, secondId, "GET", basePath+"/lottery/entry", nil)
+	json.Unmarshal(w.Body.Bytes(), &entry)
+	assert.Equal(t, models.LotteryWon, entry.Outcome)
+	assert.Equal(t, response.Results[0].EntryID, entry.ID)
+
+	// Seats left after the draw are first come, first served
+	assert.Equal(t, http.StatusConflict, sendAs(router, firstId, "POST", basePath+"/register", nil).Code)
+	assert.Equal(t, http.StatusCreated, sendAs(router, lateId, "POST", basePath+"/register", nil).Code)
+}
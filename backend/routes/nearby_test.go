@@ -0,0 +1,97 @@
+package routes
+
+import (
+	"encoding/json"
+	"event-planner/geo"
+	"event-planner/models"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupNearbyRouter(t *testing.T) *gin.Engine {
+	campus, err := geo.NewCampusMap([]geo.Place{
+		{Name: "Observatory", Aliases: []string{"OBS"}, Latitude: -33.9345, Longitude: 18.4772},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create campus map: %v", err)
+	}
+	Geocoder = campus
+	t.Cleanup(func() { Geocoder = nil })
+
+	router := setupTransferRouter()
+	router.GET("/events", GetEvents)
+	router.POST("/events", CreateEvent)
+	router.PUT("/events/:id", UpdateEvent)
+	return router
+}
+
+func createEventAt(t *testing.T, router *gin.Engine, userId int64, body gin.H) models.Event {
+	body["Description"] = "Test Description"
+	body["DateTime"] = time.Now().Add(48 * time.Hour)
+	w := sendAs(router, userId, "POST", "/events", body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create event: %s", w.Body.String())
+	}
+	var response struct{ Event models.Event }
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response.Event
+}
+
+func nearbyEventIDs(router *gin.Engine, query string) []int64 {
+	var events []models.Event
+	json.Unmarshal(sendAs(router, 0, "GET", "/events?"+query, nil).Body.Bytes(), &events)
+	ids := []int64{}
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestEvents_Nearby(t *testing.T) {
+	router := setupNearbyRouter(t)
+	organizerId := createTestUser(t, "nearby-organizer@example.com")
+
+	// Geocoded from the campus map
+	observatory := createEventAt(t, router, organizerId, gin.H{"Name": "Star Party", "Location": "OBS dome"})
+	if assert.NotNil(t, observatory.Latitude) {
+		assert.Equal(t, -33.9345, *observatory.Latitude)
+		assert.Equal(t, 18.4772, *observatory.Longitude)
+	}
+
+	// About 700m north-east of the observatory: inside the bounding box of a
+	// 600m search but outside its radius
+	corner := createEventAt(t, router, organizerId, gin.H{"Name": "Corner", "Location": "Field", "Latitude": -33.9300, "Longitude": 18.4826})
+	far := createEventAt(t, router, organizerId, gin.H{"Name": "Far", "Location": "Harbour", "Latitude": -33.9036, "Longitude": 18.4208})
+	unknown := createEventAt(t, router, organizerId, gin.H{"Name": "Unknown", "Location": "Somewhere"})
+	assert.Nil(t, unknown.Latitude)
+
+	ids := nearbyEventIDs(router, "near=-33.9345,18.4772&radius=600")
+	assert.Contains(t, ids, observatory.ID)
+	assert.NotContains(t, ids, corner.ID)
+	assert.NotContains(t, ids, far.ID)
+	assert.NotContains(t, ids, unknown.ID)
+
+	ids = nearbyEventIDs(router, "near=-33.9345,18.4772&radius=800")
+	assert.Contains(t, ids, corner.ID)
+	assert.NotContains(t, ids, far.ID)
+
+	ids = nearbyEventIDs(router, "near=-33.9345,18.4772&radius=10000&q=harbour")
+	assert.Equal(t, []int64{far.ID}, ids)
+
+	// Given coordinates are kept over the geocoder's
+	path := "/events/" + strconv.FormatInt(observatory.ID, 10)
+	update := gin.H{"Name": "Star Party", "Description": "Test Description", "Location": "OBS dome", "DateTime": observatory.DateTime, "Latitude": -33.9036, "Longitude": 18.4208}
+	assert.Equal(t, http.StatusOK, sendAs(router, organizerId, "PUT", path, update).Code)
+	assert.NotContains(t, nearbyEventIDs(router, "near=-33.9345,18.4772&radius=600"), observatory.ID)
+
+	for _, query := range []string{"near=abc", "near=-33.9,", "near=95,18", "near=-33.9,18.4&radius=0", "near=-33.9,18.4&radius=100000"} {
+		assert.Equal(t, http.StatusBadRequest, sendAs(router, 0, "GET", "/events?"+query, nil).Code, query)
+	}
+	w := sendAs(router, organizerId, "POST", "/events", gin.H{"Name": "Half", "Description": "Test Description", "Location": "Field", "DateTime": time.Now().Add(time.Hour), "Latitude": 10})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,102 @@
+package routes
+
+import (
+	"event-planner/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Helper function to parse page and limit query parameters
+func parsePagination(context *gin.Context) (page int, limit int, ok bool) {
+	page, err := strconv.Atoi(context.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse page"})
+		return 0, 0, false
+	}
+
+	limit, err = strconv.Atoi(context.DefaultQuery("limit", strconv.Itoa(defaultPageSize)))
+	if err != nil || limit < 1 || limit > maxPageSize {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Limit must be between 1 and " + strconv.Itoa(maxPageSize)})
+		return 0, 0, false
+	}
+	return page, limit, true
+}
+
+func getNotifications(context *gin.Context) {
+	page, limit, ok := parsePagination(context)
+	if !ok {
+		return
+	}
+
+	userId := context.GetInt64("userId")
+	notifications, err := models.GetNotificationsForUser(userId, limit, (page-1)*limit)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch notifications"})
+		return
+	}
+
+	total, unread, err := models.CountNotifications(userId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch notifications"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"page":          page,
+		"limit":         limit,
+		"total":         total,
+		"unreadCount":   unread,
+	})
+}
+
+func getUnreadNotificationCount(context *gin.Context) {
+	userId := context.GetInt64("userId")
+	_, unread, err := models.CountNotifications(userId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not count notifications"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"unreadCount": unread})
+}
+
+func markNotificationRead(context *gin.Context) {
+	notificationId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse notification id"})
+		return
+	}
+
+	userId := context.GetInt64("userId")
+	found, err := models.MarkNotificationRead(notificationId, userId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not mark notification as read"})
+		return
+	}
+
+	if !found {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Notification not found"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+func markAllNotificationsRead(context *gin.Context) {
+	userId := context.GetInt64("userId")
+	err := models.MarkAllNotificationsRead(userId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not mark notifications as read"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read"})
+}
@@ -0,0 +1,133 @@
+package routes
+
+import (
+	"encoding/json"
+	"event-planner/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupNotificationRouter(userId int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userId", userId)
+	})
+	router.GET("/me/notifications", getNotifications)
+	router.GET("/me/notifications/unread-count", getUnreadNotificationCount)
+	router.POST("/me/notifications/read-all", markAllNotificationsRead)
+	router.POST("/me/notifications/:id/read", markNotificationRead)
+	return router
+}
+
+func createNotifications(t *testing.T, userId int64, count int) []models.Notification {
+	var notifications []models.Notification
+	for i := 0; i < count; i++ {
+		notification := models.Notification{
+			UserID:    userId,
+			Type:      "event.changed",
+			Title:     "Notification " + strconv.Itoa(i),
+			Body:      "Body",
+			CreatedAt: time.Now().Add(time.Duration(i) * time.Second),
+		}
+		err := notification.Save()
+		if err != nil {
+			t.Fatalf("Failed to create notification: %v", err)
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications
+}
+
+func TestGetNotifications_Paginates(t *testing.T) {
+	createNotifications(t, 50, 3)
+	router := setupNotificationRouter(50)
+
+	req, _ := http.NewRequest("GET", "/me/notifications?page=1&limit=2", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Notifications []models.Notification `json:"notifications"`
+		Total         int                   `json:"total"`
+		UnreadCount   int                   `json:"unreadCount"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Len(t, response.Notifications, 2)
+	assert.Equal(t, "Notification 2", response.Notifications[0].Title)
+	assert.Equal(t, 3, response.Total)
+	assert.Equal(t, 3, response.UnreadCount)
+
+	req, _ = http.NewRequest("GET", "/me/notifications?page=2&limit=2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Len(t, response.Notifications, 1)
+	assert.Equal(t, "Notification 0", response.Notifications[0].Title)
+}
+
+func TestGetNotifications_InvalidLimit(t *testing.T) {
+	router := setupNotificationRouter(50)
+
+	req, _ := http.NewRequest("GET", "/me/notifications?limit=1000", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestMarkNotificationRead(t *testing.T) {
+	notifications := createNotifications(t, 51, 2)
+	path := "/me/notifications/" + strconv.FormatInt(notifications[0].ID, 10) + "/read"
+
+	// Another user cannot mark it
+	req, _ := http.NewRequest("POST", path, nil)
+	w := httptest.NewRecorder()
+	setupNotificationRouter(52).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	router := setupNotificationRouter(51)
+	req, _ = http.NewRequest("POST", path, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("GET", "/me/notifications/unread-count", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]int
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 1, response["unreadCount"])
+
+	req, _ = http.NewRequest("POST", "/me/notifications/read-all", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("GET", "/me/notifications/unread-count", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 0, response["unreadCount"])
+}
+
+func TestMarkNotificationRead_InvalidID(t *testing.T) {
+	router := setupNotificationRouter(51)
+
+	req, _ := http.NewRequest("POST", "/me/notifications/invalid/read", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
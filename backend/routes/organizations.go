@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"event-planner/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Helper function to parse organization ID from URL parameter
+func parseOrganizationID(context *gin.Context) (int64, bool) {
+	organizationId, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse organization id"})
+		return 0, false
+	}
+	return organizationId, true
+}
+
+// Helper function to load the organization in the URL and check the user owns it
+func getOwnedOrganization(context *gin.Context) (*models.Organization, bool) {
+	organizationId, ok := parseOrganizationID(context)
+	if !ok {
+		return nil, false
+	}
+
+	organization, err := models.GetOrganizationByID(organizationId)
+	if err != nil {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Organization not found"})
+		return nil, false
+	}
+
+	if organization.OwnerID != context.GetInt64("userId") {
+		context.JSON(http.StatusUnauthorized, gin.H{"message": "You are not authorized to manage this organization"})
+		return nil, false
+	}
+	return organization, true
+}
+
+func getOrganizations(context *gin.Context) {
+	organizations, err := models.GetAllOrganizations()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch organizations"})
+		return
+	}
+	context.JSON(http.StatusOK, organizations)
+}
+
+func getOrganization(context *gin.Context) {
+	organizationId, ok := parseOrganizationID(context)
+	if !ok {
+		return
+	}
+
+	organization, err := models.GetOrganizationByID(organizationId)
+	if err != nil {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Organization not found"})
+		return
+	}
+	context.JSON(http.StatusOK, organization)
+}
+
+func createOrganization(context *gin.Context) {
+	var organization models.Organization
+	err := context.ShouldBindJSON(&organization)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	organization.OwnerID = context.GetInt64("userId")
+	err = organization.Save()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create organization"})
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Organization created successfully", "organization": organization})
+}
@@ -0,0 +1,153 @@
+package routes
+
+import (
+	"event-planner/models"
+	"event-planner/notifications"
+	"event-planner/utils"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type preferencesRequest struct {
+	Settings    *models.NotificationSettings
+	Preferences []models.NotificationPreference
+}
+
+func getNotificationPreferences(context *gin.Context) {
+	userId := context.GetInt64("userId")
+	settings, err := models.GetNotificationSettings(userId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch notification settings"})
+		return
+	}
+
+	preferences, err := notifications.PreferencesFor(userId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch notification preferences"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"settings": settings, "preferences": preferences})
+}
+
+func updateNotificationPreferences(context *gin.Context) {
+	var request preferencesRequest
+	err := context.ShouldBindJSON(&request)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	userId := context.GetInt64("userId")
+
+	if request.Settings != nil {
+		request.Settings.UserID = userId
+		if request.Settings.TimeZone == "" {
+			request.Settings.TimeZone = "UTC"
+		}
+
+		err = request.Settings.Validate()
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+	}
+
+	for _, preference := range request.Preferences {
+		err = notifications.ValidatePreference(preference)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+	}
+
+	if request.Settings != nil {
+		err = request.Settings.Save()
+		if err != nil {
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not save notification settings"})
+			return
+		}
+	}
+
+	for _, preference := range request.Preferences {
+		err = preference.Save(userId)
+		if err != nil {
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not save notification preferences"})
+			return
+		}
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Notification preferences updated successfully"})
+}
+
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Unsubscribe</title></head>
+<body>
+{{if .Confirm}}
+<p>Stop receiving {{.Type}} notifications by {{.Channel}}?</p>
+<form method="post">
+<input type="hidden" name="List-Unsubscribe" value="One-Click">
+<button type="submit">Unsubscribe</button>
+</form>
+{{else}}
+<p>{{.Message}}</p>
+{{end}}
+</body>
+</html>
+`))
+
+type unsubscribeView struct {
+	Confirm bool
+	Type    string
+	Channel string
+	Message string
+}
+
+// Helper function to render the unsubscribe page with the given status
+func renderUnsubscribe(context *gin.Context, status int, view unsubscribeView) {
+	context.Status(status)
+	context.Header("Content-Type", "text/html; charset=utf-8")
+	err := unsubscribePage.Execute(context.Writer, view)
+	if err != nil {
+		log.Printf("could not render unsubscribe page: %v", err)
+	}
+}
+
+// confirmUnsubscribe shows the page behind the link at the bottom of every
+// email. It only asks for confirmation: link scanners and prefetchers follow
+// GET links, so the unsubscribe itself happens on POST.
+func confirmUnsubscribe(context *gin.Context) {
+	_, notificationType, channel, err := utils.VerifyUnsubscribeToken(context.Query("token"))
+	if err != nil {
+		renderUnsubscribe(context, http.StatusBadRequest, unsubscribeView{Message: "Invalid unsubscribe link"})
+		return
+	}
+
+	if notificationType == notifications.AllTypes {
+		notificationType = "all"
+	}
+	renderUnsubscribe(context, http.StatusOK, unsubscribeView{Confirm: true, Type: notificationType, Channel: channel})
+}
+
+// unsubscribe turns the notifications off. It is public and authenticated by
+// the signed token alone, and serves both the confirmation form and RFC 8058
+// one-click unsubscribe from mail clients.
+func unsubscribe(context *gin.Context) {
+	userId, notificationType, channel, err := utils.VerifyUnsubscribeToken(context.Query("token"))
+	if err != nil {
+		renderUnsubscribe(context, http.StatusBadRequest, unsubscribeView{Message: "Invalid unsubscribe link"})
+		return
+	}
+
+	err = notifications.Unsubscribe(userId, notificationType, channel)
+	if err != nil {
+		renderUnsubscribe(context, http.StatusInternalServerError, unsubscribeView{Message: "Could not unsubscribe"})
+		return
+	}
+
+	renderUnsubscribe(context, http.StatusOK, unsubscribeView{Message: "You have been unsubscribed"})
+}
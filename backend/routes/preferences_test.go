@@ -0,0 +1,140 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"event-planner/db"
+	"event-planner/models"
+	"event-planner/utils"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestUser(t *testing.T, email string) int64 {
+	result, err := db.DB.Exec("INSERT INTO users (email, password) VALUES (?, 'x')", email)
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	id, _ := result.LastInsertId()
+	return id
+}
+
+func setupPreferencesRouter(userId int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/unsubscribe", confirmUnsubscribe)
+	router.POST("/unsubscribe", unsubscribe)
+	authenticated := router.Group("/", func(c *gin.Context) {
+		c.Set("userId", userId)
+	})
+	authenticated.GET("/me/notification-preferences", getNotificationPreferences)
+	authenticated.PUT("/me/notification-preferences", updateNotificationPreferences)
+	return router
+}
+
+type preferencesResponse struct {
+	Settings    models.NotificationSettings     `json:"settings"`
+	Preferences []models.NotificationPreference `json:"preferences"`
+}
+
+func getPreferences(t *testing.T, router *gin.Engine) preferencesResponse {
+	req, _ := http.NewRequest("GET", "/me/notification-preferences", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response preferencesResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response
+}
+
+func findPreference(preferences []models.NotificationPreference, notificationType, channel string) models.NotificationPreference {
+	for _, preference := range preferences {
+		if preference.Type == notificationType && preference.Channel == channel {
+			return preference
+		}
+	}
+	return models.NotificationPreference{}
+}
+
+func TestNotificationPreferences_UpdateAndRead(t *testing.T) {
+	userId := createTestUser(t, "prefs@example.com")
+	router := setupPreferencesRouter(userId)
+
+	response := getPreferences(t, router)
+	assert.Equal(t, "UTC", response.Settings.TimeZone)
+	assert.True(t, findPreference(response.Preferences, "event.changed", "email").Enabled)
+	assert.False(t, findPreference(response.Preferences, "event.changed", "sms").Enabled)
+
+	body := `{
+		"settings": {"timeZone": "Europe/Berlin", "quietHoursStart": "22:00", "quietHoursEnd": "07:00"},
+		"preferences": [{"type": "event.changed", "channel": "email", "enabled": true, "frequency": "digest"}]
+	}`
+	req, _ := http.NewRequest("PUT", "/me/notification-preferences", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	response = getPreferences(t, router)
+	assert.Equal(t, "Europe/Berlin", response.Settings.TimeZone)
+	assert.Equal(t, "22:00", response.Settings.QuietHoursStart)
+	assert.Equal(t, "digest", findPreference(response.Preferences, "event.changed", "email").Frequency)
+}
+
+func TestNotificationPreferences_RejectsInvalidInput(t *testing.T) {
+	router := setupPreferencesRouter(createTestUser(t, "invalid-prefs@example.com"))
+
+	for _, body := range []string{
+		`{"settings": {"timeZone": "Mars/Olympus"}}`,
+		`{"settings": {"timeZone": "UTC", "quietHoursStart": "22:00"}}`,
+		`{"settings": {"timeZone": "UTC", "webhookUrl": "http://169.254.169.254/latest/meta-data"}}`,
+		`{"settings": {"timeZone": "UTC", "webhookUrl": "file:///etc/passwd"}}`,
+		`{"preferences": [{"type": "event.changed", "channel": "pigeon", "frequency": "immediate"}]}`,
+		`{"preferences": [{"type": "event.changed", "channel": "email", "frequency": "hourly"}]}`,
+	} {
+		req, _ := http.NewRequest("PUT", "/me/notification-preferences", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func TestUnsubscribe_WithoutLogin(t *testing.T) {
+	userId := createTestUser(t, "unsubscribe@example.com")
+	router := setupPreferencesRouter(userId)
+	token := utils.GenerateUnsubscribeToken(userId, "event.reminder", "email")
+
+	// Following the link only asks for confirmation
+	req, _ := http.NewRequest("GET", "/unsubscribe?token="+url.QueryEscape(token), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<form method="post">`)
+
+	response := getPreferences(t, router)
+	assert.True(t, findPreference(response.Preferences, "event.reminder", "email").Enabled)
+
+	req, _ = http.NewRequest("POST", "/unsubscribe?token="+url.QueryEscape(token), bytes.NewBufferString("List-Unsubscribe=One-Click"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	response = getPreferences(t, router)
+	assert.False(t, findPreference(response.Preferences, "event.reminder", "email").Enabled)
+	assert.True(t, findPreference(response.Preferences, "event.changed", "email").Enabled)
+
+	for _, method := range []string{"GET", "POST"} {
+		req, _ = http.NewRequest(method, "/unsubscribe?token=forged", nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, method)
+	}
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"event-planner/models"
+	"event-planner/recommend"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getMyRecommendations returns the events suggested to the user by the last
+// recommendations run, each with the reasons it was picked. Runs happen in
+// the background, so new users may have none yet.
+func getMyRecommendations(context *gin.Context) {
+	limit, err := strconv.Atoi(context.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > recommend.PerUser {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Limit must be between 1 and " + strconv.Itoa(recommend.PerUser)})
+		return
+	}
+
+	recommendations, err := models.GetRecommendations(context.GetInt64("userId"), limit, time.Now())
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch recommendations"})
+		return
+	}
+	context.JSON(http.StatusOK, recommendations)
+}
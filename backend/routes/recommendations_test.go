@@ -0,0 +1,85 @@
+package routes
+
+import (
+	"encoding/json"
+	"event-planner/models"
+	"event-planner/recommend"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRecommendationRouter() *gin.Engine {
+	router := setupTransferRouter()
+	router.POST("/organizations/:id/follow", followOrganization)
+	router.DELETE("/organizations/:id/follow", unfollowOrganization)
+	router.GET("/me/follows", getMyFollows)
+	router.GET("/me/recommendations", getMyRecommendations)
+	return router
+}
+
+func TestRecommendations_FromFollowsAndHistory(t *testing.T) {
+	router := setupRecommendationRouter()
+	organizerId := createTestUser(t, "recommend-organizer@example.com")
+	userId := createTestUser(t, "recommend-user@example.com")
+
+	organization := models.Organization{Name: "Astronomy Club", OwnerID: organizerId}
+	assert.NoError(t, organization.Save())
+	organizationPath := "/organizations/" + strconv.FormatInt(organization.ID, 10) + "/follow"
+
+	stargazing := models.Event{
+		Name:           "Stargazing Night",
+		Description:    "Test Description",
+		Location:       "Test Location",
+		DateTime:       time.Now().Add(48 * time.Hour),
+		UserID:         organizerId,
+		OrganizationID: &organization.ID,
+		Tags:           []string{"telescopes"},
+	}
+	assert.NoError(t, stargazing.Save())
+
+	assert.Equal(t, http.StatusNotFound, sendAs(router, userId, "POST", "/organizations/99999/follow", nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, userId, "POST", organizationPath, nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, userId, "POST", organizationPath, nil).Code)
+
+	var follows []models.Follow
+	w := sendAs(router, userId, "GET", "/me/follows", nil)
+	json.Unmarshal(w.Body.Bytes(), &follows)
+	if assert.Len(t, follows, 1) {
+		assert.Equal(t, "Astronomy Club", follows[0].Name)
+	}
+
+	// Nothing until the background job has run
+	var recommendations []models.RecommendedEvent
+	w = sendAs(router, userId, "GET", "/me/recommendations", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &recommendations)
+	assert.Empty(t, recommendations)
+
+	assert.NoError(t, recommend.Refresh(time.Now()))
+
+	w = sendAs(router, userId, "GET", "/me/recommendations", nil)
+	json.Unmarshal(w.Body.Bytes(), &recommendations)
+	if assert.Len(t, recommendations, 1) {
+		assert.Equal(t, stargazing.ID, recommendations[0].Event.ID)
+		assert.Equal(t, []string{"telescopes"}, recommendations[0].Event.Tags)
+		assert.Equal(t, "From Astronomy Club, which you follow", recommendations[0].Reasons[0].Message)
+	}
+
+	// Registering hides the event before the next run
+	assert.NoError(t, stargazing.Register(userId, nil))
+	w = sendAs(router, userId, "GET", "/me/recommendations", nil)
+	json.Unmarshal(w.Body.Bytes(), &recommendations)
+	assert.Empty(t, recommendations)
+
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, userId, "GET", "/me/recommendations?limit=500", nil).Code)
+
+	assert.Equal(t, http.StatusOK, sendAs(router, userId, "DELETE", organizationPath, nil).Code)
+	w = sendAs(router, userId, "GET", "/me/follows", nil)
+	json.Unmarshal(w.Body.Bytes(), &follows)
+	assert.Empty(t, follows)
+}
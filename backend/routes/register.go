@@ -0,0 +1,340 @@
+package routes
+
+import (
+	"errors"
+	"event-planner/models"
+	"event-planner/notifications"
+	"event-planner/webhooks"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxGroupMembers caps how many members one group registration may name.
+const maxGroupMembers = 20
+
+// registrationRequest is the optional body of a registration. Members are the
+// emails of other users to register as a group.
+type registrationRequest struct {
+	Answers   models.RegistrationAnswers
+	Guests    int
+	GroupName string
+	Members   []string
+}
+
+// Helper function to reply to registration errors, reporting whether there was one
+func handleRegistrationError(context *gin.Context, err error) bool {
+	var answerErr *models.AnswerError
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &answerErr):
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid registration answers", "field": answerErr.Field, "error": answerErr.Message})
+	case errors.Is(err, models.ErrGuestLimit):
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Too many guests for this event"})
+	case errors.Is(err, models.ErrEventFull):
+		context.JSON(http.StatusConflict, gin.H{"message": "Event is full"})
+	case errors.Is(err, models.ErrAlreadyRegistered):
+		context.JSON(http.StatusConflict, gin.H{"message": "You are already registered for this event"})
+	case errors.Is(err, models.ErrNoInvitation):
+		context.JSON(http.StatusNotFound, gin.H{"message": "You have no pending invitation for this event"})
+	default:
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not register user for event"})
+	}
+	return true
+}
+
+// Helper function to refuse a registration overlapping the user's other
+// registrations, unless they asked to register anyway with ?force=true
+func checkScheduleConflicts(context *gin.Context, event *models.Event, userId int64) bool {
+	if context.Query("force") == "true" {
+		return true
+	}
+
+	conflicts, err := event.Conflicts(userId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not check schedule conflicts"})
+		return false
+	}
+	if len(conflicts) > 0 {
+		context.JSON(http.StatusConflict, gin.H{
+			"message":   "This event overlaps events you are registered for; register with ?force=true to go anyway",
+			"conflicts": conflicts,
+		})
+		return false
+	}
+	return true
+}
+
+// Helper function to resolve group member emails to users
+func getGroupMembers(context *gin.Context, event *models.Event, leaderId int64, emails []string) ([]models.User, bool) {
+	if len(emails) > maxGroupMembers {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "A group can have at most " + strconv.Itoa(maxGroupMembers) + " members"})
+		return nil, false
+	}
+
+	var members []models.User
+	seen := map[int64]bool{leaderId: true}
+	for _, email := range emails {
+		member, err := models.GetUserByEmail(strings.TrimSpace(email))
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "No account found for " + email})
+			return nil, false
+		}
+
+		if seen[member.ID] {
+			context.JSON(http.StatusBadRequest, gin.H{"message": email + " is listed more than once"})
+			return nil, false
+		}
+		seen[member.ID] = true
+
+		_, err = models.GetRegistration(event.ID, member.ID)
+		if err == nil {
+			context.JSON(http.StatusConflict, gin.H{"message": email + " is already registered for this event"})
+			return nil, false
+		}
+
+		members = append(members, *member)
+	}
+	return members, true
+}
+
+func registerForEvent(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	// The body is optional for events without a registration form
+	var request registrationRequest
+	if context.Request.ContentLength != 0 {
+		err := context.ShouldBindJSON(&request)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+			return
+		}
+	}
+
+	userId := context.GetInt64("userId")
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkNoPendingLottery(context, eventId) {
+		return
+	}
+
+	if !checkScheduleConflicts(context, event, userId) {
+		return
+	}
+
+	if len(request.Members) == 0 {
+		err := event.RegisterWithGuests(userId, request.Guests, request.Answers)
+		if handleRegistrationError(context, err) {
+			return
+		}
+
+		notifyRegistered(*event, userId)
+		publishWebhook(event.OrganizationID, webhooks.RegistrationCreated, gin.H{"eventId": event.ID, "userId": userId})
+
+		context.JSON(http.StatusCreated, gin.H{"message": "Registered successfully"})
+		return
+	}
+
+	members, ok := getGroupMembers(context, event, userId, request.Members)
+	if !ok {
+		return
+	}
+
+	memberIds := make([]int64, len(members))
+	for i, member := range members {
+		memberIds[i] = member.ID
+	}
+
+	group, err := event.RegisterGroup(userId, strings.TrimSpace(request.GroupName), request.Guests, request.Answers, memberIds)
+	if handleRegistrationError(context, err) {
+		return
+	}
+
+	notifyRegistered(*event, userId)
+	publishWebhook(event.OrganizationID, webhooks.RegistrationCreated, gin.H{"eventId": event.ID, "userId": userId})
+
+	leader, err := models.GetUserByID(userId)
+	for _, member := range members {
+		if err == nil {
+			err = notifications.GroupInvitation(notifications.Default, *event, *leader, member)
+		}
+		if err != nil {
+			log.Printf("could not invite user %d to event %d: %v", member.ID, event.ID, err)
+		}
+	}
+
+	for i := range group.Members {
+		group.Members[i].TicketToken = ""
+	}
+	context.JSON(http.StatusCreated, gin.H{"message": "Registered successfully", "group": group})
+}
+
+// getMyRegistration returns the user's own registration with their ticket,
+// and the group they lead or belong to.
+func getMyRegistration(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	registration, err := models.GetRegistration(eventId, context.GetInt64("userId"))
+	if err != nil {
+		context.JSON(http.StatusNotFound, gin.H{"message": "You are not registered for this event"})
+		return
+	}
+
+	if registration.Status != models.RegistrationConfirmed {
+		// Tickets are only valid once the invitation is accepted
+		registration.TicketToken = ""
+	}
+
+	response := gin.H{"registration": registration}
+	if registration.GroupID != nil {
+		group, err := models.GetRegistrationGroup(*registration.GroupID)
+		if err != nil {
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch registration group"})
+			return
+		}
+
+		for i := range group.Members {
+			group.Members[i].TicketToken = ""
+			group.Members[i].Answers = nil
+		}
+		response["group"] = group
+	}
+	context.JSON(http.StatusOK, response)
+}
+
+func acceptInvitation(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	var request registrationRequest
+	if context.Request.ContentLength != 0 {
+		err := context.ShouldBindJSON(&request)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+			return
+		}
+	}
+
+	userId := context.GetInt64("userId")
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkScheduleConflicts(context, event, userId) {
+		return
+	}
+
+	err := event.AcceptInvitation(userId, request.Answers)
+	if handleRegistrationError(context, err) {
+		return
+	}
+
+	notifyRegistered(*event, userId)
+	publishWebhook(event.OrganizationID, webhooks.RegistrationCreated, gin.H{"eventId": event.ID, "userId": userId})
+
+	context.JSON(http.StatusOK, gin.H{"message": "Invitation accepted"})
+}
+
+func declineInvitation(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	err := event.DeclineInvitation(context.GetInt64("userId"))
+	if handleRegistrationError(context, err) {
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
+
+func cancelRegistration(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	userId := context.GetInt64("userId")
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	err := event.CancelRegistration(userId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not cancel registration"})
+		return
+	}
+
+	publishWebhook(event.OrganizationID, webhooks.RegistrationCancelled, gin.H{"eventId": event.ID, "userId": userId})
+
+	context.JSON(http.StatusOK, gin.H{"message": "Registration cancelled successfully"})
+}
+
+func notifyRegistered(event models.Event, userId int64) {
+	user, err := models.GetUserByID(userId)
+	if err == nil {
+		err = notifications.RegistrationConfirmed(notifications.Default, event, *user)
+	}
+
+	if err != nil {
+		log.Printf("could not confirm registration of user %d for event %d: %v", userId, event.ID, err)
+	}
+}
+
+func checkInAttendee(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	attendeeId, err := strconv.ParseInt(context.Param("userId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse user id"})
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventOrganizer(context, event, context.GetInt64("userId"), "check in attendees") {
+		return
+	}
+
+	err = event.CheckIn(attendeeId, time.Now())
+	if errors.Is(err, models.ErrNotRegistered) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "User is not registered for this event"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not check in attendee"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Attendee checked in"})
+}
@@ -0,0 +1,337 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"event-planner/db"
+	"event-planner/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRegistrationRouter(userId int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userId", userId)
+	})
+	router.POST("/events/:id/register", registerForEvent)
+	router.DELETE("/events/:id/register", cancelRegistration)
+	return router
+}
+
+func countReminders(t *testing.T, eventID int64) int {
+	var count int
+	err := db.DB.QueryRow("SELECT COUNT(*) FROM reminders WHERE event_id = ?", eventID).Scan(&count)
+	if err != nil {
+		t.Fatalf("Failed to count reminders: %v", err)
+	}
+	return count
+}
+
+func TestRegisterForEvent_SchedulesReminders(t *testing.T) {
+	event := models.Event{
+		Name:        "Reminder Event",
+		Description: "Test Description",
+		Location:    "Test Location",
+		DateTime:    time.Now().Add(48 * time.Hour),
+		UserID:      1,
+	}
+	err := event.Save()
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+
+	router := setupRegistrationRouter(2)
+	path := "/events/" + strconv.FormatInt(event.ID, 10) + "/register"
+
+	req, _ := http.NewRequest("POST", path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, len(models.ReminderOffsets), countReminders(t, event.ID))
+
+	req, _ = http.NewRequest("DELETE", path, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 0, countReminders(t, event.ID))
+}
+
+func TestRegisterForEvent_SkipsPastReminders(t *testing.T) {
+	event := models.Event{
+		Name:        "Soon Event",
+		Description: "Test Description",
+		Location:    "Test Location",
+		DateTime:    time.Now().Add(3 * time.Hour),
+		UserID:      1,
+	}
+	err := event.Save()
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+
+	router := setupRegistrationRouter(2)
+	req, _ := http.NewRequest("POST", "/events/"+strconv.FormatInt(event.ID, 10)+"/register", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 1, countReminders(t, event.ID))
+}
+
+func TestRegisterForEvent_InvalidID(t *testing.T) {
+	router := setupRegistrationRouter(2)
+
+	req, _ := http.NewRequest("POST", "/events/invalid/register", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRescheduleReminders_FollowsNewTime(t *testing.T) {
+	event := models.Event{
+		Name:        "Moving Event",
+		Description: "Test Description",
+		Location:    "Test Location",
+		DateTime:    time.Now().Add(3 * time.Hour),
+		UserID:      1,
+	}
+	err := event.Save()
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+
+	err = event.Register(2, nil)
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	assert.Equal(t, 1, countReminders(t, event.ID))
+
+	event.DateTime = time.Now().Add(72 * time.Hour)
+	err = event.RescheduleReminders()
+
+	assert.NoError(t, err)
+	assert.Equal(t, len(models.ReminderOffsets), countReminders(t, event.ID))
+}
+
+func TestRegisterForEvent_ValidatesFormAnswers(t *testing.T) {
+	organizerId := createTestUser(t, "form-organizer@example.com")
+	attendeeId := createTestUser(t, "form-attendee@example.com")
+	event := models.Event{
+		Name:        "Form Event",
+		Description: "Test Description",
+		Location:    "Test Location",
+		DateTime:    time.Now().Add(48 * time.Hour),
+		UserID:      organizerId,
+	}
+	err := event.Save()
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+
+	organizer := setupSurveyRouter(organizerId)
+	organizer.PUT("/events/:id/registration-form", updateRegistrationForm)
+	organizer.GET("/events/:id/registrations/export", exportRegistrations)
+	basePath := "/events/" + strconv.FormatInt(event.ID, 10)
+
+	w := sendAs(organizer, 0, "PUT", basePath+"/registration-form", gin.H{"Fields": []gin.H{
+		{"Key": "Diet", "Label": "Diet", "Kind": "text"},
+	}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendAs(organizer, 0, "PUT", basePath+"/registration-form", gin.H{"Fields": []gin.H{
+		{"Key": "diet", "Label": "Dietary restrictions", "Kind": "text", "MaxLength": 20},
+		{"Key": "shirt", "Label": "T-shirt size", "Kind": "choice", "Options": []string{"S", "M", "L"}, "Required": true},
+		{"Key": "wheelchair", "Label": "Wheelchair access", "Kind": "checkbox"},
+	}})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	attendee := setupRegistrationRouter(attendeeId)
+	path := basePath + "/register"
+
+	// Missing a required answer
+	w = sendAs(attendee, 0, "POST", path, gin.H{"Answers": gin.H{"diet": "vegan"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"shirt"`)
+
+	w = sendAs(attendee, 0, "POST", path, gin.H{"Answers": gin.H{"shirt": "XXL"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendAs(attendee, 0, "POST", path, gin.H{"Answers": gin.H{"shirt": "M", "diet": "@vegan", "wheelchair": true}})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	req, _ := http.NewRequest("GET", basePath+"/registrations/export", nil)
+	w = httptest.NewRecorder()
+	organizer.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Email,Status,Guests,Checked In At,Dietary restrictions,T-shirt size,Wheelchair access\nform-attendee@example.com,confirmed,0,,'@vegan,M,yes\n", w.Body.String())
+
+	req, _ = http.NewRequest("GET", basePath+"/registrations/export?format=xlsx", nil)
+	w = httptest.NewRecorder()
+	organizer.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", w.Header().Get("Content-Type"))
+	assert.True(t, bytes.HasPrefix(w.Body.Bytes(), []byte("PK")))
+
+	req, _ = http.NewRequest("GET", basePath+"/registrations/export?format=pdf", nil)
+	w = httptest.NewRecorder()
+	organizer.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "sign-in.pdf")
+	assert.True(t, bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")))
+
+	req, _ = http.NewRequest("GET", basePath+"/registrations/export?format=docx", nil)
+	w = httptest.NewRecorder()
+	organizer.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRegisterForEvent_GuestsCountAgainstCapacity(t *testing.T) {
+	event := models.Event{
+		Name:        "Guest Event",
+		Description: "Test Description",
+		Location:    "Test Location",
+		DateTime:    time.Now().Add(48 * time.Hour),
+		UserID:      1,
+		Capacity:    4,
+		GuestLimit:  2,
+	}
+	err := event.Save()
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+	path := "/events/" + strconv.FormatInt(event.ID, 10) + "/register"
+
+	w := sendAs(setupRegistrationRouter(createTestUser(t, "guest-1@example.com")), 0, "POST", path, gin.H{"Guests": 3})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendAs(setupRegistrationRouter(createTestUser(t, "guest-2@example.com")), 0, "POST", path, gin.H{"Guests": 2})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// One seat is left, not enough for a registrant and a guest
+	w = sendAs(setupRegistrationRouter(createTestUser(t, "guest-3@example.com")), 0, "POST", path, gin.H{"Guests": 1})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	seats, err := event.Seats()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, seats.Registered)
+}
+
+func TestRegisterForEvent_GroupMembersAcceptOrDecline(t *testing.T) {
+	leaderId := createTestUser(t, "group-leader@example.com")
+	acceptingId := createTestUser(t, "group-accepting@example.com")
+	decliningId := createTestUser(t, "group-declining@example.com")
+	event := models.Event{
+		Name:        "Team Event",
+		Description: "Test Description",
+		Location:    "Test Location",
+		DateTime:    time.Now().Add(48 * time.Hour),
+		UserID:      1,
+		Capacity:    3,
+	}
+	err := event.Save()
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+	basePath := "/events/" + strconv.FormatInt(event.ID, 10)
+
+	w := sendAs(setupRegistrationRouter(leaderId), 0, "POST", basePath+"/register", gin.H{
+		"GroupName": "Robotics",
+		"Members":   []string{"group-accepting@example.com", "nobody@example.com"},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendAs(setupRegistrationRouter(leaderId), 0, "POST", basePath+"/register", gin.H{
+		"GroupName": "Robotics",
+		"Members":   []string{"group-accepting@example.com", "group-declining@example.com"},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Invitations hold their seats
+	seats, _ := event.Seats()
+	assert.Equal(t, 3, seats.Registered)
+	registered, _ := event.IsRegistered(acceptingId)
+	assert.False(t, registered)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		userId, _ := strconv.ParseInt(c.GetHeader("X-User"), 10, 64)
+		c.Set("userId", userId)
+	})
+	router.GET("/events/:id/registration", getMyRegistration)
+	router.POST("/events/:id/invitation/accept", acceptInvitation)
+	router.POST("/events/:id/invitation/decline", declineInvitation)
+
+	send := func(method, path string, userId int64) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("X-User", strconv.FormatInt(userId, 10))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, send("POST", basePath+"/invitation/accept", acceptingId).Code)
+	assert.Equal(t, http.StatusOK, send("POST", basePath+"/invitation/decline", decliningId).Code)
+	assert.Equal(t, http.StatusNotFound, send("POST", basePath+"/invitation/decline", decliningId).Code)
+
+	registered, _ = event.IsRegistered(acceptingId)
+	assert.True(t, registered)
+	seats, _ = event.Seats()
+	assert.Equal(t, 2, seats.Registered)
+
+	w = send("GET", basePath+"/registration", acceptingId)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Registration models.Registration
+		Group        models.RegistrationGroup
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Len(t, response.Registration.TicketToken, 32)
+	assert.Equal(t, "Robotics", response.Group.Name)
+	if assert.Len(t, response.Group.Members, 1) {
+		assert.Empty(t, response.Group.Members[0].TicketToken)
+	}
+}
+
+func TestRegisterForEvent_GroupInvitationsReleaseSeats(t *testing.T) {
+	leaderId := createTestUser(t, "release-leader@example.com")
+	createTestUser(t, "release-first@example.com")
+	secondId := createTestUser(t, "release-second@example.com")
+	event := createTestEvent(t, 1, time.Now().Add(72*time.Hour))
+	event.Capacity = 3
+	if err := event.Update(); err != nil {
+		t.Fatalf("Failed to update test event: %v", err)
+	}
+	basePath := "/events/" + strconv.FormatInt(event.ID, 10)
+	group := gin.H{"GroupName": "Chess", "Members": []string{"release-first@example.com", "release-second@example.com"}}
+
+	// Cancelling withdraws the invitations the leader handed out
+	assert.Equal(t, http.StatusCreated, sendAs(setupRegistrationRouter(leaderId), 0, "POST", basePath+"/register", group).Code)
+	seats, _ := event.Seats()
+	assert.Equal(t, 3, seats.Registered)
+	assert.Equal(t, http.StatusOK, sendAs(setupRegistrationRouter(leaderId), leaderId, "DELETE", basePath+"/register", nil).Code)
+	seats, _ = event.Seats()
+	assert.Equal(t, 0, seats.Registered)
+
+	// Unanswered invitations expire
+	assert.Equal(t, http.StatusCreated, sendAs(setupRegistrationRouter(leaderId), 0, "POST", basePath+"/register", group).Code)
+	assert.NoError(t, models.ExpireInvitations(time.Now()))
+	seats, _ = event.Seats()
+	assert.Equal(t, 3, seats.Registered)
+
+	assert.NoError(t, models.ExpireInvitations(time.Now().Add(models.InvitationTTL+time.Minute)))
+	seats, _ = event.Seats()
+	assert.Equal(t, 1, seats.Registered)
+	assert.ErrorIs(t, event.AcceptInvitation(secondId, nil), models.ErrNoInvitation)
+}
@@ -0,0 +1,231 @@
+package routes
+
+import (
+	"event-planner/export"
+	"event-planner/models"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type registrationFormRequest struct {
+	Fields []models.FormField
+}
+
+func getRegistrationForm(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	if _, ok := getEventByID(context, eventId); !ok {
+		return
+	}
+
+	fields, err := models.GetRegistrationForm(eventId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch registration form"})
+		return
+	}
+	context.JSON(http.StatusOK, gin.H{"fields": fields})
+}
+
+func updateRegistrationForm(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventOrganizer(context, event, context.GetInt64("userId"), "edit the registration form") {
+		return
+	}
+
+	var request registrationFormRequest
+	err := context.ShouldBindJSON(&request)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	if request.Fields == nil {
+		request.Fields = []models.FormField{}
+	}
+
+	err = models.ValidateForm(request.Fields)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	err = models.SaveRegistrationForm(eventId, request.Fields)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not save registration form"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Registration form saved", "fields": request.Fields})
+}
+
+// exportFlushRows is how many rows an export writes between flushes to the
+// client.
+const exportFlushRows = 200
+
+// signInSheetWidths are the millimetre widths of the sign-in sheet columns
+// before the signature box.
+var signInSheetWidths = []float64{8, 58, 14, 20, 36}
+
+// exportRegistrations streams the event's registrations with their form
+// answers as CSV (the default) or XLSX, or sends a printable PDF sign-in sheet.
+func exportRegistrations(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventOrganizer(context, event, context.GetInt64("userId"), "export registrations") {
+		return
+	}
+
+	fields, err := models.GetRegistrationForm(eventId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch registration form"})
+		return
+	}
+
+	format := context.DefaultQuery("format", "csv")
+	filename := fmt.Sprintf("event-%d-registrations.%s", eventId, format)
+	switch format {
+	case "csv":
+		context.Header("Content-Type", "text/csv; charset=utf-8")
+	case "xlsx":
+		context.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	case "pdf":
+		filename = fmt.Sprintf("event-%d-sign-in.pdf", eventId)
+		context.Header("Content-Type", "application/pdf")
+	default:
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Format must be csv, xlsx or pdf"})
+		return
+	}
+	context.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	context.Status(http.StatusOK)
+
+	// The status is already sent, so failures from here on can only be logged
+	table, row, err := newRegistrationTable(context.Writer, event, fields, format)
+	if err != nil {
+		log.Printf("could not export registrations of event %d: %v", eventId, err)
+		return
+	}
+
+	count := 0
+	err = event.EachRegistration(func(registration models.Registration) error {
+		count++
+		err := table.WriteRow(row(registration, count))
+		if err != nil || count%exportFlushRows != 0 {
+			return err
+		}
+
+		err = table.Flush()
+		context.Writer.Flush()
+		return err
+	})
+	if err == nil {
+		err = table.Close()
+	}
+	if err != nil {
+		log.Printf("could not export registrations of event %d: %v", eventId, err)
+	}
+}
+
+// Helper function to start a registration export, returning the table and how
+// to turn the n-th registration into a row
+func newRegistrationTable(w io.Writer, event *models.Event, fields []models.FormField, format string) (export.Table, func(models.Registration, int) []string, error) {
+	if format == "pdf" {
+		// Attendees are listed by the name approved for their certificate, if
+		// any, and otherwise by email
+		names, err := event.CertificateNames()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		subtitle := event.DateTime.UTC().Format("Mon, 02 Jan 2006 15:04 MST") + " - " + event.Location
+		table := export.NewSignInSheet(w, event.Name, subtitle, []string{"#", "Attendee", "Guests", "Checked In", "Notes"}, signInSheetWidths)
+
+		return table, func(registration models.Registration, index int) []string {
+			checkedIn := ""
+			if registration.CheckedInAt != nil {
+				checkedIn = "Yes"
+			}
+
+			var notes []string
+			for _, field := range fields {
+				answer := formAnswerCell(registration.Answers[field.Key])
+				if answer != "" {
+					notes = append(notes, field.Label+": "+answer)
+				}
+			}
+			attendee, ok := names[registration.UserID]
+			if !ok {
+				attendee = registration.Email
+			}
+			return []string{strconv.Itoa(index), attendee, strconv.Itoa(registration.Guests), checkedIn, strings.Join(notes, "; ")}
+		}, nil
+	}
+
+	table := export.NewCSV(w)
+	if format == "xlsx" {
+		var err error
+		table, err = export.NewXLSX(w, event.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	header := []string{"Email", "Status", "Guests", "Checked In At"}
+	for _, field := range fields {
+		header = append(header, field.Label)
+	}
+
+	return table, func(registration models.Registration, index int) []string {
+		checkedIn := ""
+		if registration.CheckedInAt != nil {
+			checkedIn = registration.CheckedInAt.UTC().Format(time.RFC3339)
+		}
+
+		cells := []string{registration.Email, registration.Status, strconv.Itoa(registration.Guests), checkedIn}
+		for _, field := range fields {
+			cells = append(cells, formAnswerCell(registration.Answers[field.Key]))
+		}
+		return cells
+	}, table.WriteRow(header)
+}
+
+// Helper function to format one registration answer for the exports
+func formAnswerCell(answer any) string {
+	switch value := answer.(type) {
+	case nil:
+		return ""
+	case bool:
+		if value {
+			return "yes"
+		}
+		return "no"
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -8,14 +8,113 @@ import (
 
 func RegisterRoutes(server *gin.Engine) {
 	server.GET("/events", GetEvents)
+	server.GET("/events/stream", streamEvents)
+	server.GET("/events/facets", getEventFacets)
+	server.GET("/events/trending", getTrendingEvents)
 	server.GET("/events/:id", GetEvent)
+	server.GET("/events/:id/stream", streamEvent)
+	server.GET("/events/:id/live", liveEvent)
+	server.GET("/events/:id/comments", getComments)
+	server.GET("/events/:id/registration-form", getRegistrationForm)
+	server.GET("/events/:id/image", getEventImage)
+	server.GET("/events/:id/attachments", getAttachments)
+	server.GET("/attachments/:attachmentId/download", downloadAttachment)
+	server.GET("/events/:id/lottery", getLottery)
+	server.GET("/events/:id/lottery/results", getLotteryResults)
+	server.GET("/certificates/:code", verifyCertificate)
+	server.GET("/uploads/*key", serveUpload)
+	server.GET("/calendar", getCalendar)
+	server.GET("/categories", getCategories)
+	server.GET("/organizations", getOrganizations)
+	server.GET("/organizations/:id", getOrganization)
 
 	authenticated := server.Group("/")
 	authenticated.Use(middlewares.Authenticate)
 	authenticated.POST("/events", CreateEvent)
 	authenticated.PUT("/events/:id", UpdateEvent)
 	authenticated.DELETE("/events/:id", DeleteEvent)
+	authenticated.PUT("/events/:id/image", uploadEventImage)
+	authenticated.DELETE("/events/:id/image", deleteEventImage)
+	authenticated.POST("/events/:id/bookmark", bookmarkEvent)
+	authenticated.DELETE("/events/:id/bookmark", removeBookmark)
+	authenticated.POST("/events/:id/attachments", uploadAttachment)
+	authenticated.PUT("/events/:id/attachments/:attachmentId", updateAttachment)
+	authenticated.DELETE("/events/:id/attachments/:attachmentId", deleteAttachment)
+	authenticated.POST("/events/:id/register", registerForEvent)
+	authenticated.DELETE("/events/:id/register", cancelRegistration)
+	authenticated.GET("/events/:id/registration", getMyRegistration)
+	authenticated.POST("/events/:id/invitation/accept", acceptInvitation)
+	authenticated.POST("/events/:id/invitation/decline", declineInvitation)
+	authenticated.PUT("/events/:id/lottery", saveLottery)
+	authenticated.DELETE("/events/:id/lottery", deleteLottery)
+	authenticated.POST("/events/:id/lottery/entry", enterLottery)
+	authenticated.GET("/events/:id/lottery/entry", getMyLotteryEntry)
+	authenticated.DELETE("/events/:id/lottery/entry", withdrawLotteryEntry)
+	authenticated.GET("/events/:id/transfer-rules", getTransferRules)
+	authenticated.PUT("/events/:id/transfer-rules", updateTransferRules)
+	authenticated.POST("/events/:id/transfers", offerTransfer)
+	authenticated.POST("/transfers/:transferId/accept", acceptTransfer)
+	authenticated.POST("/transfers/:transferId/decline", declineTransfer)
+	authenticated.DELETE("/transfers/:transferId", cancelTransfer)
+	authenticated.POST("/events/:id/comments", createComment)
+	authenticated.PUT("/events/:id/comments/:commentId", updateComment)
+	authenticated.DELETE("/events/:id/comments/:commentId", deleteComment)
+	authenticated.POST("/events/:id/comments/:commentId/pin", pinComment)
+	authenticated.DELETE("/events/:id/comments/:commentId/pin", unpinComment)
+	authenticated.POST("/events/:id/comments/:commentId/report", reportComment)
+	authenticated.PUT("/events/:id/registration-form", updateRegistrationForm)
+	authenticated.GET("/events/:id/registrations/export", exportRegistrations)
+	authenticated.POST("/events/:id/registrations/:userId/check-in", checkInAttendee)
+	authenticated.GET("/events/:id/certificate-template", getCertificateTemplate)
+	authenticated.PUT("/events/:id/certificate-template", saveCertificateTemplate)
+	authenticated.GET("/events/:id/certificate", downloadCertificate)
+	authenticated.GET("/events/:id/certificates", getEventCertificates)
+	authenticated.PUT("/events/:id/certificates/:userId", nameCertificate)
+	authenticated.GET("/events/:id/survey", getSurvey)
+	authenticated.PUT("/events/:id/survey", saveSurvey)
+	authenticated.POST("/events/:id/survey/responses", respondToSurvey)
+	authenticated.GET("/events/:id/survey/results", getSurveyResults)
+	authenticated.GET("/events/:id/survey/export", exportSurveyResponses)
+
+	authenticated.GET("/me/notifications", getNotifications)
+	authenticated.GET("/me/transfers", getMyTransfers)
+	authenticated.GET("/me/recommendations", getMyRecommendations)
+	authenticated.GET("/me/follows", getMyFollows)
+	authenticated.GET("/me/bookmarks", getMyBookmarks)
+	authenticated.GET("/me/feed", getMyFeed)
+	authenticated.GET("/me/schedule", getMySchedule)
+	authenticated.GET("/me/saved-searches", getMySavedSearches)
+	authenticated.POST("/me/saved-searches", createSavedSearch)
+	authenticated.PUT("/me/saved-searches/:searchId", updateSavedSearch)
+	authenticated.DELETE("/me/saved-searches/:searchId", deleteSavedSearch)
+	authenticated.GET("/me/notifications/unread-count", getUnreadNotificationCount)
+	authenticated.POST("/me/notifications/read-all", markAllNotificationsRead)
+	authenticated.POST("/me/notifications/:id/read", markNotificationRead)
+	authenticated.GET("/me/notification-preferences", getNotificationPreferences)
+	authenticated.PUT("/me/notification-preferences", updateNotificationPreferences)
+
+	authenticated.POST("/organizations", createOrganization)
+	authenticated.POST("/organizations/:id/follow", followOrganization)
+	authenticated.DELETE("/organizations/:id/follow", unfollowOrganization)
+	authenticated.POST("/organizers/:id/follow", followOrganizer)
+	authenticated.DELETE("/organizers/:id/follow", unfollowOrganizer)
+	authenticated.GET("/organizations/:id/webhooks", getWebhooks)
+	authenticated.POST("/organizations/:id/webhooks", createWebhook)
+	authenticated.DELETE("/organizations/:id/webhooks/:webhookId", deleteWebhook)
+	authenticated.GET("/organizations/:id/webhooks/:webhookId/deliveries", getWebhookDeliveries)
+	authenticated.POST("/organizations/:id/webhooks/:webhookId/deliveries/:deliveryId/redeliver", redeliverWebhook)
+
+	admin := authenticated.Group("/admin")
+	admin.Use(middlewares.RequireAdmin)
+	admin.GET("/moderation/comments", getModerationQueue)
+	admin.POST("/categories", createCategory)
+	admin.PUT("/categories/:categoryId", updateCategory)
+	admin.DELETE("/categories/:categoryId", deleteCategory)
+	admin.POST("/moderation/comments/:commentId/hide", hideComment)
+	admin.POST("/moderation/comments/:commentId/dismiss", dismissCommentReports)
 
 	server.POST("/signup", signup)
 	server.POST("/login", login)
+	server.GET("/unsubscribe", confirmUnsubscribe)
+	server.POST("/unsubscribe", unsubscribe)
 }
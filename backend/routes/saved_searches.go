@@ -0,0 +1,111 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"event-planner/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Helper function to load the user's saved search in the URL
+func getMySavedSearch(context *gin.Context) (*models.SavedSearch, bool) {
+	searchId, err := strconv.ParseInt(context.Param("searchId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse saved search id"})
+		return nil, false
+	}
+
+	search, err := models.GetSavedSearch(searchId)
+	if errors.Is(err, sql.ErrNoRows) || err == nil && search.UserID != context.GetInt64("userId") {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Saved search not found"})
+		return nil, false
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch saved search"})
+		return nil, false
+	}
+	return search, true
+}
+
+func getMySavedSearches(context *gin.Context) {
+	searches, err := models.GetSavedSearches(context.GetInt64("userId"))
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch saved searches"})
+		return
+	}
+	context.JSON(http.StatusOK, searches)
+}
+
+func createSavedSearch(context *gin.Context) {
+	var search models.SavedSearch
+	err := context.ShouldBindJSON(&search)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	err = search.Validate()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	search.UserID = context.GetInt64("userId")
+	err = search.Save()
+	if errors.Is(err, models.ErrTooManySavedSearches) {
+		context.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not save search"})
+		return
+	}
+	context.JSON(http.StatusCreated, gin.H{"message": "Search saved", "search": search})
+}
+
+func updateSavedSearch(context *gin.Context) {
+	search, ok := getMySavedSearch(context)
+	if !ok {
+		return
+	}
+
+	var update models.SavedSearch
+	err := context.ShouldBindJSON(&update)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	err = update.Validate()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	update.ID = search.ID
+	update.UserID = search.UserID
+	update.CreatedAt = search.CreatedAt
+	err = update.Update()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update saved search"})
+		return
+	}
+	context.JSON(http.StatusOK, gin.H{"message": "Saved search updated", "search": update})
+}
+
+func deleteSavedSearch(context *gin.Context) {
+	search, ok := getMySavedSearch(context)
+	if !ok {
+		return
+	}
+
+	err := search.Delete()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete saved search"})
+		return
+	}
+	context.JSON(http.StatusOK, gin.H{"message": "Saved search deleted"})
+}
@@ -0,0 +1,91 @@
+package routes
+
+import (
+	"encoding/json"
+	"event-planner/models"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSavedSearchRouter() *gin.Engine {
+	router := setupTransferRouter()
+	router.GET("/events", GetEvents)
+	router.GET("/me/saved-searches", getMySavedSearches)
+	router.POST("/me/saved-searches", createSavedSearch)
+	router.PUT("/me/saved-searches/:searchId", updateSavedSearch)
+	router.DELETE("/me/saved-searches/:searchId", deleteSavedSearch)
+	return router
+}
+
+func TestSavedSearches_Manage(t *testing.T) {
+	router := setupSavedSearchRouter()
+	userId := createTestUser(t, "saved-search-owner@example.com")
+	otherId := createTestUser(t, "saved-search-other@example.com")
+
+	w := sendAs(router, userId, "POST", "/me/saved-searches", gin.H{"Name": "Robotics", "Query": "robot", "Tags": []string{"Hands On"}})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created struct{ Search models.SavedSearch }
+	json.Unmarshal(w.Body.Bytes(), &created)
+	assert.Equal(t, []string{"hands-on"}, created.Search.Tags)
+	assert.Equal(t, models.FrequencyImmediate, created.Search.Frequency)
+	searchPath := "/me/saved-searches/" + strconv.FormatInt(created.Search.ID, 10)
+
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, userId, "POST", "/me/saved-searches", gin.H{"Name": "Everything"}).Code)
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, userId, "POST", "/me/saved-searches", gin.H{"Name": "Robotics", "Query": "robot", "Frequency": "hourly"}).Code)
+
+	update := gin.H{"Name": "Robotics digest", "Query": "robot", "Frequency": models.FrequencyDigest}
+	assert.Equal(t, http.StatusNotFound, sendAs(router, otherId, "PUT", searchPath, update).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, userId, "PUT", searchPath, update).Code)
+
+	var searches []models.SavedSearch
+	json.Unmarshal(sendAs(router, userId, "GET", "/me/saved-searches", nil).Body.Bytes(), &searches)
+	if assert.Len(t, searches, 1) {
+		assert.Equal(t, "Robotics digest", searches[0].Name)
+		assert.Equal(t, models.FrequencyDigest, searches[0].Frequency)
+		assert.Empty(t, searches[0].Tags)
+	}
+
+	assert.Equal(t, http.StatusNotFound, sendAs(router, otherId, "DELETE", searchPath, nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, userId, "DELETE", searchPath, nil).Code)
+	json.Unmarshal(sendAs(router, userId, "GET", "/me/saved-searches", nil).Body.Bytes(), &searches)
+	assert.Empty(t, searches)
+
+	for i := 0; i < models.MaxSavedSearches; i++ {
+		sendAs(router, otherId, "POST", "/me/saved-searches", gin.H{"Name": "Search", "Query": "talk"})
+	}
+	assert.Equal(t, http.StatusConflict, sendAs(router, otherId, "POST", "/me/saved-searches", gin.H{"Name": "One more", "Query": "talk"}).Code)
+}
+
+func TestGetEvents_TextQuery(t *testing.T) {
+	router := setupSavedSearchRouter()
+	organizerId := createTestUser(t, "text-query@example.com")
+	event := models.Event{
+		Name:        "Intro to 100% Robotics",
+		Description: "Build a line follower",
+		Location:    "Makerspace",
+		DateTime:    time.Now().Add(48 * time.Hour),
+		UserID:      organizerId,
+	}
+	assert.NoError(t, event.Save())
+
+	search := func(query string) []int64 {
+		var events []models.Event
+		json.Unmarshal(sendAs(router, 0, "GET", "/events?q="+query, nil).Body.Bytes(), &events)
+		ids := []int64{}
+		for _, e := range events {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+
+	assert.Contains(t, search("robotics+makerspace"), event.ID)
+	assert.Contains(t, search("LINE+FOLLOWER"), event.ID)
+	assert.Contains(t, search("100%25"), event.ID)
+	assert.NotContains(t, search("robotics+pottery"), event.ID)
+	assert.NotContains(t, search("1_0"), event.ID)
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"event-planner/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getMySchedule lists the events the user attends or organizes that are
+// still running after ?from= (default now) and start before ?to=, with the
+// overlaps between them.
+func getMySchedule(context *gin.Context) {
+	from, ok := parseTimeQuery(context, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseTimeQuery(context, "to")
+	if !ok {
+		return
+	}
+
+	if from == nil {
+		now := time.Now()
+		from = &now
+	}
+
+	schedule, err := models.GetSchedule(context.GetInt64("userId"), *from, to)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch schedule"})
+		return
+	}
+	context.JSON(http.StatusOK, schedule)
+}
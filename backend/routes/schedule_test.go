@@ -0,0 +1,135 @@
+package routes
+
+import (
+	"encoding/json"
+	"event-planner/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupScheduleRouter() *gin.Engine {
+	router := setupTransferRouter()
+	router.GET("/me/schedule", getMySchedule)
+	return router
+}
+
+func TestRegister_DetectsConflicts(t *testing.T) {
+	router := setupScheduleRouter()
+	organizerId := createTestUser(t, "conflict-organizer@example.com")
+	studentId := createTestUser(t, "conflict-student@example.com")
+
+	start := time.Date(2031, time.March, 10, 9, 0, 0, 0, time.UTC)
+	lecture := createTestEvent(t, organizerId, start)
+	// Without an end time the lecture is assumed to last two hours
+	overlapping := createTestEvent(t, organizerId, start.Add(90*time.Minute))
+	end := start.Add(5 * time.Hour)
+	adjacent := models.Event{Name: "Seminar", Description: "Test Description", Location: "Room 2", DateTime: start.Add(2 * time.Hour), EndTime: &end, UserID: organizerId}
+	assert.NoError(t, adjacent.Save())
+
+	register := func(event models.Event, query string) *httptest.ResponseRecorder {
+		return sendAs(router, studentId, "POST", "/events/"+strconv.FormatInt(event.ID, 10)+"/register"+query, nil)
+	}
+
+	assert.Equal(t, http.StatusCreated, register(lecture, "").Code)
+	// Back-to-back events do not conflict
+	assert.Equal(t, http.StatusCreated, register(adjacent, "").Code)
+
+	w := register(overlapping, "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var response struct{ Conflicts []models.Event }
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if assert.Len(t, response.Conflicts, 2) {
+		assert.Equal(t, lecture.ID, response.Conflicts[0].ID)
+		assert.Equal(t, adjacent.ID, response.Conflicts[1].ID)
+	}
+
+	assert.Equal(t, http.StatusCreated, register(overlapping, "?force=true").Code)
+}
+
+func TestSchedule_MergesTimeline(t *testing.T) {
+	router := setupScheduleRouter()
+	organizerId := createTestUser(t, "schedule-organizer@example.com")
+	studentId := createTestUser(t, "schedule-student@example.com")
+
+	start := time.Date(2031, time.March, 17, 9, 0, 0, 0, time.UTC)
+	attending := createTestEvent(t, organizerId, start)
+	assert.NoError(t, attending.Register(studentId, nil))
+	organizing := createTestEvent(t, studentId, start.Add(time.Hour))
+	later := createTestEvent(t, organizerId, start.Add(48*time.Hour))
+	assert.NoError(t, later.Register(studentId, nil))
+	past := createTestEvent(t, organizerId, time.Date(2021, time.March, 17, 9, 0, 0, 0, time.UTC))
+	assert.NoError(t, past.Register(studentId, nil))
+	createTestEvent(t, organizerId, start)
+
+	var schedule []models.ScheduleEntry
+	w := sendAs(router, studentId, "GET", "/me/schedule", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &schedule)
+	if assert.Len(t, schedule, 3) {
+		assert.Equal(t, attending.ID, schedule[0].Event.ID)
+		assert.Equal(t, models.ScheduleAttending, schedule[0].Role)
+		assert.Equal(t, []int64{organizing.ID}, schedule[0].Conflicts)
+		assert.Equal(t, organizing.ID, schedule[1].Event.ID)
+		assert.Equal(t, models.ScheduleOrganizing, schedule[1].Role)
+		assert.Equal(t, []int64{attending.ID}, schedule[1].Conflicts)
+		assert.Equal(t, later.ID, schedule[2].Event.ID)
+		assert.Empty(t, schedule[2].Conflicts)
+		assert.True(t, schedule[0].Ends.Equal(start.Add(models.DefaultEventDuration)))
+	}
+
+	to := start.Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	json.Unmarshal(sendAs(router, studentId, "GET", "/me/schedule?to="+to, nil).Body.Bytes(), &schedule)
+	assert.Len(t, schedule, 2)
+
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, studentId, "GET", "/me/schedule?from=tomorrow", nil).Code)
+}
+
+func TestSchedule_ExpandsRecurringEvents(t *testing.T) {
+	router := setupScheduleRouter()
+	organizerId := createTestUser(t, "series-organizer@example.com")
+	studentId := createTestUser(t, "series-student@example.com")
+
+	start := time.Date(2032, time.March, 1, 9, 0, 0, 0, time.UTC)
+	weekly := models.Event{
+		Name:        "Weekly Lab",
+		Description: "Test Description",
+		Location:    "Lab",
+		DateTime:    start,
+		UserID:      organizerId,
+		Recurrence:  &models.Recurrence{Frequency: models.RecurWeekly, Interval: 1},
+	}
+	assert.NoError(t, weekly.Save())
+	assert.NoError(t, weekly.Register(studentId, nil))
+
+	// Only the third occurrence clashes
+	clash := createTestEvent(t, organizerId, start.AddDate(0, 0, 14).Add(time.Hour))
+	w := sendAs(router, studentId, "POST", "/events/"+strconv.FormatInt(clash.ID, 10)+"/register", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var response struct{ Conflicts []models.Event }
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if assert.Len(t, response.Conflicts, 1) {
+		assert.Equal(t, weekly.ID, response.Conflicts[0].ID)
+	}
+	assert.Equal(t, http.StatusCreated, sendAs(router, studentId, "POST", "/events/"+strconv.FormatInt(clash.ID, 10)+"/register?force=true", nil).Code)
+
+	var schedule []models.ScheduleEntry
+	from := start.Add(-time.Hour).Format(time.RFC3339)
+	to := start.AddDate(0, 0, 21).Format(time.RFC3339)
+	json.Unmarshal(sendAs(router, studentId, "GET", "/me/schedule?from="+from+"&to="+to, nil).Body.Bytes(), &schedule)
+	if assert.Len(t, schedule, 4) {
+		for week := range 3 {
+			assert.Equal(t, weekly.ID, schedule[week].Event.ID)
+			assert.True(t, schedule[week].Start.Equal(start.AddDate(0, 0, 7*week)))
+		}
+		assert.Empty(t, schedule[0].Conflicts)
+		assert.Equal(t, []int64{clash.ID}, schedule[2].Conflicts)
+		assert.Equal(t, clash.ID, schedule[3].Event.ID)
+		assert.Equal(t, []int64{weekly.ID}, schedule[3].Conflicts)
+	}
+}
@@ -0,0 +1,115 @@
+package routes
+
+import (
+	"encoding/json"
+	"event-planner/bus"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// heartbeatInterval keeps idle connections from being closed by proxies.
+var heartbeatInterval = 15 * time.Second
+
+// streamEvents pushes every event change and seat update as Server-Sent Events.
+func streamEvents(context *gin.Context) {
+	streamMessages(context, nil, nil)
+}
+
+// streamEvent pushes changes to a single event, starting with its current seats.
+func streamEvent(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	seats, err := event.Seats()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch seat availability"})
+		return
+	}
+
+	snapshot := &bus.Message{Topic: bus.SeatsUpdated, EventID: eventId, Data: seats}
+	streamMessages(context, func(msg bus.Message) bool { return msg.EventID == eventId }, snapshot)
+}
+
+// streamMessages replays what the client missed since its Last-Event-ID, then
+// forwards new bus messages until the client disconnects. EventSource sends
+// Last-Event-ID as a header on reconnect; the lastEventId query parameter
+// covers the first connection of a page that kept the ID itself.
+func streamMessages(context *gin.Context, filter func(bus.Message) bool, snapshot *bus.Message) {
+	lastEventId := context.GetHeader("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = context.Query("lastEventId")
+	}
+	afterId, _ := strconv.ParseInt(lastEventId, 10, 64)
+
+	subscription, replay, complete := bus.Default.Subscribe(afterId, filter)
+	defer subscription.Close()
+
+	header := context.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	context.Status(http.StatusOK)
+
+	fmt.Fprint(context.Writer, "retry: 3000\n\n")
+	if !complete {
+		// Some missed messages are gone; the client should refetch its data
+		fmt.Fprint(context.Writer, "event: reset\ndata: {}\n\n")
+	}
+	if snapshot != nil {
+		writeSnapshot(context, *snapshot)
+	}
+	for _, msg := range replay {
+		writeMessage(context, msg)
+	}
+	context.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-context.Request.Context().Done():
+			return
+		case msg, open := <-subscription.C:
+			if !open {
+				// Dropped for falling behind; the client reconnects with its Last-Event-ID
+				return
+			}
+			writeMessage(context, msg)
+			context.Writer.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(context.Writer, ": keepalive\n\n")
+			context.Writer.Flush()
+		}
+	}
+}
+
+func writeMessage(context *gin.Context, msg bus.Message) {
+	data, err := json.Marshal(msg.Data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(context.Writer, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Topic, data)
+}
+
+// writeSnapshot sends current state without an id so it does not move the
+// client's resume position.
+func writeSnapshot(context *gin.Context, msg bus.Message) {
+	data, err := json.Marshal(msg.Data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(context.Writer, "event: %s\ndata: %s\n\n", msg.Topic, data)
+}
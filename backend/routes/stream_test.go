@@ -0,0 +1,134 @@
+package routes
+
+import (
+	"bufio"
+	"context"
+	"event-planner/bus"
+	"event-planner/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func openStream(t *testing.T, server *httptest.Server, path, lastEventId string) (*bufio.Scanner, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+path, nil)
+	if lastEventId != "" {
+		req.Header.Set("Last-Event-ID", lastEventId)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	return bufio.NewScanner(resp.Body), cancel
+}
+
+// readUntil returns the stream lines up to and including the first line containing want.
+func readUntil(t *testing.T, scanner *bufio.Scanner, want string) []string {
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if strings.Contains(scanner.Text(), want) {
+			return lines
+		}
+	}
+	t.Fatalf("Stream ended before %q, got %v", want, lines)
+	return nil
+}
+
+func setupStreamServer() *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/events/stream", streamEvents)
+	router.GET("/events/:id/stream", streamEvent)
+	return httptest.NewServer(router)
+}
+
+func TestStreamEvents_ResumesFromLastEventID(t *testing.T) {
+	server := setupStreamServer()
+	defer server.Close()
+
+	missed := bus.Default.Publish(bus.EventUpdated, 999, gin.H{"name": "Missed update"})
+	lastSeen := missed.ID - 1
+
+	scanner, cancel := openStream(t, server, "/events/stream", strconv.FormatInt(lastSeen, 10))
+	defer cancel()
+
+	lines := readUntil(t, scanner, "Missed update")
+	assert.Contains(t, lines, "id: "+strconv.FormatInt(missed.ID, 10))
+	assert.Contains(t, lines, "event: event.updated")
+
+	event := models.Event{Name: "Live Event", Description: "D", Location: "L", DateTime: time.Now().Add(time.Hour), UserID: 1}
+	assert.NoError(t, event.Save())
+
+	lines = readUntil(t, scanner, "Live Event")
+	assert.Contains(t, lines, "event: event.created")
+}
+
+func TestStreamEvent_PushesSeatAvailability(t *testing.T) {
+	server := setupStreamServer()
+	defer server.Close()
+
+	event := models.Event{Name: "Small Workshop", Description: "D", Location: "L", DateTime: time.Now().Add(72 * time.Hour), UserID: 1, Capacity: 2}
+	assert.NoError(t, event.Save())
+
+	scanner, cancel := openStream(t, server, "/events/"+strconv.FormatInt(event.ID, 10)+"/stream", "")
+	defer cancel()
+
+	// The current availability is sent straight away
+	lines := readUntil(t, scanner, `"Available":2`)
+	assert.Contains(t, lines, "event: seats.updated")
+
+	// Changes to other events are not sent on this stream
+	other := models.Event{Name: "Other", Description: "D", Location: "L", DateTime: time.Now(), UserID: 1}
+	assert.NoError(t, other.Save())
+
+	assert.NoError(t, event.Register(5, nil))
+	lines = readUntil(t, scanner, `"Available":1`)
+	for _, line := range lines {
+		assert.NotContains(t, line, "Other")
+	}
+}
+
+func TestStreamEvent_InvalidID(t *testing.T) {
+	router := gin.New()
+	router.GET("/events/:id/stream", streamEvent)
+
+	req, _ := http.NewRequest("GET", "/events/invalid/stream", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRegisterForEvent_RejectsWhenFull(t *testing.T) {
+	event := models.Event{Name: "Tiny Event", Description: "D", Location: "L", DateTime: time.Now().Add(48 * time.Hour), UserID: 1, Capacity: 1}
+	assert.NoError(t, event.Save())
+	path := "/events/" + strconv.FormatInt(event.ID, 10) + "/register"
+
+	req, _ := http.NewRequest("POST", path, nil)
+	w := httptest.NewRecorder()
+	setupRegistrationRouter(2).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	req, _ = http.NewRequest("POST", path, nil)
+	w = httptest.NewRecorder()
+	setupRegistrationRouter(2).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "already registered")
+
+	req, _ = http.NewRequest("POST", path, nil)
+	w = httptest.NewRecorder()
+	setupRegistrationRouter(3).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "Event is full")
+}
@@ -0,0 +1,241 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"event-planner/export"
+	"event-planner/models"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type surveyResponseRequest struct {
+	Answers []models.SurveyAnswer `binding:"required"`
+}
+
+// Helper function to load the survey attached to an event
+func getEventSurvey(context *gin.Context, eventId int64) (*models.Survey, bool) {
+	survey, err := models.GetSurveyForEvent(eventId)
+	if errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "This event has no survey"})
+		return nil, false
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch survey"})
+		return nil, false
+	}
+	return survey, true
+}
+
+func saveSurvey(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventOrganizer(context, event, context.GetInt64("userId"), "manage the survey") {
+		return
+	}
+
+	var survey models.Survey
+	err := context.ShouldBindJSON(&survey)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	err = survey.Validate()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	existing, err := models.GetSurveyForEvent(eventId)
+	if err == nil {
+		responses, err := existing.CountResponses()
+		if err != nil {
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not save survey"})
+			return
+		}
+
+		if responses > 0 {
+			context.JSON(http.StatusConflict, gin.H{"message": "The survey already has responses and can no longer be changed"})
+			return
+		}
+	}
+
+	survey.EventID = eventId
+	err = survey.Save()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not save survey"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Survey saved", "survey": survey})
+}
+
+func getSurvey(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	survey, ok := getEventSurvey(context, eventId)
+	if !ok {
+		return
+	}
+	context.JSON(http.StatusOK, survey)
+}
+
+func respondToSurvey(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	survey, ok := getEventSurvey(context, eventId)
+	if !ok {
+		return
+	}
+
+	if event.Ends().After(time.Now()) {
+		context.JSON(http.StatusConflict, gin.H{"message": "The survey opens when the event ends"})
+		return
+	}
+
+	userId := context.GetInt64("userId")
+	checkedIn, err := event.IsCheckedIn(userId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not check attendance"})
+		return
+	}
+
+	if !checkedIn {
+		context.JSON(http.StatusUnauthorized, gin.H{"message": "Only checked-in attendees can respond to this survey"})
+		return
+	}
+
+	var request surveyResponseRequest
+	err = context.ShouldBindJSON(&request)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	err = survey.ValidateAnswers(request.Answers)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	response := models.SurveyResponse{SurveyID: survey.ID, UserID: userId, Answers: request.Answers}
+	err = response.Save()
+	if errors.Is(err, models.ErrAlreadyResponded) {
+		context.JSON(http.StatusConflict, gin.H{"message": "You have already responded to this survey"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not save response"})
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Thanks for your feedback"})
+}
+
+// Helper function to load an organizer's survey together with its responses
+func getSurveyWithResponses(context *gin.Context) (*models.Survey, []models.SurveyResponse, bool) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return nil, nil, false
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return nil, nil, false
+	}
+
+	if !checkEventOrganizer(context, event, context.GetInt64("userId"), "view survey results") {
+		return nil, nil, false
+	}
+
+	survey, ok := getEventSurvey(context, eventId)
+	if !ok {
+		return nil, nil, false
+	}
+
+	responses, err := models.GetSurveyResponses(survey.ID)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch survey responses"})
+		return nil, nil, false
+	}
+	return survey, responses, true
+}
+
+func getSurveyResults(context *gin.Context) {
+	survey, responses, ok := getSurveyWithResponses(context)
+	if !ok {
+		return
+	}
+	context.JSON(http.StatusOK, survey.Results(responses))
+}
+
+// exportSurveyResponses writes one CSV row per response with a column per question.
+func exportSurveyResponses(context *gin.Context) {
+	survey, responses, ok := getSurveyWithResponses(context)
+	if !ok {
+		return
+	}
+
+	context.Header("Content-Type", "text/csv; charset=utf-8")
+	context.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="event-%d-survey.csv"`, survey.EventID))
+	context.Status(http.StatusOK)
+
+	table := export.NewCSV(context.Writer)
+	header := []string{"Submitted At", "Email"}
+	for _, question := range survey.Questions {
+		header = append(header, question.Prompt)
+	}
+	table.WriteRow(header)
+
+	for _, response := range responses {
+		row := []string{response.CreatedAt.UTC().Format(time.RFC3339), response.Email}
+		for i, question := range survey.Questions {
+			row = append(row, surveyAnswerCell(question, response.Answers, i))
+		}
+		table.WriteRow(row)
+	}
+	table.Close()
+}
+
+// Helper function to format one answer for the CSV export
+func surveyAnswerCell(question models.SurveyQuestion, answers []models.SurveyAnswer, index int) string {
+	if index >= len(answers) {
+		return ""
+	}
+
+	answer := answers[index]
+	if question.Kind == models.QuestionText {
+		return answer.Text
+	}
+	if answer.Value == nil {
+		return ""
+	}
+	if question.Kind == models.QuestionChoice && *answer.Value < len(question.Options) {
+		return question.Options[*answer.Value]
+	}
+	return strconv.Itoa(*answer.Value)
+}
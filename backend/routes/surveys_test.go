@@ -0,0 +1,111 @@
+package routes
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"event-planner/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSurveyRouter(userId int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userId", userId)
+	})
+	router.POST("/events/:id/registrations/:userId/check-in", checkInAttendee)
+	router.PUT("/events/:id/survey", saveSurvey)
+	router.POST("/events/:id/survey/responses", respondToSurvey)
+	router.GET("/events/:id/survey/results", getSurveyResults)
+	router.GET("/events/:id/survey/export", exportSurveyResponses)
+	return router
+}
+
+func TestSurvey_ResponsesAndResults(t *testing.T) {
+	organizerId := createTestUser(t, "survey-organizer@example.com")
+	attendeeId := createTestUser(t, "survey-attendee@example.com")
+	absentId := createTestUser(t, "survey-absent@example.com")
+
+	event := models.Event{
+		Name:        "Finished Event",
+		Description: "Test Description",
+		Location:    "Test Location",
+		DateTime:    time.Now().Add(-4 * time.Hour),
+		UserID:      organizerId,
+	}
+	err := event.Save()
+	if err != nil {
+		t.Fatalf("Failed to create test event: %v", err)
+	}
+	event.Register(attendeeId, nil)
+	event.Register(absentId, nil)
+
+	organizer := setupSurveyRouter(organizerId)
+	basePath := "/events/" + strconv.FormatInt(event.ID, 10)
+
+	w := sendAs(setupSurveyRouter(attendeeId), 0, "POST", basePath+"/registrations/"+strconv.FormatInt(attendeeId, 10)+"/check-in", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = sendAs(organizer, 0, "POST", basePath+"/registrations/"+strconv.FormatInt(attendeeId, 10)+"/check-in", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = sendAs(organizer, 0, "PUT", basePath+"/survey", gin.H{"Title": "Feedback", "Questions": []gin.H{{"Kind": "stars", "Prompt": "?"}}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	survey := gin.H{"Title": "Feedback", "Questions": []gin.H{
+		{"Kind": "rating", "Prompt": "Overall", "Required": true},
+		{"Kind": "nps", "Prompt": "Recommend?"},
+		{"Kind": "choice", "Prompt": "Best part", "Options": []string{"Talks", "Food"}},
+		{"Kind": "text", "Prompt": "Comments"},
+	}}
+	w = sendAs(organizer, 0, "PUT", basePath+"/survey", survey)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	answers := gin.H{"Answers": []gin.H{{"Value": 4}, {"Value": 10}, {"Value": 1}, {"Text": "=HYPERLINK(\"http://x\")"}}}
+
+	// Only checked-in attendees can respond, once
+	w = sendAs(setupSurveyRouter(absentId), 0, "POST", basePath+"/survey/responses", answers)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	attendee := setupSurveyRouter(attendeeId)
+	w = sendAs(attendee, 0, "POST", basePath+"/survey/responses", gin.H{"Answers": []gin.H{{"Value": 9}, {}, {}, {}}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = sendAs(attendee, 0, "POST", basePath+"/survey/responses", answers)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = sendAs(attendee, 0, "POST", basePath+"/survey/responses", answers)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// The questions are locked once responses exist
+	w = sendAs(organizer, 0, "PUT", basePath+"/survey", survey)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	req, _ := http.NewRequest("GET", basePath+"/survey/results", nil)
+	w = httptest.NewRecorder()
+	organizer.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var results models.SurveyResults
+	json.Unmarshal(w.Body.Bytes(), &results)
+	assert.Equal(t, 1, results.Responses)
+	assert.Equal(t, 4.0, *results.Questions[0].Average)
+	assert.Equal(t, 100.0, *results.Questions[1].NPS)
+	assert.Equal(t, []int{0, 1}, results.Questions[2].OptionCounts)
+	assert.Equal(t, []string{`=HYPERLINK("http://x")`}, results.Questions[3].Texts)
+
+	req, _ = http.NewRequest("GET", basePath+"/survey/export", nil)
+	w = httptest.NewRecorder()
+	organizer.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Submitted At", "Email", "Overall", "Recommend?", "Best part", "Comments"}, rows[0])
+	// Answers are escaped so spreadsheets do not run them as formulas
+	assert.Equal(t, []string{"survey-attendee@example.com", "4", "10", "Food", `'=HYPERLINK("http://x")`}, rows[1][1:])
+}
@@ -0,0 +1,289 @@
+package routes
+
+import (
+	"errors"
+	"event-planner/models"
+	"event-planner/notifications"
+	"event-planner/webhooks"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type transferRequest struct {
+	Email string `binding:"required"`
+}
+
+// Helper function to reply to transfer errors, reporting whether there was one
+func handleTransferError(context *gin.Context, err error) bool {
+	var answerErr *models.AnswerError
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &answerErr):
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid registration answers", "field": answerErr.Field, "error": answerErr.Message})
+	case errors.Is(err, models.ErrTransfersClosed), errors.Is(err, models.ErrTransferPending),
+		errors.Is(err, models.ErrTransferUnavailable), errors.Is(err, models.ErrTransferExpired):
+		context.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+	default:
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update ticket transfer"})
+	}
+	return true
+}
+
+// Helper function to load the transfer in the URL
+func getTransfer(context *gin.Context) (*models.Transfer, bool) {
+	transferId, err := strconv.ParseInt(context.Param("transferId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse transfer id"})
+		return nil, false
+	}
+
+	transfer, err := models.GetTransferByID(transferId)
+	if err != nil {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Transfer not found"})
+		return nil, false
+	}
+	return transfer, true
+}
+
+func getTransferRules(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	rules, err := models.GetTransferRules(eventId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch transfer rules"})
+		return
+	}
+	context.JSON(http.StatusOK, rules)
+}
+
+func updateTransferRules(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	if !checkEventOrganizer(context, event, context.GetInt64("userId"), "change transfer rules") {
+		return
+	}
+
+	var rules models.TransferRules
+	err := context.ShouldBindJSON(&rules)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	err = rules.Validate()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	rules.EventID = eventId
+	err = rules.Save()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not save transfer rules"})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Transfer rules saved", "rules": rules})
+}
+
+// offerTransfer offers the user's ticket to the account with the given email.
+func offerTransfer(context *gin.Context) {
+	eventId, ok := parseEventID(context)
+	if !ok {
+		return
+	}
+
+	var request transferRequest
+	err := context.ShouldBindJSON(&request)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Please give the recipient's email"})
+		return
+	}
+
+	userId := context.GetInt64("userId")
+	event, ok := getEventByID(context, eventId)
+	if !ok {
+		return
+	}
+
+	registration, err := models.GetRegistration(eventId, userId)
+	if err != nil || registration.Status != models.RegistrationConfirmed {
+		context.JSON(http.StatusNotFound, gin.H{"message": "You have no ticket for this event"})
+		return
+	}
+
+	if registration.CheckedInAt != nil {
+		context.JSON(http.StatusConflict, gin.H{"message": "Tickets cannot be transferred after check-in"})
+		return
+	}
+
+	rules, err := models.GetTransferRules(eventId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch transfer rules"})
+		return
+	}
+
+	now := time.Now()
+	if !rules.Open(event.DateTime, now) {
+		handleTransferError(context, models.ErrTransfersClosed)
+		return
+	}
+
+	recipient, err := models.GetUserByEmail(strings.TrimSpace(request.Email))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "No account found for " + request.Email})
+		return
+	}
+
+	if recipient.ID == userId {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "You already hold this ticket"})
+		return
+	}
+
+	_, err = models.GetRegistration(eventId, recipient.ID)
+	if err == nil {
+		context.JSON(http.StatusConflict, gin.H{"message": recipient.Email + " is already registered for this event"})
+		return
+	}
+
+	// The offer cannot outlive the window in which transfers are allowed
+	expiresAt := now.Add(time.Duration(rules.OfferExpiryHours) * time.Hour)
+	cutoff := event.DateTime.Add(-time.Duration(rules.CutoffHours) * time.Hour)
+	if expiresAt.After(cutoff) {
+		expiresAt = cutoff
+	}
+
+	transfer := models.Transfer{
+		EventID:    eventId,
+		FromUserID: userId,
+		ToUserID:   recipient.ID,
+		ToEmail:    recipient.Email,
+		FromEmail:  registration.Email,
+		ExpiresAt:  expiresAt,
+	}
+	err = transfer.Save()
+	if handleTransferError(context, err) {
+		return
+	}
+
+	err = notifications.TransferOffered(notifications.Default, *event, transfer)
+	if err != nil {
+		log.Printf("could not notify user %d of transfer %d: %v", recipient.ID, transfer.ID, err)
+	}
+
+	context.JSON(http.StatusCreated, gin.H{"message": "Transfer offered", "transfer": transfer})
+}
+
+func getMyTransfers(context *gin.Context) {
+	transfers, err := models.GetPendingTransfersForUser(context.GetInt64("userId"), time.Now())
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch transfers"})
+		return
+	}
+	context.JSON(http.StatusOK, transfers)
+}
+
+func acceptTransfer(context *gin.Context) {
+	transfer, ok := getTransfer(context)
+	if !ok {
+		return
+	}
+
+	userId := context.GetInt64("userId")
+	if transfer.ToUserID != userId {
+		context.JSON(http.StatusUnauthorized, gin.H{"message": "This transfer was not offered to you"})
+		return
+	}
+
+	var request registrationRequest
+	if context.Request.ContentLength != 0 {
+		err := context.ShouldBindJSON(&request)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+			return
+		}
+	}
+
+	event, ok := getEventByID(context, transfer.EventID)
+	if !ok {
+		return
+	}
+
+	rules, err := models.GetTransferRules(event.ID)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch transfer rules"})
+		return
+	}
+
+	now := time.Now()
+	if !rules.Open(event.DateTime, now) {
+		handleTransferError(context, models.ErrTransfersClosed)
+		return
+	}
+
+	err = transfer.Accept(*event, request.Answers, now)
+	if handleTransferError(context, err) {
+		return
+	}
+
+	notifyRegistered(*event, userId)
+	publishWebhook(event.OrganizationID, webhooks.RegistrationCancelled, gin.H{"eventId": event.ID, "userId": transfer.FromUserID})
+	publishWebhook(event.OrganizationID, webhooks.RegistrationCreated, gin.H{"eventId": event.ID, "userId": userId})
+
+	context.JSON(http.StatusOK, gin.H{"message": "Ticket transferred to you"})
+}
+
+func declineTransfer(context *gin.Context) {
+	transfer, ok := getTransfer(context)
+	if !ok {
+		return
+	}
+
+	if transfer.ToUserID != context.GetInt64("userId") {
+		context.JSON(http.StatusUnauthorized, gin.H{"message": "This transfer was not offered to you"})
+		return
+	}
+
+	err := transfer.Resolve(models.TransferDeclined, time.Now())
+	if handleTransferError(context, err) {
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Transfer declined"})
+}
+
+func cancelTransfer(context *gin.Context) {
+	transfer, ok := getTransfer(context)
+	if !ok {
+		return
+	}
+
+	if transfer.FromUserID != context.GetInt64("userId") {
+		context.JSON(http.StatusUnauthorized, gin.H{"message": "You are not authorized to cancel this transfer"})
+		return
+	}
+
+	err := transfer.Resolve(models.TransferCancelled, time.Now())
+	if handleTransferError(context, err) {
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Transfer cancelled"})
+}
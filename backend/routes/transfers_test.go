@@ -0,0 +1,152 @@
+package routes
+
+import (
+	"encoding/json"
+	"event-planner/models"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTransferRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		userId, _ := strconv.ParseInt(c.GetHeader("X-User"), 10, 64)
+		c.Set("userId", userId)
+	})
+	router.POST("/events/:id/register", registerForEvent)
+	router.GET("/events/:id/registration", getMyRegistration)
+	router.PUT("/events/:id/transfer-rules", updateTransferRules)
+	router.POST("/events/:id/transfers", offerTransfer)
+	router.GET("/me/transfers", getMyTransfers)
+	router.POST("/transfers/:transferId/accept", acceptTransfer)
+	router.POST("/transfers/:transferId/decline", declineTransfer)
+	router.DELETE("/transfers/:transferId", cancelTransfer)
+	return router
+}
+
+func TestTransfers_OfferAndAccept(t *testing.T) {
+	organizerId := createTestUser(t, "transfer-organizer@example.com")
+	holderId := createTestUser(t, "transfer-holder@example.com")
+	recipientId := createTestUser(t, "transfer-recipient@example.com")
+	event := createTestEvent(t, organizerId, time.Now().Add(72*time.Hour))
+	basePath := "/events/" + strconv.FormatInt(event.ID, 10)
+	router := setupTransferRouter()
+
+	assert.Equal(t, http.StatusCreated, sendAs(router, holderId, "POST", basePath+"/register", nil).Code)
+	original, err := models.GetRegistration(event.ID, holderId)
+	if err != nil {
+		t.Fatalf("Failed to fetch registration: %v", err)
+	}
+
+	w := sendAs(router, holderId, "POST", basePath+"/transfers", gin.H{"Email": "nobody@example.com"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendAs(router, holderId, "POST", basePath+"/transfers", gin.H{"Email": "transfer-recipient@example.com"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Transfer models.Transfer `json:"transfer"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	transferPath := "/transfers/" + strconv.FormatInt(response.Transfer.ID, 10)
+
+	// Only one offer can be pending per ticket
+	w = sendAs(router, holderId, "POST", basePath+"/transfers", gin.H{"Email": "transfer-organizer@example.com"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var pending []models.Transfer
+	w = sendAs(router, recipientId, "GET", "/me/transfers", nil)
+	json.Unmarshal(w.Body.Bytes(), &pending)
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, "transfer-holder@example.com", pending[0].FromEmail)
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, sendAs(router, organizerId, "POST", transferPath+"/accept", nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, recipientId, "POST", transferPath+"/accept", nil).Code)
+	assert.Equal(t, http.StatusConflict, sendAs(router, recipientId, "POST", transferPath+"/accept", nil).Code)
+
+	registered, _ := event.IsRegistered(holderId)
+	assert.False(t, registered)
+
+	transferred, err := models.GetRegistration(event.ID, recipientId)
+	if assert.NoError(t, err) {
+		assert.Equal(t, original.ID, transferred.ID)
+		assert.Len(t, transferred.TicketToken, 32)
+		assert.NotEqual(t, original.TicketToken, transferred.TicketToken)
+	}
+
+	seats, _ := event.Seats()
+	assert.Equal(t, 1, seats.Registered)
+	// The holder's reminders move to the recipient
+	assert.Equal(t, len(models.ReminderOffsets), countReminders(t, event.ID))
+}
+
+func TestTransfers_RespectRules(t *testing.T) {
+	organizerId := createTestUser(t, "rules-organizer@example.com")
+	holderId := createTestUser(t, "rules-holder@example.com")
+	createTestUser(t, "rules-recipient@example.com")
+	event := createTestEvent(t, organizerId, time.Now().Add(72*time.Hour))
+	basePath := "/events/" + strconv.FormatInt(event.ID, 10)
+	router := setupTransferRouter()
+
+	assert.Equal(t, http.StatusCreated, sendAs(router, holderId, "POST", basePath+"/register", nil).Code)
+
+	rules := gin.H{"Allowed": false, "CutoffHours": 0, "OfferExpiryHours": 24}
+	assert.Equal(t, http.StatusUnauthorized, sendAs(router, holderId, "PUT", basePath+"/transfer-rules", rules).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, organizerId, "PUT", basePath+"/transfer-rules", rules).Code)
+
+	w := sendAs(router, holderId, "POST", basePath+"/transfers", gin.H{"Email": "rules-recipient@example.com"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Transfers close four days before an event starting in three
+	rules = gin.H{"Allowed": true, "CutoffHours": 96, "OfferExpiryHours": 24}
+	assert.Equal(t, http.StatusOK, sendAs(router, organizerId, "PUT", basePath+"/transfer-rules", rules).Code)
+
+	w = sendAs(router, holderId, "POST", basePath+"/transfers", gin.H{"Email": "rules-recipient@example.com"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	rules = gin.H{"Allowed": true, "CutoffHours": 0, "OfferExpiryHours": 0}
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, organizerId, "PUT", basePath+"/transfer-rules", rules).Code)
+}
+
+func TestTransfers_ExpiredAndCancelled(t *testing.T) {
+	holderId := createTestUser(t, "expiry-holder@example.com")
+	recipientId := createTestUser(t, "expiry-recipient@example.com")
+	event := createTestEvent(t, 1, time.Now().Add(72*time.Hour))
+	basePath := "/events/" + strconv.FormatInt(event.ID, 10)
+	router := setupTransferRouter()
+
+	assert.Equal(t, http.StatusCreated, sendAs(router, holderId, "POST", basePath+"/register", nil).Code)
+
+	expired := models.Transfer{EventID: event.ID, FromUserID: holderId, ToUserID: recipientId, ExpiresAt: time.Now().Add(-time.Minute)}
+	err := expired.Save()
+	if err != nil {
+		t.Fatalf("Failed to create transfer: %v", err)
+	}
+
+	w := sendAs(router, recipientId, "POST", "/transfers/"+strconv.FormatInt(expired.ID, 10)+"/accept", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// An expired offer does not block a new one
+	w = sendAs(router, holderId, "POST", basePath+"/transfers", gin.H{"Email": "expiry-recipient@example.com"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Transfer models.Transfer `json:"transfer"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	transferPath := "/transfers/" + strconv.FormatInt(response.Transfer.ID, 10)
+
+	assert.Equal(t, http.StatusUnauthorized, sendAs(router, recipientId, "DELETE", transferPath, nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, holderId, "DELETE", transferPath, nil).Code)
+	assert.Equal(t, http.StatusConflict, sendAs(router, recipientId, "POST", transferPath+"/accept", nil).Code)
+
+	registered, _ := event.IsRegistered(holderId)
+	assert.True(t, registered)
+}
@@ -0,0 +1,113 @@
+package routes
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"event-planner/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrendingWindow is how far back GET /events/trending looks by default. main
+// sets it from TRENDING_WINDOW.
+var TrendingWindow = 7 * 24 * time.Hour
+
+const (
+	maxTrendingWindow = 30 * 24 * time.Hour
+	maxTrendingLimit  = 50
+	sessionCookie     = "sid"
+)
+
+// ConfigureProxies makes the server read client addresses from
+// X-Forwarded-For and X-Real-IP only on requests from the comma-separated
+// proxy addresses or CIDRs. With none, the connection's address is used, so
+// clients cannot choose their own and slip past the per-address view cap.
+func ConfigureProxies(server *gin.Engine, proxies string) error {
+	var trusted []string
+	for _, proxy := range strings.Split(proxies, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy != "" {
+			trusted = append(trusted, proxy)
+		}
+	}
+	return server.SetTrustedProxies(trusted)
+}
+
+// Helper function to identify who is viewing a page: the logged-in user, or
+// else the session from the sid cookie, which is issued on first visit.
+// Anonymous viewers also get a source key from their address, so their views
+// can be capped. Keys are hashed so raw session ids and addresses are not
+// stored.
+func viewerKey(context *gin.Context) (viewer, source string) {
+	if userId := optionalUserID(context); userId != 0 {
+		return hashViewerKey("user:" + strconv.FormatInt(userId, 10)), ""
+	}
+
+	session, err := context.Cookie(sessionCookie)
+	if err != nil || session == "" {
+		buf := make([]byte, 16)
+		_, err := rand.Read(buf)
+		if err != nil {
+			return "", ""
+		}
+		session = hex.EncodeToString(buf)
+		context.SetCookie(sessionCookie, session, 365*24*60*60, "/", "", false, true)
+	}
+	return hashViewerKey("session:" + session), hashViewerKey("ip:" + context.ClientIP())
+}
+
+func hashViewerKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Helper function to count a view of the event, ignoring its organizer
+func recordEventView(context *gin.Context, event *models.Event) {
+	if optionalUserID(context) == event.UserID {
+		return
+	}
+
+	viewer, source := viewerKey(context)
+	if viewer == "" {
+		return
+	}
+
+	_, err := models.RecordView(event.ID, viewer, source, time.Now())
+	if err != nil {
+		log.Printf("could not record view of event %d: %v", event.ID, err)
+	}
+}
+
+// getTrendingEvents ranks upcoming events by recent registrations, bookmarks
+// and views. ?window= (such as 72h) overrides TrendingWindow; activity loses
+// half its weight every quarter of the window.
+func getTrendingEvents(context *gin.Context) {
+	window := TrendingWindow
+	if value := context.Query("window"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < time.Hour || parsed > maxTrendingWindow {
+			context.JSON(http.StatusBadRequest, gin.H{"message": "Window must be a duration between 1h and 720h"})
+			return
+		}
+		window = parsed
+	}
+
+	limit, err := strconv.Atoi(context.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > maxTrendingLimit {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Limit must be between 1 and " + strconv.Itoa(maxTrendingLimit)})
+		return
+	}
+
+	trending, err := models.GetTrendingEvents(window, window/4, limit, time.Now())
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch trending events"})
+		return
+	}
+	context.JSON(http.StatusOK, trending)
+}
@@ -0,0 +1,172 @@
+package routes
+
+import (
+	"encoding/json"
+	"event-planner/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTrendingRouter() *gin.Engine {
+	router := setupTransferRouter()
+	router.GET("/events/:id", GetEvent)
+	router.GET("/events/trending", getTrendingEvents)
+	router.POST("/events/:id/bookmark", bookmarkEvent)
+	router.DELETE("/events/:id/bookmark", removeBookmark)
+	router.GET("/me/bookmarks", getMyBookmarks)
+	return router
+}
+
+func viewEvent(router *gin.Engine, eventId int64, session string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/events/"+strconv.FormatInt(eventId, 10), nil)
+	if session != "" {
+		req.AddCookie(&http.Cookie{Name: sessionCookie, Value: session})
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func findTrending(trending []models.TrendingEvent, eventId int64) (int, *models.TrendingEvent) {
+	for i := range trending {
+		if trending[i].Event.ID == eventId {
+			return i, &trending[i]
+		}
+	}
+	return -1, nil
+}
+
+func TestTrending_ScoresRecentActivity(t *testing.T) {
+	router := setupTrendingRouter()
+	organizerId := createTestUser(t, "trending-organizer@example.com")
+	fanId := createTestUser(t, "trending-fan@example.com")
+	popular := createTestEvent(t, organizerId, time.Now().Add(72*time.Hour))
+	viewed := createTestEvent(t, organizerId, time.Now().Add(72*time.Hour))
+	stale := createTestEvent(t, organizerId, time.Now().Add(72*time.Hour))
+
+	assert.NoError(t, popular.Register(fanId, nil))
+	assert.Equal(t, http.StatusOK, sendAs(router, fanId, "POST", "/events/"+strconv.FormatInt(popular.ID, 10)+"/bookmark", nil).Code)
+
+	// Repeat views by one session count once
+	assert.Equal(t, http.StatusOK, viewEvent(router, viewed.ID, "session-a").Code)
+	viewEvent(router, viewed.ID, "session-a")
+	viewEvent(router, viewed.ID, "session-b")
+
+	// Anonymous visitors without a session are given one
+	w := viewEvent(router, viewed.ID, "")
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "sid=")
+
+	counted, err := models.RecordView(stale.ID, "old-viewer", "", time.Now().Add(-5*24*time.Hour))
+	assert.NoError(t, err)
+	assert.True(t, counted)
+
+	now := time.Now()
+	trending, err := models.GetTrendingEvents(7*24*time.Hour, 42*time.Hour, 1000, now)
+	assert.NoError(t, err)
+
+	popularRank, popularScore := findTrending(trending, popular.ID)
+	viewedRank, viewedScore := findTrending(trending, viewed.ID)
+	_, staleScore := findTrending(trending, stale.ID)
+	if assert.NotNil(t, popularScore) && assert.NotNil(t, viewedScore) && assert.NotNil(t, staleScore) {
+		assert.Equal(t, 1, popularScore.Registrations)
+		assert.Equal(t, 1, popularScore.Bookmarks)
+		assert.Equal(t, 3, viewedScore.Views)
+		assert.Less(t, popularRank, viewedRank)
+		// Five days is a bit under three half-lives
+		assert.InDelta(t, 0.138, staleScore.Score, 0.01)
+	}
+
+	// A shorter window leaves the old view out
+	trending, err = models.GetTrendingEvents(72*time.Hour, 18*time.Hour, 1000, now)
+	assert.NoError(t, err)
+	_, staleScore = findTrending(trending, stale.ID)
+	assert.Nil(t, staleScore)
+
+	w = sendAs(router, 0, "GET", "/events/trending?window=72h&limit=5", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &trending)
+	assert.LessOrEqual(t, len(trending), 5)
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, 0, "GET", "/events/trending?window=forever", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, sendAs(router, 0, "GET", "/events/trending?window=2000h", nil).Code)
+}
+
+func TestBookmarks(t *testing.T) {
+	router := setupTrendingRouter()
+	organizerId := createTestUser(t, "bookmark-organizer@example.com")
+	userId := createTestUser(t, "bookmark-user@example.com")
+	event := createTestEvent(t, organizerId, time.Now().Add(72*time.Hour))
+	path := "/events/" + strconv.FormatInt(event.ID, 10) + "/bookmark"
+
+	assert.Equal(t, http.StatusOK, sendAs(router, userId, "POST", path, nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(router, userId, "POST", path, nil).Code)
+
+	var bookmarks []models.Event
+	w := sendAs(router, userId, "GET", "/me/bookmarks", nil)
+	json.Unmarshal(w.Body.Bytes(), &bookmarks)
+	if assert.Len(t, bookmarks, 1) {
+		assert.Equal(t, event.ID, bookmarks[0].ID)
+	}
+
+	assert.Equal(t, http.StatusOK, sendAs(router, userId, "DELETE", path, nil).Code)
+	w = sendAs(router, userId, "GET", "/me/bookmarks", nil)
+	json.Unmarshal(w.Body.Bytes(), &bookmarks)
+	assert.Empty(t, bookmarks)
+}
+
+func TestTrending_CapsAnonymousViews(t *testing.T) {
+	router := setupTrendingRouter()
+	organizerId := createTestUser(t, "capped-organizer@example.com")
+	event := createTestEvent(t, organizerId, time.Now().Add(72*time.Hour))
+
+	// A client choosing its own session header is still one fresh visitor per
+	// request, so only the per-address cap holds it back
+	for i := range models.MaxViewsPerSource + 5 {
+		req := httptest.NewRequest("GET", "/events/"+strconv.FormatInt(event.ID, 10), nil)
+		req.Header.Set("X-Session-ID", "bot-"+strconv.Itoa(i))
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	trending, err := models.GetTrendingEvents(7*24*time.Hour, 42*time.Hour, 1000, time.Now())
+	assert.NoError(t, err)
+	_, score := findTrending(trending, event.ID)
+	if assert.NotNil(t, score) {
+		assert.Equal(t, models.MaxViewsPerSource, score.Views)
+	}
+}
+
+func TestTrending_IgnoresSpoofedForwardedFor(t *testing.T) {
+	for _, test := range []struct {
+		proxies string
+		views   int
+	}{
+		{"", models.MaxViewsPerSource},
+		{"10.0.0.1", models.MaxViewsPerSource},
+		// httptest requests come from 192.0.2.1
+		{"192.0.2.0/24", models.MaxViewsPerSource + 5},
+	} {
+		router := setupTrendingRouter()
+		assert.NoError(t, ConfigureProxies(router, test.proxies))
+		organizerId := createTestUser(t, "spoofed-organizer-"+test.proxies+"@example.com")
+		event := createTestEvent(t, organizerId, time.Now().Add(72*time.Hour))
+
+		for i := range models.MaxViewsPerSource + 5 {
+			req := httptest.NewRequest("GET", "/events/"+strconv.FormatInt(event.ID, 10), nil)
+			req.Header.Set("X-Forwarded-For", "203.0.113."+strconv.Itoa(i+1))
+			req.Header.Set("X-Real-IP", "203.0.113."+strconv.Itoa(i+1))
+			router.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		trending, err := models.GetTrendingEvents(7*24*time.Hour, 42*time.Hour, 1000, time.Now())
+		assert.NoError(t, err)
+		_, score := findTrending(trending, event.ID)
+		if assert.NotNil(t, score, test.proxies) {
+			assert.Equal(t, test.views, score.Views, test.proxies)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package routes
+
+import (
+	"event-planner/models"
+	"event-planner/webhooks"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const deliveryHistoryLimit = 50
+
+// Helper function to queue a webhook without failing the request that caused it
+func publishWebhook(organizationId *int64, eventType string, data any) {
+	err := webhooks.Publish(organizationId, eventType, data)
+	if err != nil {
+		log.Printf("could not queue %s webhook: %v", eventType, err)
+	}
+}
+
+// Helper function to load a webhook endpoint that belongs to the organization
+func getOrganizationWebhook(context *gin.Context, organization *models.Organization) (*models.WebhookEndpoint, bool) {
+	webhookId, err := strconv.ParseInt(context.Param("webhookId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse webhook id"})
+		return nil, false
+	}
+
+	endpoint, err := models.GetWebhookEndpointByID(webhookId)
+	if err != nil || endpoint.OrganizationID != organization.ID {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Webhook not found"})
+		return nil, false
+	}
+	return endpoint, true
+}
+
+func getWebhooks(context *gin.Context) {
+	organization, ok := getOwnedOrganization(context)
+	if !ok {
+		return
+	}
+
+	endpoints, err := models.GetWebhookEndpointsForOrganization(organization.ID)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch webhooks"})
+		return
+	}
+	context.JSON(http.StatusOK, endpoints)
+}
+
+func createWebhook(context *gin.Context) {
+	organization, ok := getOwnedOrganization(context)
+	if !ok {
+		return
+	}
+
+	var endpoint models.WebhookEndpoint
+	err := context.ShouldBindJSON(&endpoint)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse data"})
+		return
+	}
+
+	err = webhooks.ValidateEndpoint(endpoint)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	endpoint.OrganizationID = organization.ID
+	endpoint.Secret, err = webhooks.GenerateSecret()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create webhook"})
+		return
+	}
+
+	err = endpoint.Save()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create webhook"})
+		return
+	}
+
+	// The secret is only ever shown here
+	context.JSON(http.StatusCreated, gin.H{"message": "Webhook created successfully", "webhook": endpoint, "secret": endpoint.Secret})
+}
+
+func deleteWebhook(context *gin.Context) {
+	organization, ok := getOwnedOrganization(context)
+	if !ok {
+		return
+	}
+
+	endpoint, ok := getOrganizationWebhook(context, organization)
+	if !ok {
+		return
+	}
+
+	err := endpoint.Delete()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete webhook"})
+		return
+	}
+	context.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+func getWebhookDeliveries(context *gin.Context) {
+	organization, ok := getOwnedOrganization(context)
+	if !ok {
+		return
+	}
+
+	endpoint, ok := getOrganizationWebhook(context, organization)
+	if !ok {
+		return
+	}
+
+	deliveries, err := models.GetWebhookDeliveries(endpoint.ID, deliveryHistoryLimit)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch webhook deliveries"})
+		return
+	}
+
+	type deliveryWithAttempts struct {
+		models.WebhookDelivery
+		AttemptLog []models.WebhookAttempt
+	}
+
+	history := []deliveryWithAttempts{}
+	for _, delivery := range deliveries {
+		attempts, err := models.GetWebhookAttempts(delivery.ID)
+		if err != nil {
+			context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch webhook deliveries"})
+			return
+		}
+		history = append(history, deliveryWithAttempts{delivery, attempts})
+	}
+	context.JSON(http.StatusOK, history)
+}
+
+func redeliverWebhook(context *gin.Context) {
+	organization, ok := getOwnedOrganization(context)
+	if !ok {
+		return
+	}
+
+	endpoint, ok := getOrganizationWebhook(context, organization)
+	if !ok {
+		return
+	}
+
+	deliveryId, err := strconv.ParseInt(context.Param("deliveryId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse delivery id"})
+		return
+	}
+
+	original, err := models.GetWebhookDeliveryByID(deliveryId)
+	if err != nil || original.EndpointID != endpoint.ID {
+		context.JSON(http.StatusNotFound, gin.H{"message": "Delivery not found"})
+		return
+	}
+
+	delivery, err := webhooks.Redeliver(*original)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"message": "Could not redeliver webhook"})
+		return
+	}
+	context.JSON(http.StatusAccepted, gin.H{"message": "Webhook queued for redelivery", "delivery": delivery})
+}
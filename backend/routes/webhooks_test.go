@@ -0,0 +1,130 @@
+package routes
+
+import (
+	"encoding/json"
+	"event-planner/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupWebhookRouter(userId int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userId", userId)
+	})
+	router.POST("/events", CreateEvent)
+	router.POST("/organizations", createOrganization)
+	router.GET("/organizations/:id/webhooks", getWebhooks)
+	router.POST("/organizations/:id/webhooks", createWebhook)
+	router.DELETE("/organizations/:id/webhooks/:webhookId", deleteWebhook)
+	router.GET("/organizations/:id/webhooks/:webhookId/deliveries", getWebhookDeliveries)
+	router.POST("/organizations/:id/webhooks/:webhookId/deliveries/:deliveryId/redeliver", redeliverWebhook)
+	return router
+}
+
+func createTestOrganization(t *testing.T, ownerId int64) models.Organization {
+	organization := models.Organization{Name: "Chess Club", OwnerID: ownerId}
+	err := organization.Save()
+	if err != nil {
+		t.Fatalf("Failed to create organization: %v", err)
+	}
+	return organization
+}
+
+func TestCreateOrganization(t *testing.T) {
+	w := sendAs(setupWebhookRouter(70), 0, "POST", "/organizations", gin.H{"name": "Robotics Society"})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Organization models.Organization `json:"organization"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, int64(70), response.Organization.OwnerID)
+}
+
+func TestWebhooks_CreateListAndQueueDeliveries(t *testing.T) {
+	organization := createTestOrganization(t, 71)
+	router := setupWebhookRouter(71)
+	basePath := "/organizations/" + strconv.FormatInt(organization.ID, 10) + "/webhooks"
+
+	w := sendAs(router, 0, "POST", basePath, gin.H{"url": "https://bot.example/hook", "eventTypes": []string{"event.created"}})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var created struct {
+		Webhook models.WebhookEndpoint `json:"webhook"`
+		Secret  string                 `json:"secret"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	assert.NotEmpty(t, created.Secret)
+
+	// The secret is never listed again
+	req, _ := http.NewRequest("GET", basePath, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), created.Secret)
+
+	w = sendAs(router, 0, "POST", "/events", gin.H{
+		"name":           "Blitz Tournament",
+		"description":    "Fast games",
+		"location":       "Library",
+		"dateTime":       time.Now().Add(24 * time.Hour),
+		"organizationId": organization.ID,
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	deliveriesPath := basePath + "/" + strconv.FormatInt(created.Webhook.ID, 10) + "/deliveries"
+	req, _ = http.NewRequest("GET", deliveriesPath, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var deliveries []models.WebhookDelivery
+	json.Unmarshal(w.Body.Bytes(), &deliveries)
+	assert.Len(t, deliveries, 1)
+	assert.Equal(t, "event.created", deliveries[0].EventType)
+	assert.Contains(t, deliveries[0].Payload, "Blitz Tournament")
+
+	w = sendAs(router, 0, "POST", deliveriesPath+"/"+strconv.FormatInt(deliveries[0].ID, 10)+"/redeliver", nil)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	req, _ = http.NewRequest("DELETE", basePath+"/"+strconv.FormatInt(created.Webhook.ID, 10), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestWebhooks_RejectsInvalidEndpoint(t *testing.T) {
+	organization := createTestOrganization(t, 72)
+	path := "/organizations/" + strconv.FormatInt(organization.ID, 10) + "/webhooks"
+
+	w := sendAs(setupWebhookRouter(72), 0, "POST", path, gin.H{"url": "not a url", "eventTypes": []string{"event.created"}})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWebhooks_OnlyOwnerCanManage(t *testing.T) {
+	organization := createTestOrganization(t, 73)
+	path := "/organizations/" + strconv.FormatInt(organization.ID, 10) + "/webhooks"
+
+	w := sendAs(setupWebhookRouter(74), 0, "POST", path, gin.H{"url": "https://bot.example/hook", "eventTypes": []string{"event.created"}})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Nor can they create events on the organization's behalf
+	w = sendAs(setupWebhookRouter(74), 0, "POST", "/events", gin.H{
+		"name":           "Fake Event",
+		"description":    "Not ours",
+		"location":       "Nowhere",
+		"dateTime":       time.Now(),
+		"organizationId": organization.ID,
+	})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a unit of background work. It receives the time the run started.
+type Job func(now time.Time) error
+
+type entry struct {
+	name     string
+	interval time.Duration
+	job      Job
+}
+
+// Scheduler runs registered jobs on fixed intervals until stopped. Jobs keep
+// their state in the database, so a restart simply resumes where it left off.
+type Scheduler struct {
+	entries []entry
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func New() *Scheduler {
+	return &Scheduler{stop: make(chan struct{})}
+}
+
+// Every registers job to run once at start and then every interval.
+func (s *Scheduler) Every(name string, interval time.Duration, job Job) {
+	s.entries = append(s.entries, entry{name: name, interval: interval, job: job})
+}
+
+func (s *Scheduler) Start() {
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go s.loop(e)
+	}
+}
+
+// Stop signals every job to exit and waits for runs in progress to finish.
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(e entry) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	run(e, time.Now())
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			run(e, now)
+		}
+	}
+}
+
+func run(e entry, now time.Time) {
+	err := e.job(now)
+	if err != nil {
+		log.Printf("scheduler: job %s failed: %v", e.name, err)
+	}
+}
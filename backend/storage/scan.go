@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrInfected is returned, possibly wrapped with the scanner's verdict, for
+// files that must not be stored.
+var ErrInfected = errors.New("file failed the virus scan")
+
+// Scanner checks an upload before it is stored. Implementations wrap an
+// antivirus engine such as clamd.
+type Scanner interface {
+	Scan(ctx context.Context, name string, body io.Reader) error
+}
+
+// NopScanner accepts every file. It is used when no scanner is configured.
+type NopScanner struct{}
+
+func (NopScanner) Scan(ctx context.Context, name string, body io.Reader) error {
+	return nil
+}
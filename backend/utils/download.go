@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Download links are signed with their own key, like unsubscribe links.
+var downloadKey = []byte(secretKey + ":download")
+
+var (
+	ErrInvalidDownload = errors.New("Invalid download link")
+	ErrDownloadExpired = errors.New("This download link has expired")
+)
+
+// SignDownload returns the signature that lets anyone holding the link fetch
+// the attachment until expires.
+func SignDownload(attachmentID int64, expires time.Time) string {
+	mac := hmac.New(sha256.New, downloadKey)
+	mac.Write([]byte(strconv.FormatInt(attachmentID, 10) + "|" + strconv.FormatInt(expires.Unix(), 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyDownload checks a signature made by SignDownload and that the link has
+// not expired.
+func VerifyDownload(attachmentID int64, expires time.Time, signature string, now time.Time) error {
+	if !hmac.Equal([]byte(signature), []byte(SignDownload(attachmentID, expires))) {
+		return ErrInvalidDownload
+	}
+	if !now.Before(expires) {
+		return ErrDownloadExpired
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadSignature(t *testing.T) {
+	now := time.Now()
+	expires := now.Add(15 * time.Minute)
+	signature := SignDownload(7, expires)
+
+	assert.NoError(t, VerifyDownload(7, expires, signature, now))
+	assert.ErrorIs(t, VerifyDownload(8, expires, signature, now), ErrInvalidDownload)
+	assert.ErrorIs(t, VerifyDownload(7, expires.Add(time.Hour), signature, now), ErrInvalidDownload)
+	assert.ErrorIs(t, VerifyDownload(7, expires, signature, expires), ErrDownloadExpired)
+}
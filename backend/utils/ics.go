@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const icsTimeFormat = "20060102T150405Z"
+
+// ICSEvent describes a single VEVENT for an iCalendar file.
+type ICSEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	Sequence    int64
+	Cancelled   bool
+}
+
+// GenerateICS renders the event as an iCalendar (RFC 5545) document. Cancelled
+// events use METHOD:CANCEL so calendar clients remove them.
+func GenerateICS(event ICSEvent) []byte {
+	method, status := "REQUEST", "CONFIRMED"
+	if event.Cancelled {
+		method, status = "CANCEL", "CANCELLED"
+	}
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Campus Event Planner//EN",
+		"METHOD:" + method,
+		"BEGIN:VEVENT",
+		"UID:" + event.UID,
+		"DTSTAMP:" + time.Now().UTC().Format(icsTimeFormat),
+		"DTSTART:" + event.Start.UTC().Format(icsTimeFormat),
+		"DTEND:" + event.End.UTC().Format(icsTimeFormat),
+		fmt.Sprintf("SEQUENCE:%d", event.Sequence),
+		"STATUS:" + status,
+		"SUMMARY:" + escapeICSText(event.Summary),
+		"DESCRIPTION:" + escapeICSText(event.Description),
+		"LOCATION:" + escapeICSText(event.Location),
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(foldICSLine(line))
+		b.WriteString("\r\n")
+	}
+	return []byte(b.String())
+}
+
+func escapeICSText(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(text)
+}
+
+// foldICSLine splits lines longer than 75 octets as required by RFC 5545,
+// without breaking multi-byte characters.
+func foldICSLine(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateICS(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.FixedZone("EST", -5*3600))
+
+	ics := string(GenerateICS(ICSEvent{
+		UID:      "event-1@test",
+		Summary:  "Career Fair, Spring",
+		Location: "Hall; East wing",
+		Start:    start,
+		End:      start.Add(90 * time.Minute),
+		Sequence: 3,
+	}))
+
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n"))
+	assert.Contains(t, ics, "METHOD:REQUEST\r\n")
+	assert.Contains(t, ics, "DTSTART:20260301T150000Z\r\n")
+	assert.Contains(t, ics, "DTEND:20260301T163000Z\r\n")
+	assert.Contains(t, ics, "SUMMARY:Career Fair\\, Spring\r\n")
+	assert.Contains(t, ics, "LOCATION:Hall\\; East wing\r\n")
+	assert.Contains(t, ics, "SEQUENCE:3\r\n")
+}
+
+func TestGenerateICS_FoldsLongLines(t *testing.T) {
+	ics := string(GenerateICS(ICSEvent{Description: strings.Repeat("é", 100)}))
+
+	for _, line := range strings.Split(ics, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	assert.Contains(t, ics, "STATUS:CONFIRMED")
+}
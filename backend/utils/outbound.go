@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Outbound URLs must not point into the private network, so user supplied
+// webhooks cannot reach internal services.
+var (
+	ErrInvalidOutboundURL = errors.New("URL must be an absolute http or https URL")
+	ErrInternalAddress    = errors.New("URL must not point to an internal address")
+)
+
+const maxRedirects = 5
+
+// Carrier-grade NAT, which IsPrivate does not cover
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsInternalIP reports whether ip is loopback, private, link-local (including
+// the cloud metadata address), multicast or unspecified.
+func IsInternalIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip)
+}
+
+// ValidateOutboundURL checks the URL is absolute http(s) and its host is not
+// obviously internal. Host names are only resolved when dialing, where
+// NewOutboundClient checks the address again.
+func ValidateOutboundURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidOutboundURL
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrInternalAddress
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && IsInternalIP(ip) {
+		return ErrInternalAddress
+	}
+	return nil
+}
+
+// NewOutboundClient returns an HTTP client for user supplied URLs. Every
+// connection is checked against IsInternalIP after DNS resolution, so neither
+// rebinding nor a redirect can reach an internal address, and redirects are
+// validated like the original URL.
+func NewOutboundClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || IsInternalIP(addrPort.Addr()) {
+				return ErrInternalAddress
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the target and defeat the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errors.New("stopped after too many redirects")
+			}
+			return ValidateOutboundURL(request.URL.String())
+		},
+	}
+}
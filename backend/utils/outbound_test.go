@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOutboundURL(t *testing.T) {
+	assert.NoError(t, ValidateOutboundURL("https://hooks.example.com/notify"))
+	assert.NoError(t, ValidateOutboundURL("http://93.184.216.34/hook"))
+
+	assert.ErrorIs(t, ValidateOutboundURL("ftp://example.com"), ErrInvalidOutboundURL)
+	assert.ErrorIs(t, ValidateOutboundURL("/relative"), ErrInvalidOutboundURL)
+	for _, url := range []string{
+		"http://localhost/hook",
+		"http://127.0.0.1:8080/hook",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		assert.ErrorIs(t, ValidateOutboundURL(url), ErrInternalAddress, url)
+	}
+}
+
+func TestOutboundClient_RefusesInternalAddresses(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	_, err := NewOutboundClient(time.Second).Get(server.URL)
+
+	assert.ErrorIs(t, err, ErrInternalAddress)
+	assert.Zero(t, requests)
+}
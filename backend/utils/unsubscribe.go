@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Unsubscribe links are signed with their own key so they can never be
+// mistaken for, or turned into, a login token.
+var unsubscribeKey = []byte(secretKey + ":unsubscribe")
+
+// GenerateUnsubscribeToken signs a token that turns off one notification type
+// on one channel for the user. It does not expire, so links in old emails work.
+func GenerateUnsubscribeToken(userID int64, notificationType, channel string) string {
+	payload := strings.Join([]string{strconv.FormatInt(userID, 10), notificationType, channel}, "|")
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + signUnsubscribe(encoded)
+}
+
+// VerifyUnsubscribeToken checks the signature and returns the user, type and
+// channel the token was issued for.
+func VerifyUnsubscribeToken(token string) (int64, string, string, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(signUnsubscribe(encoded))) {
+		return 0, "", "", errors.New("Invalid unsubscribe token")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, "", "", errors.New("Invalid unsubscribe token")
+	}
+
+	parts := strings.Split(string(payload), "|")
+	if len(parts) != 3 {
+		return 0, "", "", errors.New("Invalid unsubscribe token")
+	}
+
+	userID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", "", errors.New("Invalid unsubscribe token")
+	}
+	return userID, parts[1], parts[2], nil
+}
+
+func signUnsubscribe(encoded string) string {
+	mac := hmac.New(sha256.New, unsubscribeKey)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
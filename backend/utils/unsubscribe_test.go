@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnsubscribeToken_RoundTrip(t *testing.T) {
+	token := GenerateUnsubscribeToken(42, "event.changed", "email")
+
+	userID, notificationType, channel, err := VerifyUnsubscribeToken(token)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), userID)
+	assert.Equal(t, "event.changed", notificationType)
+	assert.Equal(t, "email", channel)
+}
+
+func TestUnsubscribeToken_RejectsTampering(t *testing.T) {
+	token := GenerateUnsubscribeToken(42, "event.changed", "email")
+	forged := GenerateUnsubscribeToken(43, "event.changed", "email")
+
+	_, _, _, err := VerifyUnsubscribeToken(forged[:len(forged)/2] + token[len(token)/2:])
+	assert.Error(t, err)
+
+	_, _, _, err = VerifyUnsubscribeToken("not-a-token")
+	assert.Error(t, err)
+}
+
+func TestUnsubscribeToken_IsNotALoginToken(t *testing.T) {
+	_, err := VerifyToken(GenerateUnsubscribeToken(42, "event.changed", "email"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,245 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"event-planner/models"
+	"event-planner/utils"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Event types organizations can subscribe to.
+const (
+	EventCreated          = "event.created"
+	EventUpdated          = "event.updated"
+	EventDeleted          = "event.deleted"
+	RegistrationCreated   = "registration.created"
+	RegistrationCancelled = "registration.cancelled"
+)
+
+var EventTypes = []string{EventCreated, EventUpdated, EventDeleted, RegistrationCreated, RegistrationCancelled}
+
+// Headers sent with every delivery. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the endpoint secret, so receivers can check
+// both authenticity and freshness.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Payload is the JSON body posted to endpoints.
+type Payload struct {
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      any       `json:"data"`
+}
+
+// GenerateSecret returns a random per-endpoint signing secret.
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// Sign computes the value of the signature header for a delivery.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidateEndpoint checks the URL is absolute http(s) outside the internal
+// network and every event type is known.
+func ValidateEndpoint(endpoint models.WebhookEndpoint) error {
+	err := utils.ValidateOutboundURL(endpoint.URL)
+	if err != nil {
+		return fmt.Errorf("webhook %w", err)
+	}
+
+	if len(endpoint.EventTypes) == 0 {
+		return fmt.Errorf("subscribe to at least one event type")
+	}
+
+	for _, eventType := range endpoint.EventTypes {
+		if !slices.Contains(EventTypes, eventType) {
+			return fmt.Errorf("unknown event type %q", eventType)
+		}
+	}
+	return nil
+}
+
+// Publish queues a delivery of data to every endpoint of the organization that
+// subscribes to eventType. Events that belong to no organization are ignored.
+func Publish(organizationID *int64, eventType string, data any) error {
+	if organizationID == nil {
+		return nil
+	}
+
+	endpoints, err := models.GetWebhookEndpointsForOrganization(*organizationID)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(Payload{Type: eventType, CreatedAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	for _, endpoint := range endpoints {
+		if !endpoint.Subscribes(eventType) {
+			continue
+		}
+
+		delivery := models.WebhookDelivery{EndpointID: endpoint.ID, EventType: eventType, Payload: string(payload)}
+		err = delivery.Save()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Redeliver queues a fresh copy of an earlier delivery, keeping the original
+// and its attempts for the record.
+func Redeliver(original models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	delivery := models.WebhookDelivery{
+		EndpointID: original.EndpointID,
+		EventType:  original.EventType,
+		Payload:    original.Payload,
+	}
+	err := delivery.Save()
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// Deliverer sends queued deliveries and retries failures with exponential
+// backoff: BaseBackoff, then twice that, and so on, until MaxAttempts. Without
+// a Client, deliveries use one that refuses internal addresses.
+type Deliverer struct {
+	Client      *http.Client
+	MaxAttempts int
+	BaseBackoff time.Duration
+}
+
+const (
+	batchSize     = 50
+	claimLease    = time.Minute
+	maxBackoff    = 6 * time.Hour
+	maxErrorBytes = 512
+)
+
+// Backoff returns how long to wait after the given number of failed attempts.
+func (d Deliverer) Backoff(attempts int) time.Duration {
+	backoff := d.BaseBackoff
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
+
+// DeliverDue sends every delivery whose next attempt is due.
+func (d Deliverer) DeliverDue(now time.Time) error {
+	deliveries, err := models.GetDueWebhookDeliveries(now, batchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		claimed, err := delivery.Claim(now, claimLease)
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			continue
+		}
+
+		endpoint, err := models.GetWebhookEndpointByID(delivery.EndpointID)
+		if err != nil {
+			log.Printf("webhook delivery %d has no endpoint: %v", delivery.ID, err)
+			continue
+		}
+
+		err = d.attempt(*endpoint, delivery, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d Deliverer) attempt(endpoint models.WebhookEndpoint, delivery models.WebhookDelivery, now time.Time) error {
+	body := []byte(delivery.Payload)
+	timestamp := now.Unix()
+
+	attempt := models.WebhookAttempt{AttemptedAt: now}
+	request, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err == nil {
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("User-Agent", "campus-event-planner-webhooks")
+		request.Header.Set(HeaderEvent, delivery.EventType)
+		request.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+		request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		request.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, body))
+
+		start := time.Now()
+		var response *http.Response
+		response, err = d.client().Do(request)
+		attempt.DurationMs = time.Since(start).Milliseconds()
+
+		if err == nil {
+			attempt.StatusCode = response.StatusCode
+			// Only the status is recorded: the body may hold whatever the
+			// endpoint returns and is shown to the organizers
+			io.Copy(io.Discard, io.LimitReader(response.Body, maxErrorBytes))
+			response.Body.Close()
+
+			if response.StatusCode < 200 || response.StatusCode >= 300 {
+				err = fmt.Errorf("endpoint responded with status %d", response.StatusCode)
+			}
+		}
+	}
+
+	if err == nil {
+		return delivery.RecordAttempt(attempt, models.DeliverySucceeded, now)
+	}
+
+	attempt.Error = err.Error()
+	if len(attempt.Error) > maxErrorBytes {
+		attempt.Error = attempt.Error[:maxErrorBytes]
+	}
+
+	attempts := delivery.Attempts + 1
+	if attempts >= d.MaxAttempts {
+		return delivery.RecordAttempt(attempt, models.DeliveryFailed, now)
+	}
+	return delivery.RecordAttempt(attempt, models.DeliveryPending, now.Add(d.Backoff(attempts)))
+}
+
+var defaultClient = utils.NewOutboundClient(10 * time.Second)
+
+func (d Deliverer) client() *http.Client {
+	if d.Client != nil {
+		return d.Client
+	}
+	return defaultClient
+}
@@ -0,0 +1,255 @@
+package webhooks
+
+import (
+	"database/sql"
+	"encoding/json"
+	"event-planner/db"
+	"event-planner/models"
+	"event-planner/utils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	var err error
+	db.DB, err = sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		panic(err)
+	}
+	db.DB.SetMaxOpenConns(1)
+
+	createTables := `
+	CREATE TABLE webhook_endpoints (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		organization_id INTEGER NOT NULL,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		event_types TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		endpoint_id INTEGER NOT NULL,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE webhook_attempts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		delivery_id INTEGER NOT NULL,
+		attempted_at DATETIME NOT NULL,
+		status_code INTEGER NOT NULL,
+		error TEXT NOT NULL,
+		duration_ms INTEGER NOT NULL
+	);
+	`
+	_, err = db.DB.Exec(createTables)
+	if err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	db.DB.Close()
+	os.Exit(code)
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver is a local webhook consumer that answers with the queued status codes.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedRequest
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body, _ := io.ReadAll(req.Body)
+	r.requests = append(r.requests, receivedRequest{header: req.Header.Clone(), body: body})
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func createEndpoint(t *testing.T, organizationID int64, url string, eventTypes ...string) models.WebhookEndpoint {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+
+	endpoint := models.WebhookEndpoint{OrganizationID: organizationID, URL: url, EventTypes: eventTypes, Secret: secret}
+	err = endpoint.Save()
+	if err != nil {
+		t.Fatalf("Failed to create endpoint: %v", err)
+	}
+	return endpoint
+}
+
+func TestPublishAndDeliver_SignsPayload(t *testing.T) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	organizationID := int64(1)
+	endpoint := createEndpoint(t, organizationID, server.URL, EventCreated)
+	createEndpoint(t, organizationID, server.URL, RegistrationCreated)
+
+	err := Publish(&organizationID, EventCreated, map[string]string{"name": "Career Fair"})
+	assert.NoError(t, err)
+
+	// Events outside an organization never produce deliveries
+	assert.NoError(t, Publish(nil, EventCreated, nil))
+
+	deliverer := Deliverer{Client: server.Client(), MaxAttempts: 3, BaseBackoff: time.Minute}
+	err = deliverer.DeliverDue(time.Now())
+	assert.NoError(t, err)
+	assert.Len(t, recv.requests, 1)
+
+	request := recv.requests[0]
+	assert.Equal(t, EventCreated, request.header.Get(HeaderEvent))
+	timestamp, _ := strconv.ParseInt(request.header.Get(HeaderTimestamp), 10, 64)
+	assert.Equal(t, Sign(endpoint.Secret, timestamp, request.body), request.header.Get(HeaderSignature))
+
+	var payload struct {
+		Type string            `json:"type"`
+		Data map[string]string `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(request.body, &payload))
+	assert.Equal(t, EventCreated, payload.Type)
+	assert.Equal(t, "Career Fair", payload.Data["name"])
+
+	deliveries, _ := models.GetWebhookDeliveries(endpoint.ID, 10)
+	assert.Equal(t, models.DeliverySucceeded, deliveries[0].Status)
+}
+
+func TestDeliverDue_RetriesWithBackoff(t *testing.T) {
+	recv := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusInternalServerError}}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	organizationID := int64(2)
+	endpoint := createEndpoint(t, organizationID, server.URL, EventUpdated)
+	assert.NoError(t, Publish(&organizationID, EventUpdated, "payload"))
+
+	deliverer := Deliverer{Client: server.Client(), MaxAttempts: 3, BaseBackoff: time.Minute}
+	now := time.Now()
+
+	assert.NoError(t, deliverer.DeliverDue(now))
+	delivery := latestDelivery(t, endpoint.ID)
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.WithinDuration(t, now.Add(time.Minute), delivery.NextAttemptAt, time.Second)
+
+	// Not due yet, so nothing is sent
+	assert.NoError(t, deliverer.DeliverDue(now.Add(30*time.Second)))
+	assert.Len(t, recv.requests, 1)
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, deliverer.DeliverDue(now))
+	delivery = latestDelivery(t, endpoint.ID)
+	assert.WithinDuration(t, now.Add(2*time.Minute), delivery.NextAttemptAt, time.Second)
+
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, deliverer.DeliverDue(now))
+	delivery = latestDelivery(t, endpoint.ID)
+	assert.Equal(t, models.DeliveryFailed, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+
+	attempts, _ := models.GetWebhookAttempts(delivery.ID)
+	assert.Len(t, attempts, 3)
+	assert.Equal(t, http.StatusBadGateway, attempts[1].StatusCode)
+	assert.Contains(t, attempts[1].Error, "502")
+
+	// A redelivery starts over as a new delivery
+	redelivery, err := Redeliver(delivery)
+	assert.NoError(t, err)
+	assert.NoError(t, deliverer.DeliverDue(now.Add(time.Second)))
+	redelivered, _ := models.GetWebhookDeliveryByID(redelivery.ID)
+	assert.Equal(t, models.DeliverySucceeded, redelivered.Status)
+	assert.Len(t, recv.requests, 4)
+}
+
+func TestBackoff(t *testing.T) {
+	deliverer := Deliverer{BaseBackoff: 30 * time.Second}
+
+	assert.Equal(t, 30*time.Second, deliverer.Backoff(1))
+	assert.Equal(t, time.Minute, deliverer.Backoff(2))
+	assert.Equal(t, 4*time.Minute, deliverer.Backoff(4))
+	assert.Equal(t, maxBackoff, deliverer.Backoff(40))
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	assert.NoError(t, ValidateEndpoint(models.WebhookEndpoint{URL: "https://discord.example/hook", EventTypes: []string{EventCreated}}))
+	assert.Error(t, ValidateEndpoint(models.WebhookEndpoint{URL: "ftp://example.com", EventTypes: []string{EventCreated}}))
+	assert.Error(t, ValidateEndpoint(models.WebhookEndpoint{URL: "/relative", EventTypes: []string{EventCreated}}))
+	assert.Error(t, ValidateEndpoint(models.WebhookEndpoint{URL: "https://example.com", EventTypes: []string{"event.exploded"}}))
+	assert.ErrorIs(t, ValidateEndpoint(models.WebhookEndpoint{URL: "http://169.254.169.254/latest", EventTypes: []string{EventCreated}}), utils.ErrInternalAddress)
+	assert.ErrorIs(t, ValidateEndpoint(models.WebhookEndpoint{URL: "http://localhost:8080/hook", EventTypes: []string{EventCreated}}), utils.ErrInternalAddress)
+}
+
+func TestDeliverDue_RecordsOnlyStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("database password is hunter2"))
+	}))
+	defer server.Close()
+
+	organizationID := int64(3)
+	endpoint := createEndpoint(t, organizationID, server.URL, EventDeleted)
+	assert.NoError(t, Publish(&organizationID, EventDeleted, "payload"))
+
+	deliverer := Deliverer{Client: server.Client(), MaxAttempts: 3, BaseBackoff: time.Minute}
+	assert.NoError(t, deliverer.DeliverDue(time.Now()))
+
+	attempts, _ := models.GetWebhookAttempts(latestDelivery(t, endpoint.ID).ID)
+	if assert.Len(t, attempts, 1) {
+		assert.Equal(t, http.StatusInternalServerError, attempts[0].StatusCode)
+		assert.Contains(t, attempts[0].Error, "500")
+		assert.NotContains(t, attempts[0].Error, "hunter2")
+	}
+}
+
+func TestDeliverDue_RefusesInternalAddresses(t *testing.T) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	// Saved directly, as a host name resolving to loopback would pass validation
+	organizationID := int64(4)
+	endpoint := createEndpoint(t, organizationID, server.URL, EventCreated)
+	assert.NoError(t, Publish(&organizationID, EventCreated, "payload"))
+
+	deliverer := Deliverer{MaxAttempts: 3, BaseBackoff: time.Minute}
+	assert.NoError(t, deliverer.DeliverDue(time.Now()))
+	assert.Empty(t, recv.requests)
+
+	attempts, _ := models.GetWebhookAttempts(latestDelivery(t, endpoint.ID).ID)
+	if assert.Len(t, attempts, 1) {
+		assert.Contains(t, attempts[0].Error, utils.ErrInternalAddress.Error())
+	}
+}
+
+func latestDelivery(t *testing.T, endpointID int64) models.WebhookDelivery {
+	deliveries, err := models.GetWebhookDeliveries(endpointID, 1)
+	if err != nil || len(deliveries) == 0 {
+		t.Fatalf("Failed to fetch delivery: %v", err)
+	}
+	return deliveries[0]
+}